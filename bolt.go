@@ -0,0 +1,81 @@
+// Package bolt lets Bolt be built with support for databases it
+// doesn't support out of the box, without forking it.
+//
+// A custom build registers its drivers and then runs the CLI:
+//
+//	func main() {
+//		err := bolt.RegisterDriver("mydb", bolt.Driver{
+//			SQLDriverName:    "mydb",
+//			Adapter:          MyDBAdapter{},
+//			Transactions:     true,
+//			TransactionalDDL: true,
+//		})
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		os.Exit(bolt.Run())
+//	}
+//
+// The driver is then selected with `driver = "mydb"` in bolt.toml,
+// or BOLT_DB_DRIVER=mydb.
+package bolt
+
+import (
+	"github.com/eugenetriguba/bolt/internal/cli"
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+type (
+	// Driver describes a database Bolt can connect to.
+	Driver = storage.Driver
+	// DBAdapter handles everything that differs between databases.
+	DBAdapter = storage.DBAdapter
+	// UpdateStatementCreator and SchemaDropper are optional for a
+	// DBAdapter to implement when its database needs them.
+	UpdateStatementCreator = storage.UpdateStatementCreator
+	SchemaDropper          = storage.SchemaDropper
+	// SQLExecutor executes statements for a DBAdapter. It's either
+	// the database's connection pool or a transaction.
+	SQLExecutor = storage.SQLExecutor
+	// ConnectionConfig is the database configuration a DBAdapter
+	// creates DSNs from.
+	ConnectionConfig = configloader.ConnectionConfig
+	// Schema is what DBAdapter.InspectSchema gives back.
+	Schema = models.Schema
+	Table  = models.Table
+	Column = models.Column
+)
+
+// The built-in adapters, which custom adapters can embed to
+// support databases that are compatible with one of them.
+type (
+	PostgresqlAdapter  = storage.PostgresqlAdapter
+	MySQLAdapter       = storage.MySQLAdapter
+	MSSQLAdapter       = storage.MSSQLAdapter
+	SqliteAdapter      = storage.SqliteAdapter
+	CockroachDBAdapter = storage.CockroachDBAdapter
+	ClickHouseAdapter  = storage.ClickHouseAdapter
+	LibSQLAdapter      = storage.LibSQLAdapter
+)
+
+var (
+	// ErrSchemasUnsupported is what DBAdapter.CreateSchema
+	// gives back for databases without schemas.
+	ErrSchemasUnsupported = storage.ErrSchemasUnsupported
+	ErrDriverRegistered   = storage.ErrDriverRegistered
+	ErrInvalidDriver      = storage.ErrInvalidDriver
+)
+
+// RegisterDriver makes a database available under name, the
+// name the driver connection setting refers to it by. It must be
+// called before Run.
+func RegisterDriver(name string, driver Driver) error {
+	return storage.RegisterDriver(name, driver)
+}
+
+// Run runs the Bolt CLI and returns the exit code.
+func Run() int {
+	return cli.Run()
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/bolt/internal/storage"
 	"github.com/eugenetriguba/checkmate/assert"
 )
@@ -45,12 +46,16 @@ func DropTable(t *testing.T, db storage.DB, tableName string) {
 }
 
 type MockDB struct {
-	ExecFunc        func(query string, args ...interface{}) (sql.Result, error)
-	QueryFunc       func(query string, args ...interface{}) (*sql.Rows, error)
-	QueryRowFunc    func(query string, args ...interface{}) *sql.Row
-	TxFunc          func(fn storage.TxFunc) error
-	CloseFunc       func() error
-	TableExistsFunc func(tableName string) (bool, error)
+	ExecFunc          func(query string, args ...interface{}) (sql.Result, error)
+	QueryFunc         func(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowFunc      func(query string, args ...interface{}) *sql.Row
+	TxFunc            func(fn storage.TxFunc) error
+	CloseFunc         func() error
+	TableExistsFunc   func(tableName string) (bool, error)
+	ColumnExistsFunc  func(tableName string, columnName string) (bool, error)
+	DatabaseNameFunc  func() (string, error)
+	DumpSchemaFunc    func(excludeTables ...string) (string, error)
+	InspectSchemaFunc func(excludeTables ...string) (models.Schema, error)
 }
 
 func (m *MockDB) Exec(query string, args ...interface{}) (sql.Result, error) {
@@ -73,6 +78,22 @@ func (m *MockDB) Close() error {
 	return m.CloseFunc()
 }
 
+func (m *MockDB) ColumnExists(tableName string, columnName string) (bool, error) {
+	return m.ColumnExistsFunc(tableName, columnName)
+}
+
 func (m *MockDB) TableExists(tableName string) (bool, error) {
 	return m.TableExistsFunc(tableName)
 }
+
+func (m *MockDB) DatabaseName() (string, error) {
+	return m.DatabaseNameFunc()
+}
+
+func (m *MockDB) DumpSchema(excludeTables ...string) (string, error) {
+	return m.DumpSchemaFunc(excludeTables...)
+}
+
+func (m *MockDB) InspectSchema(excludeTables ...string) (models.Schema, error) {
+	return m.InspectSchemaFunc(excludeTables...)
+}
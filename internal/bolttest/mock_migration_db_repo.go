@@ -1,20 +1,45 @@
 package bolttest
 
-import "github.com/eugenetriguba/bolt/internal/models"
+import (
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+)
 
 type MockMigrationDBRepo struct {
-	ListReturnValue         ListReturnValue
-	ListCallCount           int
-	IsAppliedReturnValue    IsAppliedReturnValue
-	IsAppliedCallCount      int
-	ApplyReturnValue        ApplyReturnValue
-	ApplyCallCount          int
-	ApplyWithTxReturnValue  ApplyWithTxReturnValue
-	ApplyWithTxCallCount    int
-	RevertReturnValue       RevertReturnValue
-	RevertCallCount         int
-	RevertWithTxReturnValue RevertWithTxReturnValue
-	RevertWithTxCallCount   int
+	ListReturnValue        ListReturnValue
+	ListCallCount          int
+	IsAppliedReturnValue   IsAppliedReturnValue
+	IsAppliedCallCount     int
+	ApplyReturnValue       ApplyReturnValue
+	ApplyCallCount         int
+	ApplyWithTxReturnValue ApplyWithTxReturnValue
+	ApplyWithTxCallCount   int
+	// ApplyWithTxErrOnCall only returns the ApplyWithTxReturnValue
+	// error on that call, counting from 1, when it is set.
+	ApplyWithTxErrOnCall     int
+	RevertReturnValue        RevertReturnValue
+	RevertCallCount          int
+	RevertWithTxReturnValue  RevertWithTxReturnValue
+	RevertWithTxCallCount    int
+	MarkAppliedReturnValue   MarkAppliedReturnValue
+	MarkAppliedCallCount     int
+	MarkUnappliedReturnValue MarkUnappliedReturnValue
+	MarkUnappliedCallCount   int
+	MarkCleanReturnValue     MarkCleanReturnValue
+	MarkCleanCallCount       int
+	SquashReturnValue        SquashReturnValue
+	SquashCallCount          int
+	SquashedVersions         []string
+	DumpSchemaReturnValue    DumpSchemaReturnValue
+	DumpSchemaCallCount      int
+	LoadSchemaReturnValue    LoadSchemaReturnValue
+	LoadSchemaCallCount      int
+	LoadedSchema             string
+	LoadedVersions           []string
+	InspectSchemaReturnValue InspectSchemaReturnValue
+	InspectSchemaCallCount   int
+	TxReturnValue            TxReturnValue
+	TxCallCount              int
 }
 
 type ListReturnValue struct {
@@ -27,13 +52,29 @@ type IsAppliedReturnValue struct {
 	Err       error
 }
 
+type DumpSchemaReturnValue struct {
+	Schema string
+	Err    error
+}
+
+type InspectSchemaReturnValue struct {
+	Schema models.Schema
+	Err    error
+}
+
 type ApplyReturnValue struct {
 	Err error
 }
 
 type ApplyWithTxReturnValue = ApplyReturnValue
+type TxReturnValue = ApplyReturnValue
 type RevertReturnValue = ApplyReturnValue
 type RevertWithTxReturnValue = ApplyReturnValue
+type MarkAppliedReturnValue = ApplyReturnValue
+type MarkUnappliedReturnValue = ApplyReturnValue
+type MarkCleanReturnValue = ApplyReturnValue
+type SquashReturnValue = ApplyReturnValue
+type LoadSchemaReturnValue = ApplyReturnValue
 
 func (repo *MockMigrationDBRepo) List() (map[string]*models.Migration, error) {
 	repo.ListCallCount += 1
@@ -58,6 +99,9 @@ func (repo *MockMigrationDBRepo) ApplyWithTx(
 	migration *models.Migration,
 ) error {
 	repo.ApplyWithTxCallCount += 1
+	if repo.ApplyWithTxErrOnCall != 0 && repo.ApplyWithTxErrOnCall != repo.ApplyWithTxCallCount {
+		return nil
+	}
 	return repo.ApplyWithTxReturnValue.Err
 }
 
@@ -76,3 +120,51 @@ func (repo *MockMigrationDBRepo) RevertWithTx(
 	repo.RevertWithTxCallCount += 1
 	return repo.RevertWithTxReturnValue.Err
 }
+
+func (repo *MockMigrationDBRepo) MarkApplied(migration *models.Migration) error {
+	repo.MarkAppliedCallCount += 1
+	return repo.MarkAppliedReturnValue.Err
+}
+
+func (repo *MockMigrationDBRepo) MarkUnapplied(migration *models.Migration) error {
+	repo.MarkUnappliedCallCount += 1
+	return repo.MarkUnappliedReturnValue.Err
+}
+
+func (repo *MockMigrationDBRepo) MarkClean(migration *models.Migration) error {
+	repo.MarkCleanCallCount += 1
+	return repo.MarkCleanReturnValue.Err
+}
+
+func (repo *MockMigrationDBRepo) Squash(migration *models.Migration, versions []string) error {
+	repo.SquashCallCount += 1
+	repo.SquashedVersions = versions
+	return repo.SquashReturnValue.Err
+}
+
+func (repo *MockMigrationDBRepo) DumpSchema() (string, error) {
+	repo.DumpSchemaCallCount += 1
+	return repo.DumpSchemaReturnValue.Schema, repo.DumpSchemaReturnValue.Err
+}
+
+func (repo *MockMigrationDBRepo) LoadSchema(schema string, versions []string) error {
+	repo.LoadSchemaCallCount += 1
+	repo.LoadedSchema = schema
+	repo.LoadedVersions = versions
+	return repo.LoadSchemaReturnValue.Err
+}
+
+func (repo *MockMigrationDBRepo) InspectSchema() (models.Schema, error) {
+	repo.InspectSchemaCallCount += 1
+	return repo.InspectSchemaReturnValue.Schema, repo.InspectSchemaReturnValue.Err
+}
+
+// Tx calls fn with the mock itself unless the
+// TxReturnValue has an error to return instead.
+func (repo *MockMigrationDBRepo) Tx(fn func(repo repositories.MigrationDBRepo) error) error {
+	repo.TxCallCount += 1
+	if repo.TxReturnValue.Err != nil {
+		return repo.TxReturnValue.Err
+	}
+	return fn(repo)
+}
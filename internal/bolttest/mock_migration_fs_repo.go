@@ -14,6 +14,14 @@ type MockMigrationFsRepo struct {
 	ReadUpgradeScriptCallCount     int
 	ReadDowngradeScriptReturnValue ReadDowngradeScriptReturnValue
 	ReadDowngradeScriptCallCount   int
+	WriteReturnValue               WriteReturnValue
+	WriteCallCount                 int
+	ArchiveReturnValue             ArchiveReturnValue
+	ArchiveCallCount               int
+	RestoreReturnValue             RestoreReturnValue
+	RestoreCallCount               int
+	RemoveReturnValue              RemoveReturnValue
+	RemoveCallCount                int
 }
 
 type CreateReturnValue struct {
@@ -27,6 +35,14 @@ type ReadUpgradeScriptReturnValue struct {
 
 type ReadDowngradeScriptReturnValue = ReadUpgradeScriptReturnValue
 
+type WriteReturnValue = CreateReturnValue
+
+type ArchiveReturnValue = CreateReturnValue
+
+type RestoreReturnValue = CreateReturnValue
+
+type RemoveReturnValue = CreateReturnValue
+
 func (repo *MockMigrationFsRepo) Create(migration *models.Migration) error {
 	repo.CreateCallCount += 1
 	return repo.CreateReturnValue.Err
@@ -50,3 +66,33 @@ func (repo *MockMigrationFsRepo) ReadDowngradeScript(
 	repo.ReadDowngradeScriptCallCount += 1
 	return repo.ReadDowngradeScriptReturnValue.Script, repo.ReadDowngradeScriptReturnValue.Err
 }
+
+func (repo *MockMigrationFsRepo) Write(
+	migration *models.Migration,
+	upgradeScript sqlparse.MigrationScript,
+	downgradeScript sqlparse.MigrationScript,
+) error {
+	repo.WriteCallCount += 1
+	return repo.WriteReturnValue.Err
+}
+
+func (repo *MockMigrationFsRepo) Archive(
+	migration *models.Migration,
+	archiveDirPath string,
+) error {
+	repo.ArchiveCallCount += 1
+	return repo.ArchiveReturnValue.Err
+}
+
+func (repo *MockMigrationFsRepo) Restore(
+	migration *models.Migration,
+	archiveDirPath string,
+) error {
+	repo.RestoreCallCount += 1
+	return repo.RestoreReturnValue.Err
+}
+
+func (repo *MockMigrationFsRepo) Remove(migration *models.Migration) error {
+	repo.RemoveCallCount += 1
+	return repo.RemoveReturnValue.Err
+}
@@ -0,0 +1,25 @@
+package bolttest
+
+type MockSchemaFsRepo struct {
+	WriteReturnValue WriteReturnValue
+	WriteCallCount   int
+	WrittenSchema    string
+	ReadReturnValue  ReadReturnValue
+	ReadCallCount    int
+}
+
+type ReadReturnValue struct {
+	Contents string
+	Err      error
+}
+
+func (repo *MockSchemaFsRepo) Write(schema string) error {
+	repo.WriteCallCount += 1
+	repo.WrittenSchema = schema
+	return repo.WriteReturnValue.Err
+}
+
+func (repo *MockSchemaFsRepo) Read() (string, error) {
+	repo.ReadCallCount += 1
+	return repo.ReadReturnValue.Contents, repo.ReadReturnValue.Err
+}
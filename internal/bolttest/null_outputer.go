@@ -1,5 +1,7 @@
 package bolttest
 
+import "github.com/eugenetriguba/bolt/internal/output"
+
 type NullOutputter struct {
 	OutputLogs []string
 	ErrorLogs  []string
@@ -10,6 +12,10 @@ func (o NullOutputter) Output(message string) error {
 	return nil
 }
 
+func (o NullOutputter) Result(message string) error {
+	return nil
+}
+
 func (o NullOutputter) Error(err error) error {
 	return nil
 }
@@ -17,3 +23,11 @@ func (o NullOutputter) Error(err error) error {
 func (o NullOutputter) Table(header []string, rows [][]string) error {
 	return nil
 }
+
+func (o NullOutputter) Event(event output.Event) error {
+	return nil
+}
+
+func (o NullOutputter) Status(migrations []output.MigrationStatus, summary output.StatusSummary) error {
+	return nil
+}
@@ -2,9 +2,14 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"log/slog"
+	"os"
 
 	"github.com/eugenetriguba/bolt/internal/commands"
+	"github.com/eugenetriguba/bolt/internal/output"
 	"github.com/google/subcommands"
 )
 
@@ -17,9 +22,72 @@ func Run() int {
 	subcommands.Register(&commands.NewCmd{}, "")
 	subcommands.Register(&commands.UpCmd{}, "")
 	subcommands.Register(&commands.DownCmd{}, "")
+	subcommands.Register(&commands.ResetCmd{}, "")
 	subcommands.Register(&commands.StatusCmd{}, "")
+	subcommands.Register(&commands.CurrentCmd{}, "")
+	subcommands.Register(&commands.BaselineCmd{}, "")
+	subcommands.Register(&commands.MarkCmd{}, "")
+	subcommands.Register(&commands.ResolveCmd{}, "")
+	subcommands.Register(&commands.ImportCmd{}, "")
+	subcommands.Register(&commands.SquashCmd{}, "")
+	subcommands.Register(&commands.DumpCmd{}, "")
+	subcommands.Register(&commands.LoadCmd{}, "")
+	subcommands.Register(&commands.DiffCmd{}, "")
+	subcommands.Register(&commands.DBCmd{}, "")
+
+	format := flag.String("format", string(output.FormatText), "The output format: text, json, or ndjson.")
+	subcommands.ImportantFlag("format")
+	verbose := flag.Bool("v", false, "Log debug messages, including every SQL statement executed.")
+	subcommands.ImportantFlag("v")
+	flag.BoolVar(verbose, "verbose", *verbose, "alias for -v")
+	quiet := flag.Bool("quiet", false, "Only output command results and errors.")
+	subcommands.ImportantFlag("quiet")
 
 	flag.Parse()
+	outputter, err := output.NewOutputter(output.Format(*format))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return int(subcommands.ExitUsageError)
+	}
+
+	level, err := logLevel(*verbose, *quiet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return int(subcommands.ExitUsageError)
+	}
+	slog.SetDefault(output.NewLogger(os.Stderr, level, output.Format(*format)))
+	if level > slog.LevelInfo {
+		outputter = output.NewQuietOutputter(outputter)
+	}
+
 	ctx := context.Background()
-	return int(subcommands.Execute(ctx))
+	status := subcommands.Execute(ctx, outputter)
+	if flusher, ok := outputter.(output.Flusher); ok {
+		err = flusher.Flush()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return int(subcommands.ExitFailure)
+		}
+	}
+
+	return int(status)
+}
+
+// logLevel determines the level to log at from the -v and
+// -quiet flags, falling back to the BOLT_LOG_LEVEL environment
+// variable and then the info level.
+func logLevel(verbose bool, quiet bool) (slog.Level, error) {
+	switch {
+	case verbose && quiet:
+		return slog.LevelInfo, errors.New("-v and -quiet cannot be used together")
+	case verbose:
+		return slog.LevelDebug, nil
+	case quiet:
+		return slog.LevelError, nil
+	}
+
+	if name := os.Getenv("BOLT_LOG_LEVEL"); name != "" {
+		return output.ParseLogLevel(name)
+	}
+	return slog.LevelInfo, nil
 }
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type BaselineCmd struct {
+	version string
+	yes     bool
+}
+
+func (*BaselineCmd) Name() string {
+	return "baseline"
+}
+
+func (*BaselineCmd) Synopsis() string {
+	return "mark migrations as applied without running them"
+}
+
+func (*BaselineCmd) Usage() string {
+	return `baseline -version|-v [-yes]:
+	Mark every migration up to and including the version as applied
+	without running them. Use this when adopting bolt on a database
+	whose schema already matches those migrations.
+  `
+}
+
+func (cmd *BaselineCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.version,
+		"version",
+		"",
+		"The version to mark as applied up to and including.",
+	)
+	f.StringVar(&cmd.version, "v", cmd.version, "alias for -version")
+	f.BoolVar(
+		&cmd.yes,
+		"yes",
+		false,
+		"Skip the confirmation for protected databases.",
+	)
+}
+
+func (cmd *BaselineCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	if cmd.version == "" {
+		outputter.Error(errors.New("-version is required"))
+		return subcommands.ExitUsageError
+	}
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	err = confirmProtectedDB(cfg.Connection, db, cmd.yes, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+
+	err = migrationService.BaselineToVersion(cmd.version)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to baseline to %s: %w", cmd.version, err))
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/output"
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+// confirm asks the user a yes or no question on the console.
+// Anything other than an explicit yes, including no input at
+// all, is taken as a no.
+func confirm(question string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", question)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("unable to read confirmation: %w", err)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// errNotConfirmed is returned when the user doesn't confirm a
+// destructive command against a protected database.
+var errNotConfirmed = errors.New(
+	"the database name didn't match, so nothing was changed, " +
+		"pass -yes to skip the confirmation",
+)
+
+// confirmProtected asks the user to type out the database name
+// before a destructive command runs against a protected database.
+// The host and the name the database reports are shown so that it
+// is clear which database is about to be changed. When yes is set,
+// as it would be in CI, the confirmation is skipped.
+func confirmProtected(
+	cfg configloader.ConnectionConfig,
+	reportedDBName string,
+	yes bool,
+	outputter output.Outputter,
+) error {
+	if !cfg.IsProtected() {
+		return nil
+	}
+
+	host := cfg.Host
+	if host == "" {
+		host = "localhost"
+	}
+	outputter.Output(fmt.Sprintf(
+		"%s is a protected database (host: %s, database: %s).",
+		cfg.DBName,
+		host,
+		reportedDBName,
+	))
+	if yes {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Type the database name to continue: ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("unable to read confirmation: %w", err)
+	}
+	if strings.TrimSpace(answer) != cfg.DBName {
+		return errNotConfirmed
+	}
+
+	return nil
+}
+
+// confirmProtectedDB is confirmProtected for a database that is
+// connected to, so the name the database reports can be shown.
+func confirmProtectedDB(
+	cfg configloader.ConnectionConfig,
+	db storage.DB,
+	yes bool,
+	outputter output.Outputter,
+) error {
+	if !cfg.IsProtected() {
+		return nil
+	}
+
+	reportedDBName, err := db.DatabaseName()
+	if err != nil {
+		return fmt.Errorf("unable to retrieve database name: %w", err)
+	}
+
+	return confirmProtected(cfg, reportedDBName, yes, outputter)
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/output"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+const (
+	// exitNoMigrationsApplied is the exit status of `bolt current`
+	// when the database doesn't have any migrations applied.
+	exitNoMigrationsApplied subcommands.ExitStatus = 3
+	// exitDatabaseBehind is the exit status of `bolt current`
+	// when there are local migrations that aren't applied.
+	exitDatabaseBehind subcommands.ExitStatus = 4
+)
+
+type CurrentCmd struct {
+	details bool
+}
+
+func (*CurrentCmd) Name() string {
+	return "current"
+}
+
+func (*CurrentCmd) Synopsis() string {
+	return "show the version of the latest applied migration"
+}
+
+func (*CurrentCmd) Usage() string {
+	return `current [-details]:
+	Show the version of the latest applied migration. Exits with 3 when
+	no migrations are applied and 4 when there are migrations to apply.
+  `
+}
+
+func (cmd *CurrentCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(
+		&cmd.details,
+		"details",
+		false,
+		"Also show the migration's message and when it was applied.",
+	)
+}
+
+func (cmd *CurrentCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	return outputCurrentVersion(outputterFromArgs(args), cmd.details)
+}
+
+// outputCurrentVersion outputs the version of the latest applied
+// migration on its own so scripts can use it as-is, or with its
+// message and when it was applied when details are wanted.
+func outputCurrentVersion(outputter output.Outputter, details bool) subcommands.ExitStatus {
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+
+	current, pendingCount, err := migrationService.CurrentMigration()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve the current migration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	if current == nil {
+		outputter.Error(errors.New("no migrations have been applied"))
+		return exitNoMigrationsApplied
+	}
+
+	if details {
+		appliedAt := ""
+		if !current.AppliedAt.IsZero() {
+			appliedAt = current.AppliedAt.Local().Format(time.DateTime)
+		}
+		err = outputter.Table(
+			[]string{"Version", "Message", "Applied At"},
+			[][]string{{current.Version, current.Message, appliedAt}},
+		)
+	} else {
+		err = outputter.Result(current.Version)
+	}
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to output the current migration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	if pendingCount > 0 {
+		outputter.Error(fmt.Errorf(
+			"the database is behind by %d migration(s), run 'bolt up' to apply them",
+			pendingCount,
+		))
+		return exitDatabaseBehind
+	}
+
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type DBCmd struct{}
+
+func (*DBCmd) Name() string {
+	return "db"
+}
+
+func (*DBCmd) Synopsis() string {
+	return "create or drop the database"
+}
+
+func (*DBCmd) Usage() string {
+	return `db <create|drop> [-force] [-yes]:
+	Create or drop the configured database. Bolt connects to the
+	server-level database to do so.
+  `
+}
+
+func (cmd *DBCmd) SetFlags(f *flag.FlagSet) {}
+
+func (cmd *DBCmd) Execute(
+	ctx context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	dbFlags := flag.NewFlagSet("bolt db", flag.ContinueOnError)
+	dbFlags.SetOutput(os.Stderr)
+	commander := subcommands.NewCommander(dbFlags, "bolt db")
+	commander.Register(commander.HelpCommand(), "")
+	commander.Register(&DBCreateCmd{}, "")
+	commander.Register(&DBDropCmd{}, "")
+
+	err := dbFlags.Parse(f.Args())
+	if err != nil {
+		return subcommands.ExitUsageError
+	}
+	if dbFlags.NArg() == 0 {
+		f.Usage()
+		return subcommands.ExitUsageError
+	}
+
+	return commander.Execute(ctx, args...)
+}
+
+type DBCreateCmd struct{}
+
+func (*DBCreateCmd) Name() string {
+	return "create"
+}
+
+func (*DBCreateCmd) Synopsis() string {
+	return "create the database"
+}
+
+func (*DBCreateCmd) Usage() string {
+	return `db create:
+	Create the configured database if it doesn't exist yet
+  `
+}
+
+func (cmd *DBCreateCmd) SetFlags(f *flag.FlagSet) {}
+
+func (cmd *DBCreateCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	err = storage.CreateDatabase(cfg.Connection)
+	if errors.Is(err, storage.ErrDatabaseExists) {
+		outputter.Output(fmt.Sprintf("Database %s already exists.", cfg.Connection.DBName))
+		return subcommands.ExitSuccess
+	}
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to create database: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	outputter.Output(fmt.Sprintf("Created database %s.", cfg.Connection.DBName))
+	return subcommands.ExitSuccess
+}
+
+type DBDropCmd struct {
+	force bool
+	yes   bool
+}
+
+func (*DBDropCmd) Name() string {
+	return "drop"
+}
+
+func (*DBDropCmd) Synopsis() string {
+	return "drop the database"
+}
+
+func (*DBDropCmd) Usage() string {
+	return `db drop [-force] [-yes]:
+	Drop the configured database and everything in it
+  `
+}
+
+func (cmd *DBDropCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(
+		&cmd.force,
+		"force",
+		false,
+		"Drop the database without asking for confirmation.",
+	)
+	f.BoolVar(
+		&cmd.yes,
+		"yes",
+		false,
+		"Skip the confirmation for protected databases.",
+	)
+}
+
+func (cmd *DBDropCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	if cfg.Connection.IsProtected() {
+		err = confirmProtected(cfg.Connection, cfg.Connection.DBName, cmd.yes, outputter)
+		if err != nil {
+			outputter.Error(err)
+			return subcommands.ExitFailure
+		}
+	} else if !cmd.force {
+		confirmed, err := confirm(fmt.Sprintf(
+			"Drop database %s and everything in it?",
+			cfg.Connection.DBName,
+		))
+		if err != nil {
+			outputter.Error(err)
+			return subcommands.ExitFailure
+		}
+		if !confirmed {
+			outputter.Error(errors.New("database drop cancelled"))
+			return subcommands.ExitFailure
+		}
+	}
+
+	err = storage.DropDatabase(cfg.Connection)
+	if errors.Is(err, storage.ErrDatabaseDoesNotExist) {
+		outputter.Output(fmt.Sprintf("Database %s does not exist.", cfg.Connection.DBName))
+		return subcommands.ExitSuccess
+	}
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to drop database: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	outputter.Output(fmt.Sprintf("Dropped database %s.", cfg.Connection.DBName))
+	return subcommands.ExitSuccess
+}
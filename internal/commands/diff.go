@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type DiffCmd struct {
+	scratchDBName string
+}
+
+func (*DiffCmd) Name() string {
+	return "diff"
+}
+
+func (*DiffCmd) Synopsis() string {
+	return "detect schema drift between the migrations and the database"
+}
+
+func (*DiffCmd) Usage() string {
+	return `diff [-scratch-db]:
+	Apply the migrations that are applied to the database to an empty
+	scratch database and compare the two schemas. Any tables, columns,
+	indexes, and constraints that differ are reported and the command
+	exits with a non-zero exit code.
+  `
+}
+
+func (cmd *DiffCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.scratchDBName,
+		"scratch-db",
+		"",
+		"The name of an empty database on the same server to apply the "+
+			"migrations to. Defaults to a temporary database for sqlite3, sqlite, and libsql "+
+			"and is required for other drivers.",
+	)
+}
+
+func (cmd *DiffCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	scratchCfg := cfg.Connection
+	if cmd.scratchDBName != "" {
+		scratchCfg.DBName = cmd.scratchDBName
+	} else if slices.Contains([]string{"sqlite3", "sqlite", "libsql"}, cfg.Connection.Driver) {
+		scratchDirPath, err := os.MkdirTemp("", "bolt-diff-")
+		if err != nil {
+			outputter.Error(fmt.Errorf("unable to create scratch database: %w", err))
+			return subcommands.ExitFailure
+		}
+		defer os.RemoveAll(scratchDirPath)
+		scratchCfg.DBName = filepath.Join(scratchDirPath, "scratch.db")
+	} else {
+		outputter.Error(errors.New("-scratch-db is required"))
+		return subcommands.ExitUsageError
+	}
+	if scratchCfg.DBName == cfg.Connection.DBName {
+		outputter.Error(errors.New("the scratch database must not be the database being compared"))
+		return subcommands.ExitUsageError
+	}
+	// The migrations are applied in the same schema in the scratch
+	// database, which is empty, so the schema won't exist there yet.
+	scratchCfg.CreateSchema = scratchCfg.Schema != ""
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	scratchDB, err := storage.NewDB(scratchCfg)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to scratch database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer scratchDB.Close()
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	scratchDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, scratchDB)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	scratchMigrationService := services.NewMigrationService(
+		scratchDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+	schemaService := services.NewSchemaService(
+		migrationDBRepo,
+		repositories.NewSchemaFsRepo(cfg.Migrations.SchemaFilePath),
+		outputter,
+	)
+
+	differences, err := schemaService.DiffSchema(scratchDBRepo, scratchMigrationService)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to diff schema: %w", err))
+		return subcommands.ExitFailure
+	}
+	if len(differences) > 0 {
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 
@@ -15,6 +16,9 @@ import (
 
 type DownCmd struct {
 	version string
+	force   bool
+	yes     bool
+	target  string
 }
 
 func (*DownCmd) Name() string {
@@ -26,7 +30,7 @@ func (*DownCmd) Synopsis() string {
 }
 
 func (*DownCmd) Usage() string {
-	return `down [-version|-v]:
+	return `down [-version|-v] [-force] [-yes] [-target]:
 	Downgrade migrations against the database
   `
 }
@@ -39,37 +43,61 @@ func (cmd *DownCmd) SetFlags(f *flag.FlagSet) {
 		"The version to downgrade down and including to.",
 	)
 	f.StringVar(&cmd.version, "v", cmd.version, "alias for -version")
+	f.BoolVar(
+		&cmd.force,
+		"force",
+		false,
+		"Revert migrations even if they are marked as irreversible.",
+	)
+	f.BoolVar(
+		&cmd.yes,
+		"yes",
+		false,
+		"Skip the confirmation for protected databases.",
+	)
+	f.StringVar(&cmd.target, "target", "", targetFlagUsage)
 }
 
 func (cmd *DownCmd) Execute(
 	_ context.Context,
 	f *flag.FlagSet,
-	_ ...interface{},
+	args ...interface{},
 ) subcommands.ExitStatus {
-	consoleOutputter := output.NewConsoleOutputter()
+	outputter := outputterFromArgs(args)
 
 	cfg, err := configloader.NewConfig()
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
 		return subcommands.ExitFailure
 	}
 
+	return runForTarget(cfg, cmd.target, outputter, cmd.run)
+}
+
+// run reverts migrations against the database.
+func (cmd *DownCmd) run(cfg *configloader.Config, outputter output.Outputter) subcommands.ExitStatus {
 	db, err := storage.NewDB(cfg.Connection)
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
 		return subcommands.ExitFailure
 	}
 	defer db.Close()
 
+	err = confirmProtectedDB(cfg.Connection, db, cmd.yes, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
 	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
 	if err != nil {
-		consoleOutputter.Error(err)
+		outputter.Error(err)
 		return subcommands.ExitFailure
 	}
 
 	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
 	if err != nil {
-		consoleOutputter.Error(err)
+		outputter.Error(err)
 		return subcommands.ExitFailure
 	}
 
@@ -77,22 +105,42 @@ func (cmd *DownCmd) Execute(
 		migrationDBRepo,
 		migrationFsRepo,
 		*cfg,
-		consoleOutputter,
+		outputter,
 	)
 
+	opts := services.RevertOptions{Force: cmd.force}
 	if cmd.version == "" {
-		err = migrationService.RevertAllMigrations()
+		err = migrationService.RevertAllMigrations(opts)
 		if err != nil {
-			consoleOutputter.Error(fmt.Errorf("unable to revert all migrations: %w", err))
+			outputter.Error(fmt.Errorf("unable to revert all migrations: %w", err))
+			outputIrreversibleHint(outputter, err)
 			return subcommands.ExitFailure
 		}
 	} else {
-		err = migrationService.RevertDownToVersion(cmd.version)
+		err = migrationService.RevertDownToVersion(cmd.version, opts)
 		if err != nil {
-			consoleOutputter.Error(fmt.Errorf("unable to revert migrations down to %s: %w", cmd.version, err))
+			outputter.Error(fmt.Errorf("unable to revert migrations down to %s: %w", cmd.version, err))
+			outputIrreversibleHint(outputter, err)
 			return subcommands.ExitFailure
 		}
 	}
 
+	err = dumpSchemaIfEnabled(cfg, migrationDBRepo, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
 	return subcommands.ExitSuccess
 }
+
+// outputIrreversibleHint lets the user know how to proceed when
+// they've attempted to revert an irreversible migration.
+func outputIrreversibleHint(outputter output.Outputter, err error) {
+	if errors.Is(err, services.ErrIrreversibleMigration) {
+		outputter.Error(errors.New(
+			"no migrations were reverted, re-run with -force to revert " +
+				"irreversible migrations anyway",
+		))
+	}
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/output"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type DumpCmd struct {
+	schemaFilePath string
+}
+
+func (*DumpCmd) Name() string {
+	return "dump"
+}
+
+func (*DumpCmd) Synopsis() string {
+	return "dump the database's schema to the schema file"
+}
+
+func (*DumpCmd) Usage() string {
+	return `dump [-file]:
+	Dump the database's schema to the schema file
+  `
+}
+
+func (cmd *DumpCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.schemaFilePath,
+		"file",
+		"",
+		"The file to dump the schema to. Defaults to the configured schema file.",
+	)
+}
+
+func (cmd *DumpCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	if cmd.schemaFilePath != "" {
+		cfg.Migrations.SchemaFilePath = cmd.schemaFilePath
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	schemaService := services.NewSchemaService(
+		migrationDBRepo,
+		repositories.NewSchemaFsRepo(cfg.Migrations.SchemaFilePath),
+		outputter,
+	)
+
+	err = schemaService.DumpSchema()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to dump schema: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
+
+// dumpSchemaIfEnabled dumps the database's schema to the schema
+// file when the dump_schema setting is turned on.
+func dumpSchemaIfEnabled(
+	cfg *configloader.Config,
+	migrationDBRepo repositories.MigrationDBRepo,
+	outputter output.Outputter,
+) error {
+	if !cfg.Migrations.DumpSchema {
+		return nil
+	}
+
+	schemaService := services.NewSchemaService(
+		migrationDBRepo,
+		repositories.NewSchemaFsRepo(cfg.Migrations.SchemaFilePath),
+		outputter,
+	)
+
+	err := schemaService.DumpSchema()
+	if err != nil {
+		return fmt.Errorf("unable to dump schema: %w", err)
+	}
+
+	return nil
+}
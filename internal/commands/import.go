@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/importer"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type ImportCmd struct {
+	from      string
+	dir       string
+	tableName string
+}
+
+func (*ImportCmd) Name() string {
+	return "import"
+}
+
+func (*ImportCmd) Synopsis() string {
+	return "import migrations and their applied history from another tool"
+}
+
+func (*ImportCmd) Usage() string {
+	return `import -from -dir [-table]:
+	Import the migrations and applied history of another migration tool.
+	The migration files are converted into bolt's format in the migrations
+	directory and the applied migrations are marked as applied without
+	being run.
+  `
+}
+
+func (cmd *ImportCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.from,
+		"from",
+		"",
+		"The tool to import from: golang-migrate, dbmate, goose, or flyway.",
+	)
+	f.StringVar(
+		&cmd.dir,
+		"dir",
+		"",
+		"The directory containing the other tool's migration files.",
+	)
+	f.StringVar(
+		&cmd.tableName,
+		"table",
+		"",
+		"The table the other tool keeps its history in. Defaults to the "+
+			"tool's default table name.",
+	)
+}
+
+func (cmd *ImportCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	if cmd.from == "" || cmd.dir == "" {
+		outputter.Error(errors.New("-from and -dir are required"))
+		return subcommands.ExitUsageError
+	}
+
+	source, err := importer.NewSource(cmd.from, cmd.tableName)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to import from %s: %w", cmd.from, err))
+		return subcommands.ExitFailure
+	}
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	migrations, err := source.Migrations(cmd.dir)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to read %s migrations: %w", cmd.from, err))
+		return subcommands.ExitFailure
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	appliedVersions, err := source.AppliedVersions(db, migrations)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to read %s history: %w", cmd.from, err))
+		return subcommands.ExitFailure
+	}
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+
+	err = migrationService.ImportMigrations(migrations, appliedVersions)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to import from %s: %w", cmd.from, err))
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type LoadCmd struct {
+	schemaFilePath string
+}
+
+func (*LoadCmd) Name() string {
+	return "load"
+}
+
+func (*LoadCmd) Synopsis() string {
+	return "load the schema file into a fresh database"
+}
+
+func (*LoadCmd) Usage() string {
+	return `load [-file]:
+	Load the schema file into a fresh database and mark the
+	migrations it was dumped at as applied
+  `
+}
+
+func (cmd *LoadCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.schemaFilePath,
+		"file",
+		"",
+		"The file to load the schema from. Defaults to the configured schema file.",
+	)
+}
+
+func (cmd *LoadCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	if cmd.schemaFilePath != "" {
+		cfg.Migrations.SchemaFilePath = cmd.schemaFilePath
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	schemaService := services.NewSchemaService(
+		migrationDBRepo,
+		repositories.NewSchemaFsRepo(cfg.Migrations.SchemaFilePath),
+		outputter,
+	)
+
+	err = schemaService.LoadSchema()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to load schema: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
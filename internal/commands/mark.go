@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type MarkCmd struct {
+	applied   string
+	unapplied string
+	yes       bool
+}
+
+func (*MarkCmd) Name() string {
+	return "mark"
+}
+
+func (*MarkCmd) Synopsis() string {
+	return "mark a single migration as applied or unapplied without running it"
+}
+
+func (*MarkCmd) Usage() string {
+	return `mark -applied|-unapplied [-yes]:
+	Mark a single migration as applied or unapplied without running
+	its upgrade or downgrade script. Use this to fix up the migration
+	history after a manual intervention.
+  `
+}
+
+func (cmd *MarkCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.applied,
+		"applied",
+		"",
+		"The version to mark as applied.",
+	)
+	f.StringVar(
+		&cmd.unapplied,
+		"unapplied",
+		"",
+		"The version to mark as unapplied.",
+	)
+	f.BoolVar(
+		&cmd.yes,
+		"yes",
+		false,
+		"Skip the confirmation for protected databases.",
+	)
+}
+
+func (cmd *MarkCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	if (cmd.applied == "") == (cmd.unapplied == "") {
+		outputter.Error(errors.New("exactly one of -applied or -unapplied is required"))
+		return subcommands.ExitUsageError
+	}
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	err = confirmProtectedDB(cfg.Connection, db, cmd.yes, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+
+	if cmd.applied != "" {
+		err = migrationService.MarkMigrationApplied(cmd.applied)
+		if err != nil {
+			outputter.Error(fmt.Errorf("unable to mark %s as applied: %w", cmd.applied, err))
+			return subcommands.ExitFailure
+		}
+	} else {
+		err = migrationService.MarkMigrationUnapplied(cmd.unapplied)
+		if err != nil {
+			outputter.Error(fmt.Errorf("unable to mark %s as unapplied: %w", cmd.unapplied, err))
+			return subcommands.ExitFailure
+		}
+	}
+
+	return subcommands.ExitSuccess
+}
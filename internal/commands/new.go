@@ -2,11 +2,11 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
-	"github.com/eugenetriguba/bolt/internal/output"
 	"github.com/eugenetriguba/bolt/internal/repositories"
 	"github.com/eugenetriguba/bolt/internal/services"
 	"github.com/google/subcommands"
@@ -14,6 +14,8 @@ import (
 
 type NewCmd struct {
 	message string
+	layout  string
+	target  string
 }
 
 func (*NewCmd) Name() string {
@@ -25,7 +27,7 @@ func (*NewCmd) Synopsis() string {
 }
 
 func (*NewCmd) Usage() string {
-	return `new [-message|-m]:
+	return `new [-message|-m] [-layout] [-target]:
 	Create a new database migration
   `
 }
@@ -38,24 +40,52 @@ func (cmd *NewCmd) SetFlags(f *flag.FlagSet) {
 		"Message to use for the migration",
 	)
 	f.StringVar(&cmd.message, "m", cmd.message, "alias for -message")
+	f.StringVar(
+		&cmd.layout,
+		"layout",
+		"",
+		"The layout to create the migration with: single, split, or directory. "+
+			"Defaults to the configured layout.",
+	)
+	f.StringVar(
+		&cmd.target,
+		"target",
+		"",
+		"The configured target to create the migration for. Defaults to the top-level settings.",
+	)
 }
 
 func (cmd *NewCmd) Execute(
 	_ context.Context,
 	f *flag.FlagSet,
-	_ ...interface{},
+	args ...interface{},
 ) subcommands.ExitStatus {
-	consoleOutputter := output.NewConsoleOutputter()
+	outputter := outputterFromArgs(args)
 
 	cfg, err := configloader.NewConfig()
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
 		return subcommands.ExitFailure
 	}
 
+	if cmd.target == configloader.AllTargets {
+		outputter.Error(errors.New("-target all can't be used to create a migration, choose a single target"))
+		return subcommands.ExitUsageError
+	} else if cmd.target != "" {
+		cfg, err = cfg.Target(cmd.target)
+		if err != nil {
+			outputter.Error(err)
+			return subcommands.ExitFailure
+		}
+	}
+
+	if cmd.layout != "" {
+		cfg.Migrations.Layout = configloader.MigrationLayout(cmd.layout)
+	}
+
 	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
 	if err != nil {
-		consoleOutputter.Error(
+		outputter.Error(
 			fmt.Errorf("unable to setup local migrations directory: %w", err),
 		)
 		return subcommands.ExitFailure
@@ -65,12 +95,12 @@ func (cmd *NewCmd) Execute(
 		nil,
 		migrationFsRepo,
 		*cfg,
-		consoleOutputter,
+		outputter,
 	)
 
 	_, err = migrationService.CreateMigration(cmd.message)
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to create new migration: %w", err))
+		outputter.Error(fmt.Errorf("unable to create new migration: %w", err))
 		return subcommands.ExitFailure
 	}
 
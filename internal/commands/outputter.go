@@ -0,0 +1,15 @@
+package commands
+
+import "github.com/eugenetriguba/bolt/internal/output"
+
+// outputterFromArgs retrieves the outputter for the output format
+// the CLI was run with, which is passed along to each command's
+// Execute. It falls back to console output when there isn't one.
+func outputterFromArgs(args []interface{}) output.Outputter {
+	for _, arg := range args {
+		if outputter, ok := arg.(output.Outputter); ok {
+			return outputter
+		}
+	}
+	return output.NewConsoleOutputter()
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type ResetCmd struct {
+	hard  bool
+	force bool
+	yes   bool
+}
+
+func (*ResetCmd) Name() string {
+	return "reset"
+}
+
+func (*ResetCmd) Synopsis() string {
+	return "revert all migrations and apply them again"
+}
+
+func (*ResetCmd) Usage() string {
+	return `reset [-hard] [-force] [-yes]:
+	Revert all migrations and apply them again. Only runs against
+	databases whose environment is development or test.
+  `
+}
+
+func (cmd *ResetCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(
+		&cmd.hard,
+		"hard",
+		false,
+		"Drop and re-create the database instead of reverting the migrations.",
+	)
+	f.BoolVar(
+		&cmd.force,
+		"force",
+		false,
+		"Revert migrations even if they are marked as irreversible.",
+	)
+	f.BoolVar(
+		&cmd.yes,
+		"yes",
+		false,
+		"Skip the confirmation for protected databases.",
+	)
+}
+
+func (cmd *ResetCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	if !cfg.Connection.Environment.NonProduction() {
+		outputter.Error(fmt.Errorf(
+			"refusing to reset the database: its environment must be set to %s or %s",
+			configloader.EnvironmentDevelopment,
+			configloader.EnvironmentTest,
+		))
+		return subcommands.ExitFailure
+	}
+
+	if cmd.hard {
+		// The database is dropped before connecting to it, so
+		// the configured name stands in for the reported one.
+		err = confirmProtected(cfg.Connection, cfg.Connection.DBName, cmd.yes, outputter)
+		if err != nil {
+			outputter.Error(err)
+			return subcommands.ExitFailure
+		}
+
+		err = storage.RecreateDatabase(cfg.Connection)
+		if err != nil {
+			outputter.Error(fmt.Errorf("unable to re-create database: %w", err))
+			return subcommands.ExitFailure
+		}
+		if cfg.Connection.Schema != "" {
+			outputter.Output(fmt.Sprintf("Re-created schema %s.", cfg.Connection.Schema))
+		} else {
+			outputter.Output(fmt.Sprintf("Re-created database %s.", cfg.Connection.DBName))
+		}
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	if !cmd.hard {
+		err = confirmProtectedDB(cfg.Connection, db, cmd.yes, outputter)
+		if err != nil {
+			outputter.Error(err)
+			return subcommands.ExitFailure
+		}
+	}
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+
+	err = migrationService.ResetMigrations(services.RevertOptions{Force: cmd.force})
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to reset migrations: %w", err))
+		if errors.Is(err, services.ErrIrreversibleMigration) {
+			outputter.Error(errors.New(
+				"no migrations were reverted, re-run with -force to revert " +
+					"irreversible migrations anyway or with -hard to re-create " +
+					"the database instead",
+			))
+		}
+		return subcommands.ExitFailure
+	}
+
+	err = dumpSchemaIfEnabled(cfg, migrationDBRepo, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
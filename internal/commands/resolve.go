@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type ResolveCmd struct {
+	version    string
+	applied    bool
+	rolledBack bool
+	yes        bool
+}
+
+func (*ResolveCmd) Name() string {
+	return "resolve"
+}
+
+func (*ResolveCmd) Synopsis() string {
+	return "resolve a dirty migration after fixing the database by hand"
+}
+
+func (*ResolveCmd) Usage() string {
+	return `resolve -version -applied|-rolled-back [-yes]:
+	Resolve a migration left dirty by a transaction:false script that
+	failed partway through. Fix the database by hand first, then record
+	whether the migration ended up applied or rolled back.
+  `
+}
+
+func (cmd *ResolveCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.version,
+		"version",
+		"",
+		"The version of the dirty migration.",
+	)
+	f.BoolVar(
+		&cmd.applied,
+		"applied",
+		false,
+		"Record the migration as applied.",
+	)
+	f.BoolVar(
+		&cmd.rolledBack,
+		"rolled-back",
+		false,
+		"Record the migration as rolled back.",
+	)
+	f.BoolVar(
+		&cmd.yes,
+		"yes",
+		false,
+		"Skip the confirmation for protected databases.",
+	)
+}
+
+func (cmd *ResolveCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	if cmd.version == "" {
+		outputter.Error(errors.New("-version is required"))
+		return subcommands.ExitUsageError
+	}
+	if cmd.applied == cmd.rolledBack {
+		outputter.Error(errors.New("exactly one of -applied or -rolled-back is required"))
+		return subcommands.ExitUsageError
+	}
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	err = confirmProtectedDB(cfg.Connection, db, cmd.yes, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+
+	err = migrationService.ResolveMigration(cmd.version, cmd.applied)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to resolve %s: %w", cmd.version, err))
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/services"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/google/subcommands"
+)
+
+type SquashCmd struct {
+	to             string
+	archiveDirPath string
+	yes            bool
+}
+
+func (*SquashCmd) Name() string {
+	return "squash"
+}
+
+func (*SquashCmd) Synopsis() string {
+	return "squash migrations into a single migration"
+}
+
+func (*SquashCmd) Usage() string {
+	return `squash -to [-archive-dir] [-yes]:
+	Replace every migration up to and including a version with a single
+	migration containing a dump of the database's schema. The database
+	must have exactly those migrations applied. The original migrations
+	are moved into the archive directory.
+  `
+}
+
+func (cmd *SquashCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(
+		&cmd.to,
+		"to",
+		"",
+		"The version to squash up to and including.",
+	)
+	f.StringVar(
+		&cmd.archiveDirPath,
+		"archive-dir",
+		"",
+		"The directory to move the original migrations into. Defaults to "+
+			"the migrations directory with an _archive suffix.",
+	)
+	f.BoolVar(
+		&cmd.yes,
+		"yes",
+		false,
+		"Skip the confirmation for protected databases.",
+	)
+}
+
+func (cmd *SquashCmd) Execute(
+	_ context.Context,
+	f *flag.FlagSet,
+	args ...interface{},
+) subcommands.ExitStatus {
+	outputter := outputterFromArgs(args)
+
+	if cmd.to == "" {
+		outputter.Error(errors.New("-to is required"))
+		return subcommands.ExitUsageError
+	}
+
+	cfg, err := configloader.NewConfig()
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	archiveDirPath := cmd.archiveDirPath
+	if archiveDirPath == "" {
+		archiveDirPath = filepath.Clean(cfg.Migrations.DirectoryPath) + "_archive"
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		return subcommands.ExitFailure
+	}
+	defer db.Close()
+
+	err = confirmProtectedDB(cfg.Connection, db, cmd.yes, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		*cfg,
+		outputter,
+	)
+
+	err = migrationService.SquashToVersion(cmd.to, archiveDirPath)
+	if err != nil {
+		outputter.Error(fmt.Errorf("unable to squash migrations up to %s: %w", cmd.to, err))
+		return subcommands.ExitFailure
+	}
+
+	return subcommands.ExitSuccess
+}
@@ -2,10 +2,12 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/bolt/internal/output"
 	"github.com/eugenetriguba/bolt/internal/repositories"
 	"github.com/eugenetriguba/bolt/internal/services"
@@ -13,7 +15,12 @@ import (
 	"github.com/google/subcommands"
 )
 
-type StatusCmd struct{}
+type StatusCmd struct {
+	pending  bool
+	applied  bool
+	exitCode bool
+	target   string
+}
 
 func (*StatusCmd) Name() string {
 	return "status"
@@ -24,42 +31,72 @@ func (*StatusCmd) Synopsis() string {
 }
 
 func (*StatusCmd) Usage() string {
-	return `status:
+	return `status [-pending] [-applied] [-exit-code] [-target]:
 	List the database migrations and their statuses
   `
 }
 
-func (m *StatusCmd) SetFlags(f *flag.FlagSet) {}
+func (m *StatusCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(
+		&m.pending,
+		"pending",
+		false,
+		"Only list the migrations that aren't applied.",
+	)
+	f.BoolVar(
+		&m.applied,
+		"applied",
+		false,
+		"Only list the migrations that are applied.",
+	)
+	f.BoolVar(
+		&m.exitCode,
+		"exit-code",
+		false,
+		"Exit with a non-zero exit code when there are migrations that aren't applied or are dirty.",
+	)
+	f.StringVar(&m.target, "target", "", targetFlagUsage)
+}
 
 func (m *StatusCmd) Execute(
 	_ context.Context,
 	f *flag.FlagSet,
-	_ ...interface{},
+	args ...interface{},
 ) subcommands.ExitStatus {
-	consoleOutputter := output.NewConsoleOutputter()
+	outputter := outputterFromArgs(args)
+
+	if m.pending && m.applied {
+		outputter.Error(errors.New("-pending and -applied cannot be used together"))
+		return subcommands.ExitUsageError
+	}
 
 	cfg, err := configloader.NewConfig()
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
 		return subcommands.ExitFailure
 	}
 
+	return runForTarget(cfg, m.target, outputter, m.run)
+}
+
+// run lists the migrations and their statuses for the database.
+func (m *StatusCmd) run(cfg *configloader.Config, outputter output.Outputter) subcommands.ExitStatus {
 	db, err := storage.NewDB(cfg.Connection)
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
 		return subcommands.ExitFailure
 	}
 	defer db.Close()
 
 	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
 	if err != nil {
-		consoleOutputter.Error(err)
+		outputter.Error(err)
 		return subcommands.ExitFailure
 	}
 
 	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
 	if err != nil {
-		consoleOutputter.Error(err)
+		outputter.Error(err)
 		return subcommands.ExitFailure
 	}
 
@@ -67,41 +104,51 @@ func (m *StatusCmd) Execute(
 		migrationDBRepo,
 		migrationFsRepo,
 		*cfg,
-		consoleOutputter,
+		outputter,
 	)
 
-	migrations, err := migrationService.ListMigrations(services.SortOrderAsc)
+	migrations, err := migrationService.ListMigrationStatuses()
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to list migrations: %w", err))
+		outputter.Error(fmt.Errorf("unable to list migrations: %w", err))
 		return subcommands.ExitFailure
 	}
 
-	if len(migrations) == 0 {
-		consoleOutputter.Output(
-			"No migrations have been created.\n" +
-				"Run 'bolt new' to create your first migration.",
-		)
-		return subcommands.ExitSuccess
-	}
-
-	headers := []string{"Version", "Message", "Applied"}
-	rows := make([][]string, len(migrations))
+	statuses := make([]output.MigrationStatus, len(migrations))
 	for i, migration := range migrations {
-		applied := ""
-		if migration.Applied {
-			applied = "X"
+		statuses[i] = output.MigrationStatus{
+			Version:   migration.Migration.Version,
+			Message:   migration.Migration.Message,
+			Applied:   migration.Migration.Applied,
+			State:     migration.State,
+			AppliedAt: migration.Migration.AppliedAt,
+			Duration:  migration.Migration.ExecutionTime,
 		}
+	}
+	summary := output.NewStatusSummary(statuses)
 
-		rows[i] = []string{migration.Version, migration.Message, applied}
+	filteredStatuses := make([]output.MigrationStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if (m.pending && status.Applied) || (m.applied && !status.Applied) {
+			continue
+		}
+		filteredStatuses = append(filteredStatuses, status)
 	}
 
-	err = consoleOutputter.Table(headers, rows)
+	err = outputter.Status(filteredStatuses, summary)
 	if err != nil {
-		consoleOutputter.Error(
-			fmt.Errorf("unable to output migrations as table: %w", err),
+		outputter.Error(
+			fmt.Errorf("unable to output migration statuses: %w", err),
 		)
 		return subcommands.ExitFailure
 	}
 
+	if m.exitCode {
+		for _, status := range statuses {
+			if !status.Applied || status.State == models.MigrationStateDirty {
+				return subcommands.ExitFailure
+			}
+		}
+	}
+
 	return subcommands.ExitSuccess
 }
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/output"
+	"github.com/google/subcommands"
+)
+
+// targetFlagUsage is the usage of the -target flag for
+// commands that can be run against every target.
+const targetFlagUsage = "The configured target to run against, or \"all\" to run " +
+	"against every target in turn. Defaults to the top-level settings."
+
+// runForTarget runs the command against the target chosen with the
+// -target flag. Without a target, it is run against the top-level
+// settings. With "all", it is run against every target in turn,
+// even when it fails for one of them, and a summary of how it went
+// for each target is output at the end.
+func runForTarget(
+	cfg *configloader.Config,
+	target string,
+	outputter output.Outputter,
+	run func(cfg *configloader.Config, outputter output.Outputter) subcommands.ExitStatus,
+) subcommands.ExitStatus {
+	if target == "" {
+		return run(cfg, outputter)
+	}
+	if target != configloader.AllTargets {
+		targetCfg, err := cfg.Target(target)
+		if err != nil {
+			outputter.Error(err)
+			return subcommands.ExitFailure
+		}
+		return run(targetCfg, outputter)
+	}
+
+	names := cfg.TargetNames()
+	if len(names) == 0 {
+		outputter.Error(errors.New("no targets are configured in the configuration file"))
+		return subcommands.ExitFailure
+	}
+
+	failed := 0
+	rows := make([][]string, len(names))
+	for i, name := range names {
+		targetCfg, err := cfg.Target(name)
+		if err != nil {
+			outputter.Error(err)
+			return subcommands.ExitFailure
+		}
+
+		status := "succeeded"
+		if run(targetCfg, output.NewTargetOutputter(outputter, name)) != subcommands.ExitSuccess {
+			failed++
+			status = "failed"
+		}
+		rows[i] = []string{name, status}
+	}
+
+	err := outputter.Table([]string{"Target", "Status"}, rows)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+	outputter.Output(fmt.Sprintf("%d targets succeeded, %d failed.", len(names)-failed, failed))
+
+	if failed > 0 {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
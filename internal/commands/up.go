@@ -2,8 +2,10 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"sync"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
 	"github.com/eugenetriguba/bolt/internal/output"
@@ -14,7 +16,12 @@ import (
 )
 
 type UpCmd struct {
-	version string
+	version           string
+	atomic            bool
+	rollbackOnFailure bool
+	tenants           bool
+	parallelism       int
+	target            string
 }
 
 func (*UpCmd) Name() string {
@@ -26,7 +33,7 @@ func (*UpCmd) Synopsis() string {
 }
 
 func (*UpCmd) Usage() string {
-	return `up [-version|-v]:
+	return `up [-version|-v] [-atomic] [-rollback-on-failure] [-tenants [-parallelism]] [-target]:
 	Apply migrations against the database
   `
 }
@@ -39,37 +46,96 @@ func (cmd *UpCmd) SetFlags(f *flag.FlagSet) {
 		"The version to upgrade up and including to.",
 	)
 	f.StringVar(&cmd.version, "v", cmd.version, "alias for -version")
+	f.BoolVar(
+		&cmd.atomic,
+		"atomic",
+		false,
+		"Apply every migration within a single transaction. Defaults to the configured single_transaction setting.",
+	)
+	f.BoolVar(
+		&cmd.rollbackOnFailure,
+		"rollback-on-failure",
+		false,
+		"Revert the migrations applied by this run, in reverse order, when one fails to apply.",
+	)
+	f.BoolVar(
+		&cmd.tenants,
+		"tenants",
+		false,
+		"Apply migrations against each of the configured tenants, rather than the database.",
+	)
+	f.IntVar(
+		&cmd.parallelism,
+		"parallelism",
+		0,
+		"How many tenants to migrate at a time. Defaults to the configured tenants parallelism.",
+	)
+	f.StringVar(&cmd.target, "target", "", targetFlagUsage)
 }
 
 func (cmd *UpCmd) Execute(
 	_ context.Context,
 	f *flag.FlagSet,
-	_ ...interface{},
+	args ...interface{},
 ) subcommands.ExitStatus {
-	consoleOutputter := output.NewConsoleOutputter()
+	outputter := outputterFromArgs(args)
 
 	cfg, err := configloader.NewConfig()
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		outputter.Error(fmt.Errorf("unable to retrieve configuration: %w", err))
+		return subcommands.ExitFailure
+	}
+
+	return runForTarget(cfg, cmd.target, outputter, cmd.run)
+}
+
+// run applies migrations against the database, or against
+// each of the tenants when -tenants is given.
+func (cmd *UpCmd) run(cfg *configloader.Config, outputter output.Outputter) subcommands.ExitStatus {
+	opts := services.ApplyOptions{
+		Atomic:            cmd.atomic || cfg.Migrations.SingleTransaction,
+		RollbackOnFailure: cmd.rollbackOnFailure,
+	}
+	if opts.Atomic && opts.RollbackOnFailure {
+		outputter.Error(errors.New(
+			"-rollback-on-failure can't be used when applying migrations in a single " +
+				"transaction, which already rolls back every migration on failure",
+		))
+		return subcommands.ExitUsageError
+	}
+	if opts.Atomic && !storage.SupportsTransactionalDDL(cfg.Connection.Driver) {
+		outputter.Error(fmt.Errorf(
+			"unable to apply migrations in a single transaction: the %s driver "+
+				"commits DDL statements implicitly, so they can't be rolled back",
+			cfg.Connection.Driver,
+		))
 		return subcommands.ExitFailure
 	}
 
+	if cmd.tenants {
+		return cmd.migrateTenants(cfg, opts, outputter)
+	}
+	if cmd.parallelism != 0 {
+		outputter.Error(errors.New("-parallelism can only be used with -tenants"))
+		return subcommands.ExitUsageError
+	}
+
 	db, err := storage.NewDB(cfg.Connection)
 	if err != nil {
-		consoleOutputter.Error(fmt.Errorf("unable to connect to database: %w", err))
+		outputter.Error(fmt.Errorf("unable to connect to database: %w", err))
 		return subcommands.ExitFailure
 	}
 	defer db.Close()
 
 	migrationDBRepo, err := repositories.NewMigrationDBRepo(cfg.Connection.MigrationsTable, db)
 	if err != nil {
-		consoleOutputter.Error(err)
+		outputter.Error(err)
 		return subcommands.ExitFailure
 	}
 
 	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
 	if err != nil {
-		consoleOutputter.Error(err)
+		outputter.Error(err)
 		return subcommands.ExitFailure
 	}
 
@@ -77,22 +143,182 @@ func (cmd *UpCmd) Execute(
 		migrationDBRepo,
 		migrationFsRepo,
 		*cfg,
-		consoleOutputter,
+		outputter,
 	)
 
 	if cmd.version == "" {
-		err = migrationService.ApplyAllMigrations()
+		err = migrationService.ApplyAllMigrations(opts)
 		if err != nil {
-			consoleOutputter.Error(fmt.Errorf("unable to apply all migrations: %w", err))
+			outputter.Error(fmt.Errorf("unable to apply all migrations: %w", err))
 			return subcommands.ExitFailure
 		}
 	} else {
-		err = migrationService.ApplyUpToVersion(cmd.version)
+		err = migrationService.ApplyUpToVersion(cmd.version, opts)
 		if err != nil {
-			consoleOutputter.Error(fmt.Errorf("unable to apply migrations up to %s: %w", cmd.version, err))
+			outputter.Error(fmt.Errorf("unable to apply migrations up to %s: %w", cmd.version, err))
 			return subcommands.ExitFailure
 		}
 	}
 
+	err = dumpSchemaIfEnabled(cfg, migrationDBRepo, outputter)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+
 	return subcommands.ExitSuccess
 }
+
+// migrateTenants applies migrations against each tenant, with
+// each having its own migrations table, and outputs a summary
+// of how every tenant was migrated. A tenant failing to be
+// migrated doesn't stop the others from being migrated.
+func (cmd *UpCmd) migrateTenants(
+	cfg *configloader.Config,
+	opts services.ApplyOptions,
+	outputter output.Outputter,
+) subcommands.ExitStatus {
+	parallelism := cfg.Tenants.Parallelism
+	if cmd.parallelism < 0 {
+		outputter.Error(errors.New("-parallelism must be at least 1"))
+		return subcommands.ExitUsageError
+	} else if cmd.parallelism > 0 {
+		parallelism = cmd.parallelism
+	}
+
+	if cfg.Tenants.Target == configloader.TenantTargetSchema &&
+		!storage.SupportsSchemas(cfg.Connection.Driver) {
+		outputter.Error(fmt.Errorf(
+			"unable to migrate tenants as schemas: %w, set the tenants target to database instead",
+			storage.ErrSchemasUnsupported,
+		))
+		return subcommands.ExitFailure
+	}
+
+	tenants, err := listTenants(cfg)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+	if len(tenants) == 0 {
+		outputter.Output("No tenants were found to migrate.")
+		return subcommands.ExitSuccess
+	}
+
+	var mu sync.Mutex
+	results := services.MigrateTenants(tenants, parallelism, func(tenant string) (string, error) {
+		tenantOutputter := output.NewTenantOutputter(outputter, tenant, &mu)
+		return cmd.migrateTenant(cfg, cfg.Tenants.Connection(cfg.Connection, tenant), opts, tenantOutputter)
+	})
+
+	failed := 0
+	rows := make([][]string, len(results))
+	for i, result := range results {
+		status := "migrated"
+		errMessage := ""
+		if result.Err != nil {
+			failed++
+			status = "failed"
+			errMessage = result.Err.Error()
+		}
+		version := result.Version
+		if version == "" {
+			version = "none"
+		}
+		rows[i] = []string{result.Tenant, status, version, errMessage}
+	}
+
+	err = outputter.Table([]string{"Tenant", "Status", "Version", "Error"}, rows)
+	if err != nil {
+		outputter.Error(err)
+		return subcommands.ExitFailure
+	}
+	outputter.Output(fmt.Sprintf("%d tenants migrated, %d failed.", len(results)-failed, failed))
+
+	if failed > 0 {
+		outputter.Error(fmt.Errorf("unable to migrate %d of %d tenants", failed, len(results)))
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+// migrateTenant applies migrations against a single tenant and
+// gives back its current version afterwards, which is set even
+// when applying the migrations failed partway through.
+func (cmd *UpCmd) migrateTenant(
+	cfg *configloader.Config,
+	connection configloader.ConnectionConfig,
+	opts services.ApplyOptions,
+	outputter output.Outputter,
+) (string, error) {
+	db, err := storage.NewDB(connection)
+	if err != nil {
+		return "", fmt.Errorf("unable to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	migrationDBRepo, err := repositories.NewMigrationDBRepo(connection.MigrationsTable, db)
+	if err != nil {
+		return "", err
+	}
+
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&cfg.Migrations)
+	if err != nil {
+		return "", err
+	}
+
+	tenantCfg := *cfg
+	tenantCfg.Connection = connection
+	migrationService := services.NewMigrationService(
+		migrationDBRepo,
+		migrationFsRepo,
+		tenantCfg,
+		outputter,
+	)
+
+	var applyErr error
+	if cmd.version == "" {
+		applyErr = migrationService.ApplyAllMigrations(opts)
+		if applyErr != nil {
+			applyErr = fmt.Errorf("unable to apply all migrations: %w", applyErr)
+		}
+	} else {
+		applyErr = migrationService.ApplyUpToVersion(cmd.version, opts)
+		if applyErr != nil {
+			applyErr = fmt.Errorf("unable to apply migrations up to %s: %w", cmd.version, applyErr)
+		}
+	}
+
+	current, _, err := migrationService.CurrentMigration()
+	if err != nil {
+		return "", errors.Join(applyErr, err)
+	}
+	version := ""
+	if current != nil {
+		version = current.Version
+	}
+
+	return version, applyErr
+}
+
+// listTenants gives back the configured tenants, running the
+// tenants query against the database to discover them when
+// there is one.
+func listTenants(cfg *configloader.Config) ([]string, error) {
+	if cfg.Tenants.Query == "" {
+		if len(cfg.Tenants.List) == 0 {
+			return nil, errors.New(
+				"no tenants are configured, set either the tenants query or list",
+			)
+		}
+		return cfg.Tenants.List, nil
+	}
+
+	db, err := storage.NewDB(cfg.Connection)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	return repositories.ListTenants(db, cfg.Tenants.Query)
+}
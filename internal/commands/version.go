@@ -4,11 +4,12 @@ import (
 	"context"
 	"flag"
 
-	"github.com/eugenetriguba/bolt/internal/output"
 	"github.com/google/subcommands"
 )
 
-type VersionCmd struct{}
+type VersionCmd struct {
+	db bool
+}
 
 func (*VersionCmd) Name() string {
 	return "version"
@@ -19,22 +20,33 @@ func (*VersionCmd) Synopsis() string {
 }
 
 func (*VersionCmd) Usage() string {
-	return `version:
+	return `version [-db]:
 	Show the current version of Bolt
   `
 }
 
-func (cmd *VersionCmd) SetFlags(f *flag.FlagSet) {}
+func (cmd *VersionCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(
+		&cmd.db,
+		"db",
+		false,
+		"Show the version of the latest applied migration instead, like 'bolt current'.",
+	)
+}
 
 func (cmd *VersionCmd) Execute(
 	_ context.Context,
 	f *flag.FlagSet,
-	_ ...interface{},
+	args ...interface{},
 ) subcommands.ExitStatus {
-	consoleOutputter := output.NewConsoleOutputter()
-	err := consoleOutputter.Output("bolt v0.10.1")
+	outputter := outputterFromArgs(args)
+	if cmd.db {
+		return outputCurrentVersion(outputter, false)
+	}
+
+	err := outputter.Result("bolt v0.10.1")
 	if err != nil {
-		consoleOutputter.Error(err)
+		outputter.Error(err)
 		return subcommands.ExitFailure
 	}
 
@@ -14,6 +14,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
 	"github.com/kelseyhightower/envconfig"
@@ -26,6 +30,71 @@ const (
 	VersionStyleTimestamp  VersionStyle = "timestamp"
 )
 
+// MigrationLayout is how a migration's upgrade and downgrade
+// scripts are laid out on the filesystem.
+type MigrationLayout string
+
+const (
+	// MigrationLayoutSingle is a single <version>_<message>.sql file
+	// with both an upgrade and downgrade section.
+	MigrationLayoutSingle MigrationLayout = "single"
+	// MigrationLayoutSplit is a <version>_<message>.up.sql and
+	// <version>_<message>.down.sql file pair.
+	MigrationLayoutSplit MigrationLayout = "split"
+	// MigrationLayoutDirectory is a <version>_<message> directory
+	// with an up.sql and down.sql file within it.
+	MigrationLayoutDirectory MigrationLayout = "directory"
+)
+
+// Valid checks whether the layout is one of the supported layouts.
+func (l MigrationLayout) Valid() bool {
+	return l == MigrationLayoutSingle ||
+		l == MigrationLayoutSplit ||
+		l == MigrationLayoutDirectory
+}
+
+// TenantTarget is what each tenant is when migrating many tenants.
+type TenantTarget string
+
+const (
+	// TenantTargetSchema is a schema per tenant within the
+	// configured database.
+	TenantTargetSchema TenantTarget = "schema"
+	// TenantTargetDatabase is a database per tenant on the
+	// configured server.
+	TenantTargetDatabase TenantTarget = "database"
+)
+
+// Valid checks whether the target is one of the supported targets.
+func (t TenantTarget) Valid() bool {
+	return t == TenantTargetSchema || t == TenantTargetDatabase
+}
+
+// Environment is the kind of environment a database is used in.
+type Environment string
+
+const (
+	EnvironmentDevelopment Environment = "development"
+	EnvironmentTest        Environment = "test"
+	EnvironmentProduction  Environment = "production"
+)
+
+// Valid checks whether the environment is one of the supported
+// environments. An environment doesn't have to be set.
+func (e Environment) Valid() bool {
+	return e == "" ||
+		e == EnvironmentDevelopment ||
+		e == EnvironmentTest ||
+		e == EnvironmentProduction
+}
+
+// NonProduction checks whether the environment is explicitly
+// marked as a development or test environment. An environment
+// that isn't set could be production, so it isn't one.
+func (e Environment) NonProduction() bool {
+	return e == EnvironmentDevelopment || e == EnvironmentTest
+}
+
 var (
 	ErrConfigFileNotFound = errors.New(
 		"bolt configuration file not found in current directory or any parent directories",
@@ -34,6 +103,37 @@ var (
 		"invalid version style for bolt migrations. supported styles: %v",
 		[]VersionStyle{VersionStyleSequential, VersionStyleTimestamp},
 	)
+	ErrInvalidMigrationLayout = fmt.Errorf(
+		"invalid layout for bolt migrations. supported layouts: %v",
+		[]MigrationLayout{
+			MigrationLayoutSingle,
+			MigrationLayoutSplit,
+			MigrationLayoutDirectory,
+		},
+	)
+	ErrNegativeRetries = errors.New(
+		"invalid database retries. connect_retries and statement_retries must not be negative",
+	)
+	ErrInvalidTenantTarget = fmt.Errorf(
+		"invalid tenants target. supported targets: %v",
+		[]TenantTarget{TenantTargetSchema, TenantTargetDatabase},
+	)
+	ErrInvalidTenantsParallelism = errors.New(
+		"invalid tenants parallelism. parallelism must be at least 1",
+	)
+	ErrTargetNotFound    = errors.New("target not found")
+	ErrInvalidTargetName = fmt.Errorf(
+		"invalid target name. %q is reserved for running against every target",
+		AllTargets,
+	)
+	ErrInvalidEnvironment = fmt.Errorf(
+		"invalid database environment. supported environments: %v",
+		[]Environment{
+			EnvironmentDevelopment,
+			EnvironmentTest,
+			EnvironmentProduction,
+		},
+	)
 )
 
 // Config represents the application configuration settings.
@@ -46,11 +146,76 @@ type Config struct {
 	// Information related to how to connect to the database
 	// that is desired to run migrations against.
 	Connection ConnectionConfig `toml:"database"`
+
+	// Tenants is how to find the tenants that `bolt up -tenants`
+	// runs the migrations against.
+	Tenants TenantsConfig `toml:"tenants"`
+
+	// Targets are named databases, each with their own migrations,
+	// that commands can be run against with -target instead. They
+	// can only be configured in the configuration file.
+	Targets map[string]TargetConfig `toml:"targets" ignored:"true"`
+}
+
+// AllTargets is the -target that runs a command
+// against every target in turn.
+const AllTargets = "all"
+
+// TargetConfig is a named database along with its migrations.
+// Settings that aren't set have their default values, rather
+// than the values of the top-level settings.
+type TargetConfig struct {
+	Migrations MigrationsConfig `toml:"migrations"`
+	Connection ConnectionConfig `toml:"database"`
+}
+
+// Target gives back the configuration for running a command
+// against the named target, which has the target's migrations
+// and database settings in place of the top-level ones.
+func (c Config) Target(name string) (*Config, error) {
+	target, ok := c.Targets[name]
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: %s, configured targets: %v",
+			ErrTargetNotFound,
+			name,
+			c.TargetNames(),
+		)
+	}
+
+	c.Migrations = target.Migrations
+	c.Connection = target.Connection
+	return &c, nil
+}
+
+// TargetNames gives back the names of the
+// configured targets in alphabetical order.
+func (c Config) TargetNames() []string {
+	names := make([]string, 0, len(c.Targets))
+	for name := range c.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 type MigrationsConfig struct {
 	DirectoryPath string       `toml:"directory_path" envconfig:"BOLT_MIGRATIONS_DIR_PATH"`
 	VersionStyle  VersionStyle `toml:"version_style"  envconfig:"BOLT_MIGRATIONS_VERSION_STYLE"`
+	// Layout is the layout new migrations are created with. Migrations
+	// in any layout can always be read.
+	Layout MigrationLayout `toml:"layout" envconfig:"BOLT_MIGRATIONS_LAYOUT"`
+	// EmptyDownIrreversible treats migrations with an empty
+	// downgrade script as irreversible.
+	EmptyDownIrreversible bool `toml:"empty_down_irreversible" envconfig:"BOLT_MIGRATIONS_EMPTY_DOWN_IRREVERSIBLE"`
+	// DumpSchema writes the database's schema to the SchemaFilePath
+	// after migrations are successfully applied or reverted.
+	DumpSchema     bool   `toml:"dump_schema" envconfig:"BOLT_MIGRATIONS_DUMP_SCHEMA"`
+	SchemaFilePath string `toml:"schema_file" envconfig:"BOLT_MIGRATIONS_SCHEMA_FILE"`
+	// SingleTransaction applies every migration `bolt up` applies
+	// within a single transaction, so either all of them are
+	// applied or none are.
+	SingleTransaction bool `toml:"single_transaction" envconfig:"BOLT_MIGRATIONS_SINGLE_TRANSACTION"`
 }
 
 type ConnectionConfig struct {
@@ -61,6 +226,84 @@ type ConnectionConfig struct {
 	DBName          string `toml:"dbname"   envconfig:"BOLT_DB_NAME"`
 	Driver          string `toml:"driver"   envconfig:"BOLT_DB_DRIVER"`
 	MigrationsTable string `toml:"migrations_table" envconfig:"BOLT_DB_MIGRATIONS_TABLE"`
+	// Environment is the kind of environment the database is used
+	// in. Destructive development commands like reset only run
+	// against development and test databases.
+	Environment Environment `toml:"environment" envconfig:"BOLT_DB_ENVIRONMENT"`
+	// Protected makes destructive commands ask for the database
+	// name to be typed out before they run against the database.
+	Protected bool `toml:"protected" envconfig:"BOLT_DB_PROTECTED"`
+	// Schema is the schema migrations are run in, and that the
+	// migrations table is created in, by setting the search_path
+	// of every connection. CreateSchema creates it when it doesn't
+	// exist. Only PostgreSQL supports them.
+	Schema       string `toml:"schema"        envconfig:"BOLT_DB_SCHEMA"`
+	CreateSchema bool   `toml:"create_schema" envconfig:"BOLT_DB_CREATE_SCHEMA"`
+	// ConnectTimeout is the total time to spend connecting to the
+	// database, including waiting between retries. ConnectRetries
+	// is how many times connecting is retried. When only the timeout
+	// is set, connecting is retried until the timeout is reached.
+	ConnectTimeout time.Duration `toml:"connect_timeout" envconfig:"BOLT_DB_CONNECT_TIMEOUT"`
+	ConnectRetries int           `toml:"connect_retries" envconfig:"BOLT_DB_CONNECT_RETRIES"`
+	// StatementRetries is how many times statements and transactions
+	// that fail with a transient error, like a deadlock, are retried.
+	StatementRetries int `toml:"statement_retries" envconfig:"BOLT_DB_STATEMENT_RETRIES"`
+}
+
+type TenantsConfig struct {
+	// Query discovers the tenants by running it against the
+	// configured database. The first column of each row it
+	// returns is a tenant.
+	Query string `toml:"query" envconfig:"BOLT_TENANTS_QUERY"`
+	// List is the tenants to use when there isn't a query.
+	List []string `toml:"list" envconfig:"BOLT_TENANTS_LIST"`
+	// Target is whether each tenant is a schema or a database.
+	Target TenantTarget `toml:"target" envconfig:"BOLT_TENANTS_TARGET"`
+	// Parallelism is how many tenants are migrated at a time.
+	Parallelism int `toml:"parallelism" envconfig:"BOLT_TENANTS_PARALLELISM"`
+}
+
+// Connection gives back how to connect to the tenant, which is
+// the connection with either its schema or database name
+// replaced by the tenant.
+func (t TenantsConfig) Connection(cfg ConnectionConfig, tenant string) ConnectionConfig {
+	if t.Target == TenantTargetDatabase {
+		cfg.DBName = tenant
+	} else {
+		cfg.Schema = tenant
+	}
+	return cfg
+}
+
+// IsProtected checks whether destructive commands should ask for
+// confirmation before running against the database. Besides being
+// explicitly protected, production databases are protected, and so
+// are databases whose name or host look like production when the
+// environment isn't set.
+func (c ConnectionConfig) IsProtected() bool {
+	if c.Protected || c.Environment == EnvironmentProduction {
+		return true
+	}
+	if c.Environment.NonProduction() {
+		return false
+	}
+
+	return looksLikeProduction(c.DBName) || looksLikeProduction(c.Host)
+}
+
+// looksLikeProduction checks whether any of the words in the
+// name, such as the "prod" in "myapp-prod", refer to production.
+func looksLikeProduction(name string) bool {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		switch word {
+		case "prod", "prd", "production", "live":
+			return true
+		}
+	}
+	return false
 }
 
 func NewConfig() (*Config, error) {
@@ -70,12 +313,11 @@ func NewConfig() (*Config, error) {
 	}
 
 	cfg := Config{
-		Migrations: MigrationsConfig{
-			DirectoryPath: "migrations",
-			VersionStyle:  VersionStyleTimestamp,
-		},
-		Connection: ConnectionConfig{
-			MigrationsTable: "bolt_migrations",
+		Migrations: defaultMigrationsConfig(),
+		Connection: defaultConnectionConfig(),
+		Tenants: TenantsConfig{
+			Target:      TenantTargetSchema,
+			Parallelism: 1,
 		},
 	}
 	if !errors.Is(err, ErrConfigFileNotFound) {
@@ -83,6 +325,11 @@ func NewConfig() (*Config, error) {
 		if err != nil {
 			return nil, err
 		}
+
+		cfg.Targets, err = decodeTargets(filePath)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = envconfig.Process("", &cfg)
@@ -90,14 +337,101 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
-	if cfg.Migrations.VersionStyle != VersionStyleSequential &&
-		cfg.Migrations.VersionStyle != VersionStyleTimestamp {
-		return nil, ErrInvalidVersionStyle
+	err = validate(cfg.Migrations, cfg.Connection)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, target := range cfg.Targets {
+		if name == AllTargets {
+			return nil, ErrInvalidTargetName
+		}
+		err = validate(target.Migrations, target.Connection)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", name, err)
+		}
+	}
+
+	if !cfg.Tenants.Target.Valid() {
+		return nil, ErrInvalidTenantTarget
+	}
+
+	if cfg.Tenants.Parallelism < 1 {
+		return nil, ErrInvalidTenantsParallelism
 	}
 
 	return &cfg, nil
 }
 
+func defaultMigrationsConfig() MigrationsConfig {
+	return MigrationsConfig{
+		DirectoryPath:  "migrations",
+		VersionStyle:   VersionStyleTimestamp,
+		Layout:         MigrationLayoutSingle,
+		SchemaFilePath: "schema.sql",
+	}
+}
+
+func defaultConnectionConfig() ConnectionConfig {
+	return ConnectionConfig{
+		MigrationsTable: "bolt_migrations",
+	}
+}
+
+// decodeTargets decodes the targets in the configuration file. Each
+// target starts out with the default settings, rather than the zero
+// values they'd have when decoded along with the rest of the file.
+func decodeTargets(filePath string) (map[string]TargetConfig, error) {
+	var file struct {
+		Targets map[string]toml.Primitive `toml:"targets"`
+	}
+	md, err := toml.DecodeFile(filePath, &file)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Targets) == 0 {
+		return nil, nil
+	}
+
+	targets := make(map[string]TargetConfig, len(file.Targets))
+	for name, primitive := range file.Targets {
+		target := TargetConfig{
+			Migrations: defaultMigrationsConfig(),
+			Connection: defaultConnectionConfig(),
+		}
+		err = md.PrimitiveDecode(primitive, &target)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode target %s: %w", name, err)
+		}
+		targets[name] = target
+	}
+
+	return targets, nil
+}
+
+// validate checks that the migrations and database settings
+// have supported values.
+func validate(migrations MigrationsConfig, connection ConnectionConfig) error {
+	if migrations.VersionStyle != VersionStyleSequential &&
+		migrations.VersionStyle != VersionStyleTimestamp {
+		return ErrInvalidVersionStyle
+	}
+
+	if !migrations.Layout.Valid() {
+		return ErrInvalidMigrationLayout
+	}
+
+	if !connection.Environment.Valid() {
+		return ErrInvalidEnvironment
+	}
+
+	if connection.ConnectRetries < 0 || connection.StatementRetries < 0 {
+		return ErrNegativeRetries
+	}
+
+	return nil
+}
+
 func findConfigFilePath() (filePath string, err error) {
 	const configFileName = "bolt.toml"
 	var rootDir = fsRootDir()
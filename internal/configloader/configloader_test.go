@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/eugenetriguba/bolt/internal/bolttest"
 	"github.com/eugenetriguba/bolt/internal/configloader"
@@ -19,7 +20,12 @@ func TestNewConfigDefaults(t *testing.T) {
 
 	check.Equal(t, cfg.Migrations.DirectoryPath, "migrations")
 	check.Equal(t, cfg.Migrations.VersionStyle, configloader.VersionStyleTimestamp)
+	check.Equal(t, cfg.Migrations.Layout, configloader.MigrationLayoutSingle)
+	check.Equal(t, cfg.Migrations.DumpSchema, false)
+	check.Equal(t, cfg.Migrations.SchemaFilePath, "schema.sql")
 	check.Equal(t, cfg.Connection.MigrationsTable, "bolt_migrations")
+	check.Equal(t, cfg.Tenants.Target, configloader.TenantTargetSchema)
+	check.Equal(t, cfg.Tenants.Parallelism, 1)
 }
 
 func TestNewConfigWithInvalidVersionStyle(t *testing.T) {
@@ -35,6 +41,232 @@ func TestNewConfigWithInvalidVersionStyle(t *testing.T) {
 	assert.ErrorIs(t, err, configloader.ErrInvalidVersionStyle)
 }
 
+func TestNewConfigWithInvalidMigrationLayout(t *testing.T) {
+	fileCfg := configloader.Config{
+		Migrations: configloader.MigrationsConfig{
+			DirectoryPath: "myfancymigrations",
+			VersionStyle:  configloader.VersionStyleSequential,
+			Layout:        "invalid",
+		},
+	}
+	tmpdir := t.TempDir()
+	bolttest.ChangeCwd(t, tmpdir)
+	bolttest.CreateConfigFile(t, &fileCfg, filepath.Join(tmpdir, "bolt.toml"))
+
+	_, err := configloader.NewConfig()
+	assert.ErrorIs(t, err, configloader.ErrInvalidMigrationLayout)
+}
+
+func TestNewConfigWithInvalidEnvironment(t *testing.T) {
+	fileCfg := configloader.Config{
+		Migrations: configloader.MigrationsConfig{
+			VersionStyle: configloader.VersionStyleSequential,
+			Layout:       configloader.MigrationLayoutSingle,
+		},
+		Connection: configloader.ConnectionConfig{
+			Environment: "staging",
+		},
+	}
+	tmpdir := t.TempDir()
+	bolttest.ChangeCwd(t, tmpdir)
+	bolttest.CreateConfigFile(t, &fileCfg, filepath.Join(tmpdir, "bolt.toml"))
+
+	_, err := configloader.NewConfig()
+	assert.ErrorIs(t, err, configloader.ErrInvalidEnvironment)
+}
+
+func TestNewConfigWithNegativeRetries(t *testing.T) {
+	bolttest.ChangeCwd(t, t.TempDir())
+	t.Setenv("BOLT_DB_CONNECT_RETRIES", "-1")
+
+	_, err := configloader.NewConfig()
+	assert.ErrorIs(t, err, configloader.ErrNegativeRetries)
+}
+
+func TestNewConfigWithInvalidTenantTarget(t *testing.T) {
+	bolttest.ChangeCwd(t, t.TempDir())
+	t.Setenv("BOLT_TENANTS_TARGET", "table")
+
+	_, err := configloader.NewConfig()
+	assert.ErrorIs(t, err, configloader.ErrInvalidTenantTarget)
+}
+
+func TestNewConfigWithInvalidTenantsParallelism(t *testing.T) {
+	bolttest.ChangeCwd(t, t.TempDir())
+	t.Setenv("BOLT_TENANTS_PARALLELISM", "0")
+
+	_, err := configloader.NewConfig()
+	assert.ErrorIs(t, err, configloader.ErrInvalidTenantsParallelism)
+}
+
+func TestTenantsConfigConnection(t *testing.T) {
+	cfg := configloader.ConnectionConfig{DBName: "myapp", Schema: "public"}
+
+	schemaTenants := configloader.TenantsConfig{Target: configloader.TenantTargetSchema}
+	check.DeepEqual(
+		t,
+		schemaTenants.Connection(cfg, "acme"),
+		configloader.ConnectionConfig{DBName: "myapp", Schema: "acme"},
+	)
+
+	databaseTenants := configloader.TenantsConfig{Target: configloader.TenantTargetDatabase}
+	check.DeepEqual(
+		t,
+		databaseTenants.Connection(cfg, "acme"),
+		configloader.ConnectionConfig{DBName: "acme", Schema: "public"},
+	)
+}
+
+func TestNewConfigWithTargets(t *testing.T) {
+	tmpdir := t.TempDir()
+	bolttest.ChangeCwd(t, tmpdir)
+	err := os.WriteFile(filepath.Join(tmpdir, "bolt.toml"), []byte(`
+[targets.main.migrations]
+directory_path = "migrations/main"
+version_style = "sequential"
+
+[targets.main.database]
+driver = "postgresql"
+dbname = "app"
+
+[targets.cache.database]
+driver = "sqlite3"
+dbname = "cache.db"
+`), 0644)
+	assert.Nil(t, err)
+
+	cfg, err := configloader.NewConfig()
+	assert.Nil(t, err)
+
+	check.DeepEqual(t, cfg.TargetNames(), []string{"cache", "main"})
+	check.DeepEqual(t, cfg.Targets["main"], configloader.TargetConfig{
+		Migrations: configloader.MigrationsConfig{
+			DirectoryPath:  "migrations/main",
+			VersionStyle:   configloader.VersionStyleSequential,
+			Layout:         configloader.MigrationLayoutSingle,
+			SchemaFilePath: "schema.sql",
+		},
+		Connection: configloader.ConnectionConfig{
+			Driver:          "postgresql",
+			DBName:          "app",
+			MigrationsTable: "bolt_migrations",
+		},
+	})
+	check.DeepEqual(t, cfg.Targets["cache"], configloader.TargetConfig{
+		Migrations: configloader.MigrationsConfig{
+			DirectoryPath:  "migrations",
+			VersionStyle:   configloader.VersionStyleTimestamp,
+			Layout:         configloader.MigrationLayoutSingle,
+			SchemaFilePath: "schema.sql",
+		},
+		Connection: configloader.ConnectionConfig{
+			Driver:          "sqlite3",
+			DBName:          "cache.db",
+			MigrationsTable: "bolt_migrations",
+		},
+	})
+}
+
+func TestNewConfigWithInvalidTarget(t *testing.T) {
+	tmpdir := t.TempDir()
+	bolttest.ChangeCwd(t, tmpdir)
+	err := os.WriteFile(filepath.Join(tmpdir, "bolt.toml"), []byte(`
+[targets.main.migrations]
+version_style = "invalid"
+`), 0644)
+	assert.Nil(t, err)
+
+	_, err = configloader.NewConfig()
+	assert.ErrorIs(t, err, configloader.ErrInvalidVersionStyle)
+	assert.ErrorContains(t, err, "target main")
+}
+
+func TestNewConfigWithReservedTargetName(t *testing.T) {
+	tmpdir := t.TempDir()
+	bolttest.ChangeCwd(t, tmpdir)
+	err := os.WriteFile(filepath.Join(tmpdir, "bolt.toml"), []byte(`
+[targets.all.database]
+driver = "sqlite3"
+`), 0644)
+	assert.Nil(t, err)
+
+	_, err = configloader.NewConfig()
+	assert.ErrorIs(t, err, configloader.ErrInvalidTargetName)
+}
+
+func TestConfigTarget(t *testing.T) {
+	cfg := configloader.Config{
+		Migrations: configloader.MigrationsConfig{DirectoryPath: "migrations"},
+		Connection: configloader.ConnectionConfig{Driver: "postgresql"},
+		Tenants:    configloader.TenantsConfig{Parallelism: 2},
+		Targets: map[string]configloader.TargetConfig{
+			"cache": {
+				Migrations: configloader.MigrationsConfig{DirectoryPath: "migrations/cache"},
+				Connection: configloader.ConnectionConfig{Driver: "sqlite3"},
+			},
+		},
+	}
+
+	targetCfg, err := cfg.Target("cache")
+	assert.Nil(t, err)
+	check.Equal(t, targetCfg.Migrations.DirectoryPath, "migrations/cache")
+	check.Equal(t, targetCfg.Connection.Driver, "sqlite3")
+	check.Equal(t, targetCfg.Tenants.Parallelism, 2)
+	check.Equal(t, cfg.Connection.Driver, "postgresql")
+
+	_, err = cfg.Target("main")
+	assert.ErrorIs(t, err, configloader.ErrTargetNotFound)
+	assert.ErrorContains(t, err, "target not found: main, configured targets: [cache]")
+}
+
+func TestEnvironmentNonProduction(t *testing.T) {
+	check.True(t, configloader.EnvironmentDevelopment.NonProduction())
+	check.True(t, configloader.EnvironmentTest.NonProduction())
+	check.False(t, configloader.EnvironmentProduction.NonProduction())
+	check.False(t, configloader.Environment("").NonProduction())
+}
+
+func TestConnectionConfigIsProtected(t *testing.T) {
+	type test struct {
+		cfg       configloader.ConnectionConfig
+		protected bool
+	}
+
+	tests := []test{
+		{cfg: configloader.ConnectionConfig{DBName: "myapp"}, protected: false},
+		{cfg: configloader.ConnectionConfig{DBName: "myapp", Protected: true}, protected: true},
+		{
+			cfg: configloader.ConnectionConfig{
+				DBName:      "myapp",
+				Environment: configloader.EnvironmentProduction,
+			},
+			protected: true,
+		},
+		{cfg: configloader.ConnectionConfig{DBName: "myapp_prod"}, protected: true},
+		{cfg: configloader.ConnectionConfig{Host: "db.production.internal"}, protected: true},
+		{cfg: configloader.ConnectionConfig{DBName: "products"}, protected: false},
+		{
+			cfg: configloader.ConnectionConfig{
+				DBName:      "myapp_prod",
+				Environment: configloader.EnvironmentDevelopment,
+			},
+			protected: false,
+		},
+		{
+			cfg: configloader.ConnectionConfig{
+				DBName:      "myapp",
+				Environment: configloader.EnvironmentTest,
+				Protected:   true,
+			},
+			protected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		check.Equal(t, tc.cfg.IsProtected(), tc.protected)
+	}
+}
+
 func TestNewConfigFindsFileAndPopulatesConfigStruct(t *testing.T) {
 	bolttest.UnsetEnv(t, "BOLT_DB_HOST")
 	bolttest.UnsetEnv(t, "BOLT_DB_PORT")
@@ -45,19 +277,44 @@ func TestNewConfigFindsFileAndPopulatesConfigStruct(t *testing.T) {
 	bolttest.UnsetEnv(t, "BOLT_DB_MIGRATIONS_TABLE")
 	bolttest.UnsetEnv(t, "BOLT_MIGRATIONS_DIR_PATH")
 	bolttest.UnsetEnv(t, "BOLT_MIGRATIONS_VERSION_STYLE")
+	bolttest.UnsetEnv(t, "BOLT_MIGRATIONS_LAYOUT")
+	bolttest.UnsetEnv(t, "BOLT_DB_ENVIRONMENT")
+	bolttest.UnsetEnv(t, "BOLT_DB_PROTECTED")
+	bolttest.UnsetEnv(t, "BOLT_DB_SCHEMA")
+	bolttest.UnsetEnv(t, "BOLT_DB_CREATE_SCHEMA")
+	bolttest.UnsetEnv(t, "BOLT_DB_CONNECT_TIMEOUT")
+	bolttest.UnsetEnv(t, "BOLT_DB_CONNECT_RETRIES")
+	bolttest.UnsetEnv(t, "BOLT_DB_STATEMENT_RETRIES")
+	bolttest.UnsetEnv(t, "BOLT_TENANTS_QUERY")
+	bolttest.UnsetEnv(t, "BOLT_TENANTS_LIST")
+	bolttest.UnsetEnv(t, "BOLT_TENANTS_TARGET")
+	bolttest.UnsetEnv(t, "BOLT_TENANTS_PARALLELISM")
 	expectedCfg := configloader.Config{
 		Migrations: configloader.MigrationsConfig{
 			DirectoryPath: "myfancymigrations",
 			VersionStyle:  configloader.VersionStyleSequential,
+			Layout:        configloader.MigrationLayoutSplit,
 		},
 		Connection: configloader.ConnectionConfig{
-			Host:            "testhost",
-			Port:            "1234",
-			User:            "testuser",
-			Password:        "testpassword",
-			DBName:          "testdb",
-			Driver:          "postgresql",
-			MigrationsTable: "test_table",
+			Host:             "testhost",
+			Port:             "1234",
+			User:             "testuser",
+			Password:         "testpassword",
+			DBName:           "testdb",
+			Driver:           "postgresql",
+			MigrationsTable:  "test_table",
+			Environment:      configloader.EnvironmentProduction,
+			Schema:           "testschema",
+			CreateSchema:     true,
+			ConnectTimeout:   30 * time.Second,
+			ConnectRetries:   5,
+			StatementRetries: 3,
+		},
+		Tenants: configloader.TenantsConfig{
+			Query:       "SELECT name FROM tenants",
+			List:        []string{"acme", "globex"},
+			Target:      configloader.TenantTargetDatabase,
+			Parallelism: 4,
 		},
 	}
 	tmpdir := t.TempDir()
@@ -75,6 +332,7 @@ func TestNewConfigCanBeOverridenByEnvVars(t *testing.T) {
 		Migrations: configloader.MigrationsConfig{
 			DirectoryPath: "cfgmigrations",
 			VersionStyle:  configloader.VersionStyleSequential,
+			Layout:        configloader.MigrationLayoutSplit,
 		},
 		Connection: configloader.ConnectionConfig{
 			Host:            "testhost",
@@ -85,6 +343,11 @@ func TestNewConfigCanBeOverridenByEnvVars(t *testing.T) {
 			Driver:          "mysql",
 			MigrationsTable: "test_table",
 		},
+		Tenants: configloader.TenantsConfig{
+			List:        []string{"acme"},
+			Target:      configloader.TenantTargetSchema,
+			Parallelism: 1,
+		},
 	}
 	bolttest.CreateConfigFile(t, &fileCfg, "bolt.toml")
 
@@ -92,19 +355,33 @@ func TestNewConfigCanBeOverridenByEnvVars(t *testing.T) {
 		Migrations: configloader.MigrationsConfig{
 			DirectoryPath: "envmigrations",
 			VersionStyle:  configloader.VersionStyleTimestamp,
+			Layout:        configloader.MigrationLayoutDirectory,
 		},
 		Connection: configloader.ConnectionConfig{
-			Host:            "envtesthost",
-			Port:            "4321",
-			User:            "envtestuser",
-			Password:        "envtestpassword",
-			DBName:          "envtestdb",
-			Driver:          "postgresql",
-			MigrationsTable: "different_table",
+			Host:             "envtesthost",
+			Port:             "4321",
+			User:             "envtestuser",
+			Password:         "envtestpassword",
+			DBName:           "envtestdb",
+			Driver:           "postgresql",
+			MigrationsTable:  "different_table",
+			Environment:      configloader.EnvironmentDevelopment,
+			Protected:        true,
+			Schema:           "envschema",
+			ConnectTimeout:   time.Minute,
+			ConnectRetries:   10,
+			StatementRetries: 2,
+		},
+		Tenants: configloader.TenantsConfig{
+			Query:       "SELECT name FROM tenants",
+			List:        []string{"initech", "umbrella"},
+			Target:      configloader.TenantTargetDatabase,
+			Parallelism: 8,
 		},
 	}
 	t.Setenv("BOLT_MIGRATIONS_VERSION_STYLE", string(envCfg.Migrations.VersionStyle))
 	t.Setenv("BOLT_MIGRATIONS_DIR_PATH", envCfg.Migrations.DirectoryPath)
+	t.Setenv("BOLT_MIGRATIONS_LAYOUT", string(envCfg.Migrations.Layout))
 	t.Setenv("BOLT_DB_HOST", envCfg.Connection.Host)
 	t.Setenv("BOLT_DB_PORT", envCfg.Connection.Port)
 	t.Setenv("BOLT_DB_USER", envCfg.Connection.User)
@@ -112,6 +389,16 @@ func TestNewConfigCanBeOverridenByEnvVars(t *testing.T) {
 	t.Setenv("BOLT_DB_NAME", envCfg.Connection.DBName)
 	t.Setenv("BOLT_DB_DRIVER", envCfg.Connection.Driver)
 	t.Setenv("BOLT_DB_MIGRATIONS_TABLE", envCfg.Connection.MigrationsTable)
+	t.Setenv("BOLT_DB_ENVIRONMENT", string(envCfg.Connection.Environment))
+	t.Setenv("BOLT_DB_PROTECTED", "true")
+	t.Setenv("BOLT_DB_SCHEMA", envCfg.Connection.Schema)
+	t.Setenv("BOLT_DB_CONNECT_TIMEOUT", "1m")
+	t.Setenv("BOLT_DB_CONNECT_RETRIES", "10")
+	t.Setenv("BOLT_DB_STATEMENT_RETRIES", "2")
+	t.Setenv("BOLT_TENANTS_QUERY", envCfg.Tenants.Query)
+	t.Setenv("BOLT_TENANTS_LIST", "initech,umbrella")
+	t.Setenv("BOLT_TENANTS_TARGET", string(envCfg.Tenants.Target))
+	t.Setenv("BOLT_TENANTS_PARALLELISM", "8")
 
 	cfg, err := configloader.NewConfig()
 	assert.Nil(t, err)
@@ -128,14 +415,20 @@ func TestNewConfigSearchesParentDirectories(t *testing.T) {
 	bolttest.UnsetEnv(t, "BOLT_DB_MIGRATIONS_TABLE")
 	bolttest.UnsetEnv(t, "BOLT_MIGRATIONS_DIR_PATH")
 	bolttest.UnsetEnv(t, "BOLT_MIGRATIONS_VERSION_STYLE")
+	bolttest.UnsetEnv(t, "BOLT_MIGRATIONS_LAYOUT")
 	expectedCfg := configloader.Config{
 		Migrations: configloader.MigrationsConfig{
 			DirectoryPath: "differentmigrationsdir",
 			VersionStyle:  configloader.VersionStyleSequential,
+			Layout:        configloader.MigrationLayoutSingle,
 		},
 		Connection: configloader.ConnectionConfig{
 			MigrationsTable: "migration_table",
 		},
+		Tenants: configloader.TenantsConfig{
+			Target:      configloader.TenantTargetSchema,
+			Parallelism: 1,
+		},
 	}
 	tmpdir := t.TempDir()
 	bolttest.CreateConfigFile(t, &expectedCfg, filepath.Join(tmpdir, "bolt.toml"))
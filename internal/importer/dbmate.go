@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+// dbmateSource imports from dbmate, whose migration files already
+// use the same format as bolt's single file layout and which keeps
+// one row per applied version in its table.
+type dbmateSource struct {
+	tableName string
+}
+
+func (s dbmateSource) Migrations(dirPath string) ([]Migration, error) {
+	return readBoltCompatibleMigrations(dirPath)
+}
+
+func (s dbmateSource) AppliedVersions(
+	db storage.DB,
+	migrations []Migration,
+) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT version FROM %s", s.tableName))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to read applied versions from %s: %w",
+			s.tableName,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	versions := make([]string, 0)
+	for rows.Next() {
+		var version string
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan applied version: %w", err)
+		}
+
+		normalizedVersion, err := normalizeVersion(version)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, normalizedVersion)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read applied versions: %w", err)
+	}
+
+	sortVersions(versions)
+	return versions, nil
+}
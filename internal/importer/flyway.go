@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eugenetriguba/bolt/internal/sqlparse"
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+const (
+	flywayVersionedPrefix  = "V"
+	flywayUndoPrefix       = "U"
+	flywayRepeatablePrefix = "R"
+	flywaySeparator        = "__"
+	flywayMigrationExt     = ".sql"
+)
+
+// flywaySource imports from Flyway, which uses V<version>__<description>.sql
+// files for upgrades, optional U<version>__<description>.sql files for
+// downgrades, and keeps a log of every change in its table.
+type flywaySource struct {
+	tableName string
+}
+
+func (s flywaySource) Migrations(dirPath string) ([]Migration, error) {
+	err := checkIsDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make(map[string]*Migration)
+	undoScripts := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != flywayMigrationExt {
+			continue
+		}
+		if strings.HasPrefix(name, flywayRepeatablePrefix+flywaySeparator) {
+			return nil, fmt.Errorf(
+				"%s can't be imported: flyway repeatable migrations aren't supported",
+				name,
+			)
+		}
+
+		prefix := name[:1]
+		if prefix != flywayVersionedPrefix && prefix != flywayUndoPrefix {
+			continue
+		}
+		parts := strings.SplitN(
+			strings.TrimSuffix(name[1:], flywayMigrationExt),
+			flywaySeparator,
+			2,
+		)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf(
+				"%s is an invalid flyway migration name: expected the "+
+					"format %s<version>__<description>.sql",
+				name,
+				prefix,
+			)
+		}
+		version, err := normalizeVersion(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := os.ReadFile(filepath.Join(dirPath, name))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", name, err)
+		}
+
+		if prefix == flywayUndoPrefix {
+			undoScripts[version] = string(contents)
+			continue
+		}
+		migrations[version] = &Migration{
+			Version:         version,
+			Message:         parts[1],
+			UpgradeScript:   sqlparse.NewMigrationScript(string(contents)),
+			DowngradeScript: sqlparse.NewMigrationScript(""),
+		}
+	}
+
+	for version, contents := range undoScripts {
+		migration, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf(
+				"undo migration for version %s has no versioned migration",
+				version,
+			)
+		}
+		migration.DowngradeScript = sqlparse.NewMigrationScript(contents)
+	}
+
+	sortedMigrations := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		sortedMigrations = append(sortedMigrations, *migration)
+	}
+	sortMigrations(sortedMigrations)
+	return sortedMigrations, nil
+}
+
+func (s flywaySource) AppliedVersions(
+	db storage.DB,
+	migrations []Migration,
+) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf(
+		"SELECT version, type, success FROM %s ORDER BY installed_rank",
+		s.tableName,
+	))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to read applied versions from %s: %w",
+			s.tableName,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var rawVersion sql.NullString
+		var migrationType string
+		var success bool
+		err = rows.Scan(&rawVersion, &migrationType, &success)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan applied version: %w", err)
+		}
+		// Repeatable migrations have no version and schema
+		// creation markers aren't migrations.
+		if !rawVersion.Valid || migrationType == "SCHEMA" {
+			continue
+		}
+
+		version, err := normalizeVersion(rawVersion.String)
+		if err != nil {
+			return nil, err
+		}
+		if !success {
+			return nil, fmt.Errorf(
+				"flyway recorded migration %s as failed, repair it "+
+					"with flyway before importing",
+				rawVersion.String,
+			)
+		}
+
+		switch migrationType {
+		case "BASELINE":
+			// Everything up to and including a baseline is
+			// considered applied by flyway.
+			baseline, err := strconv.ParseUint(version, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			for _, migration := range migrations {
+				migrationVersion, err := strconv.ParseUint(migration.Version, 10, 64)
+				if err != nil {
+					return nil, err
+				}
+				if migrationVersion <= baseline {
+					applied[migration.Version] = true
+				}
+			}
+		case "UNDO_SQL", "UNDO_JDBC", "DELETE":
+			applied[version] = false
+		default:
+			applied[version] = true
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read applied versions: %w", err)
+	}
+
+	versions := make([]string, 0)
+	for version, isApplied := range applied {
+		if isApplied {
+			versions = append(versions, version)
+		}
+	}
+	sortVersions(versions)
+	return versions, nil
+}
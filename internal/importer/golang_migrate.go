@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+// golangMigrateSource imports from golang-migrate, which uses
+// <version>_<title>.up.sql and <version>_<title>.down.sql files
+// and keeps a single row with the current version in its table.
+type golangMigrateSource struct {
+	tableName string
+}
+
+func (s golangMigrateSource) Migrations(dirPath string) ([]Migration, error) {
+	return readBoltCompatibleMigrations(dirPath)
+}
+
+func (s golangMigrateSource) AppliedVersions(
+	db storage.DB,
+	migrations []Migration,
+) ([]string, error) {
+	var currentVersion int64
+	var dirty bool
+	err := db.QueryRow(
+		fmt.Sprintf("SELECT version, dirty FROM %s", s.tableName),
+	).Scan(&currentVersion, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to read the current version from %s: %w",
+			s.tableName,
+			err,
+		)
+	}
+	if dirty {
+		return nil, fmt.Errorf(
+			"golang-migrate recorded version %d as dirty, resolve it "+
+				"with golang-migrate before importing",
+			currentVersion,
+		)
+	}
+
+	// golang-migrate only records the latest version, so every
+	// migration up to and including it is applied.
+	versions := make([]string, 0)
+	for _, migration := range migrations {
+		version, err := strconv.ParseInt(migration.Version, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if version <= currentVersion {
+			versions = append(versions, migration.Version)
+		}
+	}
+	return versions, nil
+}
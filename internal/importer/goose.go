@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eugenetriguba/bolt/internal/sqlparse"
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+const (
+	gooseUpAnnotation            = "-- +goose up"
+	gooseDownAnnotation          = "-- +goose down"
+	gooseNoTransactionAnnotation = "-- +goose no transaction"
+	gooseAnnotationPrefix        = "-- +goose "
+	gooseInitialVersion          = 0
+	gooseMigrationExt            = ".sql"
+	gooseGoMigrationExt          = ".go"
+)
+
+// gooseSource imports from goose, which uses <version>_<name>.sql
+// files with '-- +goose Up' and '-- +goose Down' annotations and
+// keeps a log of every apply and revert in its table.
+type gooseSource struct {
+	tableName string
+}
+
+func (s gooseSource) Migrations(dirPath string) ([]Migration, error) {
+	err := checkIsDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if filepath.Ext(entry.Name()) == gooseGoMigrationExt {
+			return nil, fmt.Errorf(
+				"%s can't be imported: goose Go migrations aren't supported",
+				entry.Name(),
+			)
+		}
+		if filepath.Ext(entry.Name()) != gooseMigrationExt {
+			continue
+		}
+
+		migration, err := s.readMigration(filepath.Join(dirPath, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration)
+	}
+
+	sortMigrations(migrations)
+	return migrations, nil
+}
+
+func (s gooseSource) readMigration(path string) (Migration, error) {
+	name := strings.TrimSuffix(filepath.Base(path), gooseMigrationExt)
+	parts := strings.SplitN(name, "_", 2)
+	if len(parts) != 2 {
+		return Migration{}, fmt.Errorf(
+			"%s is an invalid goose migration name: expected the "+
+				"format <version>_<name>.sql",
+			filepath.Base(path),
+		)
+	}
+	version, err := normalizeVersion(parts[0])
+	if err != nil {
+		return Migration{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return Migration{}, fmt.Errorf("unable to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	upgradeScript := sqlparse.NewMigrationScript("")
+	downgradeScript := sqlparse.NewMigrationScript("")
+	var currentScript *sqlparse.MigrationScript
+	hasUpAnnotation := false
+	useTransaction := true
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		annotation := strings.ToLower(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(annotation, gooseUpAnnotation):
+			currentScript = &upgradeScript
+			hasUpAnnotation = true
+		case strings.HasPrefix(annotation, gooseDownAnnotation):
+			currentScript = &downgradeScript
+		case strings.HasPrefix(annotation, gooseNoTransactionAnnotation):
+			useTransaction = false
+		case strings.HasPrefix(annotation, gooseAnnotationPrefix):
+			// Statement and environment substitution annotations
+			// have no equivalent since bolt executes the whole
+			// script as-is, so they're dropped.
+		case currentScript != nil:
+			currentScript.Contents += line + "\n"
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return Migration{}, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	if !hasUpAnnotation {
+		return Migration{}, fmt.Errorf(
+			"%s is missing a '-- +goose Up' annotation",
+			filepath.Base(path),
+		)
+	}
+
+	upgradeScript.Options.UseTransaction = useTransaction
+	downgradeScript.Options.UseTransaction = useTransaction
+	return Migration{
+		Version:         version,
+		Message:         parts[1],
+		UpgradeScript:   upgradeScript,
+		DowngradeScript: downgradeScript,
+	}, nil
+}
+
+func (s gooseSource) AppliedVersions(
+	db storage.DB,
+	migrations []Migration,
+) ([]string, error) {
+	rows, err := db.Query(
+		fmt.Sprintf("SELECT version_id, is_applied FROM %s ORDER BY id", s.tableName),
+	)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to read applied versions from %s: %w",
+			s.tableName,
+			err,
+		)
+	}
+	defer rows.Close()
+
+	// goose logs every apply and revert, so the latest row for
+	// a version is the one that says whether it is applied.
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var versionID int64
+		var isApplied bool
+		err = rows.Scan(&versionID, &isApplied)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan applied version: %w", err)
+		}
+		if versionID == gooseInitialVersion {
+			continue
+		}
+
+		version, err := normalizeVersion(strconv.FormatInt(versionID, 10))
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = isApplied
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read applied versions: %w", err)
+	}
+
+	versions := make([]string, 0)
+	for version, isApplied := range applied {
+		if isApplied {
+			versions = append(versions, version)
+		}
+	}
+	sortVersions(versions)
+	return versions, nil
+}
@@ -0,0 +1,159 @@
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/bolttest"
+	"github.com/eugenetriguba/bolt/internal/importer"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func newHistoryTable(t *testing.T, db storage.DB, tableName string, statements ...string) {
+	bolttest.DropTable(t, db, tableName)
+	t.Cleanup(func() {
+		bolttest.DropTable(t, db, tableName)
+	})
+
+	for _, statement := range statements {
+		_, err := db.Exec(statement)
+		assert.Nil(t, err)
+	}
+}
+
+func importedMigrations(versions ...string) []importer.Migration {
+	migrations := make([]importer.Migration, 0, len(versions))
+	for _, version := range versions {
+		migrations = append(migrations, importer.Migration{Version: version})
+	}
+	return migrations
+}
+
+func TestAppliedVersions_GolangMigrate(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	newHistoryTable(
+		t,
+		db,
+		"schema_migrations",
+		"CREATE TABLE schema_migrations(version BIGINT NOT NULL, dirty INT NOT NULL)",
+		"INSERT INTO schema_migrations(version, dirty) VALUES (2, 0)",
+	)
+
+	versions, err := newSource(t, importer.ToolGolangMigrate).AppliedVersions(
+		db,
+		importedMigrations("001", "002", "003"),
+	)
+
+	assert.Nil(t, err)
+	check.DeepEqual(t, versions, []string{"001", "002"})
+}
+
+func TestAppliedVersions_GolangMigrateDirty(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	newHistoryTable(
+		t,
+		db,
+		"schema_migrations",
+		"CREATE TABLE schema_migrations(version BIGINT NOT NULL, dirty INT NOT NULL)",
+		"INSERT INTO schema_migrations(version, dirty) VALUES (2, 1)",
+	)
+
+	_, err := newSource(t, importer.ToolGolangMigrate).AppliedVersions(
+		db,
+		importedMigrations("001", "002"),
+	)
+
+	check.NotNil(t, err)
+}
+
+func TestAppliedVersions_Dbmate(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	newHistoryTable(
+		t,
+		db,
+		"schema_migrations",
+		"CREATE TABLE schema_migrations(version VARCHAR(128) NOT NULL)",
+		"INSERT INTO schema_migrations(version) VALUES ('20240201000000')",
+		"INSERT INTO schema_migrations(version) VALUES ('20240101000000')",
+	)
+
+	versions, err := newSource(t, importer.ToolDbmate).AppliedVersions(
+		db,
+		importedMigrations("20240101000000", "20240201000000", "20240301000000"),
+	)
+
+	assert.Nil(t, err)
+	check.DeepEqual(t, versions, []string{"20240101000000", "20240201000000"})
+}
+
+func TestAppliedVersions_Goose(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	newHistoryTable(
+		t,
+		db,
+		"goose_db_version",
+		"CREATE TABLE goose_db_version(id INT NOT NULL, version_id BIGINT NOT NULL, is_applied INT NOT NULL)",
+		"INSERT INTO goose_db_version(id, version_id, is_applied) VALUES (1, 0, 1)",
+		"INSERT INTO goose_db_version(id, version_id, is_applied) VALUES (2, 1, 1)",
+		"INSERT INTO goose_db_version(id, version_id, is_applied) VALUES (3, 2, 1)",
+		"INSERT INTO goose_db_version(id, version_id, is_applied) VALUES (4, 2, 0)",
+	)
+
+	versions, err := newSource(t, importer.ToolGoose).AppliedVersions(
+		db,
+		importedMigrations("001", "002"),
+	)
+
+	assert.Nil(t, err)
+	check.DeepEqual(t, versions, []string{"001"})
+}
+
+func TestAppliedVersions_Flyway(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	newHistoryTable(
+		t,
+		db,
+		"flyway_schema_history",
+		"CREATE TABLE flyway_schema_history(installed_rank INT NOT NULL, "+
+			"version VARCHAR(50), type VARCHAR(20) NOT NULL, success INT NOT NULL)",
+		"INSERT INTO flyway_schema_history(installed_rank, version, type, success) "+
+			"VALUES (1, '2', 'BASELINE', 1)",
+		"INSERT INTO flyway_schema_history(installed_rank, version, type, success) "+
+			"VALUES (2, '3', 'SQL', 1)",
+		"INSERT INTO flyway_schema_history(installed_rank, version, type, success) "+
+			"VALUES (3, NULL, 'SQL', 1)",
+		"INSERT INTO flyway_schema_history(installed_rank, version, type, success) "+
+			"VALUES (4, '4', 'SQL', 1)",
+		"INSERT INTO flyway_schema_history(installed_rank, version, type, success) "+
+			"VALUES (5, '4', 'UNDO_SQL', 1)",
+	)
+
+	versions, err := newSource(t, importer.ToolFlyway).AppliedVersions(
+		db,
+		importedMigrations("001", "002", "003", "004"),
+	)
+
+	assert.Nil(t, err)
+	check.DeepEqual(t, versions, []string{"001", "002", "003"})
+}
+
+func TestAppliedVersions_FlywayFailedMigration(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	newHistoryTable(
+		t,
+		db,
+		"flyway_schema_history",
+		"CREATE TABLE flyway_schema_history(installed_rank INT NOT NULL, "+
+			"version VARCHAR(50), type VARCHAR(20) NOT NULL, success INT NOT NULL)",
+		"INSERT INTO flyway_schema_history(installed_rank, version, type, success) "+
+			"VALUES (1, '1', 'SQL', 0)",
+	)
+
+	_, err := newSource(t, importer.ToolFlyway).AppliedVersions(
+		db,
+		importedMigrations("001"),
+	)
+
+	check.NotNil(t, err)
+}
@@ -0,0 +1,180 @@
+// Package importer reads the migration files and applied migration
+// history of other migration tools so that they can be brought over
+// to bolt without re-running anything.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/sqlparse"
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+const (
+	ToolGolangMigrate = "golang-migrate"
+	ToolDbmate        = "dbmate"
+	ToolGoose         = "goose"
+	ToolFlyway        = "flyway"
+)
+
+var ErrUnsupportedTool = fmt.Errorf(
+	"unsupported tool, supported tools are %s",
+	[]string{ToolGolangMigrate, ToolDbmate, ToolGoose, ToolFlyway},
+)
+
+// Migration is a migration from another tool that has been
+// converted into bolt's format.
+type Migration struct {
+	Version         string
+	Message         string
+	UpgradeScript   sqlparse.MigrationScript
+	DowngradeScript sqlparse.MigrationScript
+}
+
+type Source interface {
+	// Migrations reads the tool's migration files in dirPath
+	// and converts them into bolt's format. The migrations are
+	// sorted by version.
+	Migrations(dirPath string) ([]Migration, error)
+	// AppliedVersions reads the tool's history table and returns
+	// the bolt versions of the migrations it considers applied.
+	AppliedVersions(db storage.DB, migrations []Migration) ([]string, error)
+}
+
+// NewSource creates the Source for the tool. The tableName is the
+// history table the tool uses, and if it is empty, the tool's
+// default table name is used.
+func NewSource(tool string, tableName string) (Source, error) {
+	defaultTableNames := map[string]string{
+		ToolGolangMigrate: "schema_migrations",
+		ToolDbmate:        "schema_migrations",
+		ToolGoose:         "goose_db_version",
+		ToolFlyway:        "flyway_schema_history",
+	}
+	defaultTableName, ok := defaultTableNames[tool]
+	if !ok {
+		return nil, ErrUnsupportedTool
+	}
+
+	if tableName == "" {
+		tableName = defaultTableName
+	}
+	err := repositories.ValidateTableName(tableName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid history table name: %w", err)
+	}
+
+	switch tool {
+	case ToolGolangMigrate:
+		return golangMigrateSource{tableName: tableName}, nil
+	case ToolDbmate:
+		return dbmateSource{tableName: tableName}, nil
+	case ToolGoose:
+		return gooseSource{tableName: tableName}, nil
+	default:
+		return flywaySource{tableName: tableName}, nil
+	}
+}
+
+// normalizeVersion converts another tool's numeric version into
+// the version bolt would have created for it.
+func normalizeVersion(version string) (string, error) {
+	number, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf(
+			"%s can't be imported: only whole number versions are supported",
+			version,
+		)
+	}
+	return models.NewSequentialMigration(number, "").Version, nil
+}
+
+func checkIsDir(dirPath string) error {
+	fileInfo, err := os.Stat(dirPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("migrations directory %s does not exist", dirPath)
+	} else if err != nil {
+		return fmt.Errorf("unable to check migrations directory %s: %w", dirPath, err)
+	} else if !fileInfo.IsDir() {
+		return fmt.Errorf("migrations directory %s is not a directory", dirPath)
+	}
+	return nil
+}
+
+// readBoltCompatibleMigrations reads migrations that are already in
+// one of the layouts bolt supports.
+func readBoltCompatibleMigrations(dirPath string) ([]Migration, error) {
+	err := checkIsDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fsRepo, err := repositories.NewMigrationFsRepo(
+		&configloader.MigrationsConfig{DirectoryPath: dirPath},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	localMigrations, err := fsRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(localMigrations))
+	for _, localMigration := range localMigrations {
+		upgradeScript, err := fsRepo.ReadUpgradeScript(localMigration)
+		if err != nil {
+			return nil, err
+		}
+		downgradeScript, err := fsRepo.ReadDowngradeScript(localMigration)
+		if err != nil {
+			return nil, err
+		}
+
+		version, err := normalizeVersion(localMigration.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version:         version,
+			Message:         localMigration.Message,
+			UpgradeScript:   upgradeScript,
+			DowngradeScript: downgradeScript,
+		})
+	}
+
+	sortMigrations(migrations)
+	return migrations, nil
+}
+
+func sortMigrations(migrations []Migration) {
+	sort.Slice(migrations, func(i, j int) bool {
+		return versionLess(migrations[i].Version, migrations[j].Version)
+	})
+}
+
+func sortVersions(versions []string) {
+	sort.Slice(versions, func(i, j int) bool {
+		return versionLess(versions[i], versions[j])
+	})
+}
+
+// versionLess compares two normalized versions. Normalized versions
+// are zero-padded to the same minimum length, so a longer version is
+// always a larger one.
+func versionLess(v1 string, v2 string) bool {
+	if len(v1) != len(v2) {
+		return len(v1) < len(v2)
+	}
+	return v1 < v2
+}
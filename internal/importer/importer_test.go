@@ -0,0 +1,186 @@
+package importer_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/importer"
+	"github.com/eugenetriguba/bolt/internal/sqlparse"
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		assert.Nil(t, err)
+	}
+	return dir
+}
+
+func newSource(t *testing.T, tool string) importer.Source {
+	source, err := importer.NewSource(tool, "")
+	assert.Nil(t, err)
+	return source
+}
+
+func TestNewSource_UnsupportedTool(t *testing.T) {
+	_, err := importer.NewSource("liquibase", "")
+	check.ErrorIs(t, err, importer.ErrUnsupportedTool)
+}
+
+func TestNewSource_InvalidTableName(t *testing.T) {
+	_, err := importer.NewSource(importer.ToolGoose, "goose; DROP TABLE users")
+	check.NotNil(t, err)
+}
+
+func TestMigrations_DirectoryDoesNotExist(t *testing.T) {
+	source := newSource(t, importer.ToolGoose)
+	_, err := source.Migrations(filepath.Join(t.TempDir(), "missing"))
+	check.NotNil(t, err)
+}
+
+func TestMigrations_GolangMigrate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"1_create_users.up.sql":   "CREATE TABLE users(id INT);\n",
+		"1_create_users.down.sql": "DROP TABLE users;\n",
+		"2_add_index.up.sql":      "CREATE INDEX idx ON users(id);\n",
+	})
+
+	migrations, err := newSource(t, importer.ToolGolangMigrate).Migrations(dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 2)
+	check.Equal(t, migrations[0].Version, "001")
+	check.Equal(t, migrations[0].Message, "create_users")
+	check.Equal(t, migrations[0].UpgradeScript.Contents, "CREATE TABLE users(id INT);\n")
+	check.Equal(t, migrations[0].DowngradeScript.Contents, "DROP TABLE users;\n")
+	check.Equal(t, migrations[1].Version, "002")
+	check.Equal(t, migrations[1].DowngradeScript.Contents, "")
+}
+
+func TestMigrations_Dbmate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"20240101120000_create_users.sql": "-- migrate:up\n" +
+			"CREATE TABLE users(id INT);\n\n" +
+			"-- migrate:down transaction:false\n" +
+			"DROP TABLE users;\n",
+	})
+
+	migrations, err := newSource(t, importer.ToolDbmate).Migrations(dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 1)
+	check.Equal(t, migrations[0].Version, "20240101120000")
+	check.Equal(t, migrations[0].Message, "create_users")
+	check.Equal(t, migrations[0].UpgradeScript.Options.UseTransaction, true)
+	check.Equal(t, migrations[0].DowngradeScript.Options.UseTransaction, false)
+}
+
+func TestMigrations_Goose(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"00001_create_users.sql": "-- +goose Up\n" +
+			"-- +goose StatementBegin\n" +
+			"CREATE TABLE users(id INT);\n" +
+			"-- +goose StatementEnd\n" +
+			"-- +goose Down\n" +
+			"DROP TABLE users;\n",
+		"00002_add_index.sql": "-- +goose NO TRANSACTION\n" +
+			"-- +goose Up\n" +
+			"CREATE INDEX CONCURRENTLY idx ON users(id);\n",
+		"README.md": "not a migration",
+	})
+
+	migrations, err := newSource(t, importer.ToolGoose).Migrations(dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 2)
+	check.DeepEqual(t, migrations[0], importer.Migration{
+		Version: "001",
+		Message: "create_users",
+		UpgradeScript: sqlparse.MigrationScript{
+			Contents: "CREATE TABLE users(id INT);\n",
+			Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+		},
+		DowngradeScript: sqlparse.MigrationScript{
+			Contents: "DROP TABLE users;\n",
+			Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+		},
+	})
+	check.Equal(t, migrations[1].Version, "002")
+	check.Equal(t, migrations[1].UpgradeScript.Options.UseTransaction, false)
+	check.Equal(t, migrations[1].DowngradeScript.Contents, "")
+}
+
+func TestMigrations_GooseGoMigration(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"00001_create_users.go": "package migrations",
+	})
+
+	_, err := newSource(t, importer.ToolGoose).Migrations(dir)
+
+	check.NotNil(t, err)
+}
+
+func TestMigrations_GooseMissingUpAnnotation(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"00001_create_users.sql": "CREATE TABLE users(id INT);\n",
+	})
+
+	_, err := newSource(t, importer.ToolGoose).Migrations(dir)
+
+	check.NotNil(t, err)
+}
+
+func TestMigrations_GooseDownOnly(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"00001_create_users.sql": "-- +goose Down\n" +
+			"DROP TABLE users;\n",
+	})
+
+	_, err := newSource(t, importer.ToolGoose).Migrations(dir)
+
+	check.ErrorContains(t, err, "missing a '-- +goose Up' annotation")
+}
+
+func TestMigrations_Flyway(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"V1__create_users.sql": "CREATE TABLE users(id INT);\n",
+		"U1__create_users.sql": "DROP TABLE users;\n",
+		"V2__add_index.sql":    "CREATE INDEX idx ON users(id);\n",
+		"flyway.conf":          "flyway.url=jdbc:postgresql://localhost/db",
+	})
+
+	migrations, err := newSource(t, importer.ToolFlyway).Migrations(dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 2)
+	check.Equal(t, migrations[0].Version, "001")
+	check.Equal(t, migrations[0].Message, "create_users")
+	check.Equal(t, migrations[0].UpgradeScript.Contents, "CREATE TABLE users(id INT);\n")
+	check.Equal(t, migrations[0].DowngradeScript.Contents, "DROP TABLE users;\n")
+	check.Equal(t, migrations[1].Version, "002")
+	check.Equal(t, migrations[1].DowngradeScript.Contents, "")
+}
+
+func TestMigrations_FlywayRepeatableMigration(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"R__refresh_views.sql": "CREATE VIEW v AS SELECT 1;\n",
+	})
+
+	_, err := newSource(t, importer.ToolFlyway).Migrations(dir)
+
+	check.NotNil(t, err)
+}
+
+func TestMigrations_FlywayUndoWithoutVersionedMigration(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"U1__create_users.sql": "DROP TABLE users;\n",
+	})
+
+	_, err := newSource(t, importer.ToolFlyway).Migrations(dir)
+
+	check.NotNil(t, err)
+}
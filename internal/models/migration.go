@@ -1,6 +1,8 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +12,58 @@ type Migration struct {
 	Version string
 	Message string
 	Applied bool
+	// AppliedAt, ExecutionTime, and Checksum are recorded when
+	// the migration is applied. They're zero values for migrations
+	// that aren't applied and for migrations applied before they
+	// were recorded. ExecutionTime is nil rather than zero, since
+	// a fast migration can take less than the millisecond it is
+	// recorded to.
+	AppliedAt     time.Time
+	ExecutionTime *time.Duration
+	Checksum      string
+	// Dirty is set on a migration whose script was executed outside
+	// of a transaction and didn't finish, so the database may have
+	// been left partially changed.
+	Dirty bool
+}
+
+// MigrationState is the state of a migration when comparing the
+// local migrations against the ones applied to the database.
+type MigrationState string
+
+const (
+	MigrationStateApplied MigrationState = "applied"
+	MigrationStatePending MigrationState = "pending"
+	// MigrationStateMissingFile is an applied migration that
+	// doesn't exist locally anymore.
+	MigrationStateMissingFile MigrationState = "missing_file"
+	// MigrationStateOutOfOrder is a migration that isn't applied
+	// even though a later migration is.
+	MigrationStateOutOfOrder MigrationState = "out_of_order"
+	// MigrationStateChecksumMismatch is an applied migration whose
+	// upgrade script was changed after it was applied.
+	MigrationStateChecksumMismatch MigrationState = "checksum_mismatch"
+	// MigrationStateDirty is a migration whose script failed partway
+	// through outside of a transaction and needs to be resolved.
+	MigrationStateDirty MigrationState = "dirty"
+)
+
+// MigrationStates are all of the migration states in
+// the order they are reported in.
+var MigrationStates = []MigrationState{
+	MigrationStateApplied,
+	MigrationStatePending,
+	MigrationStateOutOfOrder,
+	MigrationStateMissingFile,
+	MigrationStateChecksumMismatch,
+	MigrationStateDirty,
+}
+
+// Checksum gives back the SHA-256 checksum of a migration
+// script's contents in hex.
+func Checksum(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
 }
 
 func NewTimestampMigration(version time.Time, message string) *Migration {
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Schema is the structure of a database that is compared
+// to find drift between two databases.
+type Schema struct {
+	Tables []Table
+}
+
+type Table struct {
+	Name    string
+	Columns []Column
+	// Indexes are the names of the indexes that don't
+	// back a constraint.
+	Indexes []string
+	// Constraints are the definitions of the table's primary key,
+	// unique, foreign key, and check constraints. They are compared
+	// by definition since their names are often generated.
+	Constraints []string
+}
+
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+}
+
+func (c Column) String() string {
+	nullability := "NOT NULL"
+	if c.Nullable {
+		nullability = "NULL"
+	}
+	return fmt.Sprintf("%s %s", c.Type, nullability)
+}
+
+type SchemaObjectKind string
+
+const (
+	SchemaObjectTable      SchemaObjectKind = "table"
+	SchemaObjectColumn     SchemaObjectKind = "column"
+	SchemaObjectIndex      SchemaObjectKind = "index"
+	SchemaObjectConstraint SchemaObjectKind = "constraint"
+)
+
+// SchemaDifference is a schema object that exists in one
+// schema but not the other, or that differs between them.
+type SchemaDifference struct {
+	Kind  SchemaObjectKind
+	Table string
+	// Name is the name of the schema object. For tables, it is
+	// the same as the Table and for constraints, it is the
+	// constraint's definition.
+	Name string
+	// Expected describes the object in the expected schema and is
+	// empty when the object only exists in the actual schema.
+	Expected string
+	// Actual describes the object in the actual schema and is
+	// empty when the object only exists in the expected schema.
+	Actual string
+}
+
+// DiffSchemas finds the tables, columns, indexes, and constraints
+// that differ between the expected and actual schemas. The
+// differences are sorted by table and then by kind and name.
+func DiffSchemas(expected Schema, actual Schema) []SchemaDifference {
+	differences := make([]SchemaDifference, 0)
+	expectedTables := tablesByName(expected)
+	actualTables := tablesByName(actual)
+
+	for name, expectedTable := range expectedTables {
+		actualTable, ok := actualTables[name]
+		if !ok {
+			differences = append(differences, SchemaDifference{
+				Kind:     SchemaObjectTable,
+				Table:    name,
+				Name:     name,
+				Expected: "exists",
+			})
+			continue
+		}
+		differences = append(differences, diffTables(expectedTable, actualTable)...)
+	}
+	for name := range actualTables {
+		if _, ok := expectedTables[name]; !ok {
+			differences = append(differences, SchemaDifference{
+				Kind:   SchemaObjectTable,
+				Table:  name,
+				Name:   name,
+				Actual: "exists",
+			})
+		}
+	}
+
+	kindOrder := map[SchemaObjectKind]int{
+		SchemaObjectTable:      0,
+		SchemaObjectColumn:     1,
+		SchemaObjectIndex:      2,
+		SchemaObjectConstraint: 3,
+	}
+	sort.Slice(differences, func(i, j int) bool {
+		if differences[i].Table != differences[j].Table {
+			return differences[i].Table < differences[j].Table
+		}
+		if differences[i].Kind != differences[j].Kind {
+			return kindOrder[differences[i].Kind] < kindOrder[differences[j].Kind]
+		}
+		return differences[i].Name < differences[j].Name
+	})
+	return differences
+}
+
+func tablesByName(schema Schema) map[string]Table {
+	tables := make(map[string]Table, len(schema.Tables))
+	for _, table := range schema.Tables {
+		tables[table.Name] = table
+	}
+	return tables
+}
+
+func diffTables(expected Table, actual Table) []SchemaDifference {
+	differences := make([]SchemaDifference, 0)
+
+	actualColumns := make(map[string]Column, len(actual.Columns))
+	for _, column := range actual.Columns {
+		actualColumns[column.Name] = column
+	}
+	expectedColumns := make(map[string]Column, len(expected.Columns))
+	for _, column := range expected.Columns {
+		expectedColumns[column.Name] = column
+
+		actualColumn, ok := actualColumns[column.Name]
+		if !ok {
+			differences = append(differences, SchemaDifference{
+				Kind:     SchemaObjectColumn,
+				Table:    expected.Name,
+				Name:     column.Name,
+				Expected: column.String(),
+			})
+		} else if actualColumn != column {
+			differences = append(differences, SchemaDifference{
+				Kind:     SchemaObjectColumn,
+				Table:    expected.Name,
+				Name:     column.Name,
+				Expected: column.String(),
+				Actual:   actualColumn.String(),
+			})
+		}
+	}
+	for _, column := range actual.Columns {
+		if _, ok := expectedColumns[column.Name]; !ok {
+			differences = append(differences, SchemaDifference{
+				Kind:   SchemaObjectColumn,
+				Table:  actual.Name,
+				Name:   column.Name,
+				Actual: column.String(),
+			})
+		}
+	}
+
+	differences = append(
+		differences,
+		diffNames(SchemaObjectIndex, expected.Name, expected.Indexes, actual.Indexes)...,
+	)
+	differences = append(
+		differences,
+		diffNames(SchemaObjectConstraint, expected.Name, expected.Constraints, actual.Constraints)...,
+	)
+	return differences
+}
+
+func diffNames(
+	kind SchemaObjectKind,
+	tableName string,
+	expected []string,
+	actual []string,
+) []SchemaDifference {
+	differences := make([]SchemaDifference, 0)
+	expectedNames := make(map[string]bool, len(expected))
+	for _, name := range expected {
+		expectedNames[name] = true
+	}
+	actualNames := make(map[string]bool, len(actual))
+	for _, name := range actual {
+		actualNames[name] = true
+	}
+
+	for _, name := range expected {
+		if !actualNames[name] {
+			differences = append(differences, SchemaDifference{
+				Kind:     kind,
+				Table:    tableName,
+				Name:     name,
+				Expected: "exists",
+			})
+		}
+	}
+	for _, name := range actual {
+		if !expectedNames[name] {
+			differences = append(differences, SchemaDifference{
+				Kind:   kind,
+				Table:  tableName,
+				Name:   name,
+				Actual: "exists",
+			})
+		}
+	}
+	return differences
+}
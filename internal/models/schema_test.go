@@ -0,0 +1,73 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func TestDiffSchemas_NoDifferences(t *testing.T) {
+	schema := models.Schema{
+		Tables: []models.Table{
+			{
+				Name:        "users",
+				Columns:     []models.Column{{Name: "id", Type: "int", Nullable: false}},
+				Indexes:     []string{"users_id_idx"},
+				Constraints: []string{"PRIMARY KEY (id)"},
+			},
+		},
+	}
+
+	differences := models.DiffSchemas(schema, schema)
+
+	check.Equal(t, len(differences), 0)
+}
+
+func TestDiffSchemas_FindsMissingAndExtraObjects(t *testing.T) {
+	expected := models.Schema{
+		Tables: []models.Table{
+			{
+				Name: "users",
+				Columns: []models.Column{
+					{Name: "id", Type: "int", Nullable: false},
+					{Name: "name", Type: "text", Nullable: false},
+				},
+				Indexes:     []string{"users_name_idx"},
+				Constraints: []string{"PRIMARY KEY (id)"},
+			},
+			{Name: "posts"},
+		},
+	}
+	actual := models.Schema{
+		Tables: []models.Table{
+			{
+				Name: "users",
+				Columns: []models.Column{
+					{Name: "id", Type: "int", Nullable: false},
+					{Name: "name", Type: "text", Nullable: true},
+					{Name: "hotfix", Type: "text", Nullable: true},
+				},
+				Constraints: []string{"PRIMARY KEY (id)", "UNIQUE (name)"},
+			},
+			{Name: "manual"},
+		},
+	}
+
+	differences := models.DiffSchemas(expected, actual)
+
+	check.DeepEqual(t, differences, []models.SchemaDifference{
+		{Kind: models.SchemaObjectTable, Table: "manual", Name: "manual", Actual: "exists"},
+		{Kind: models.SchemaObjectTable, Table: "posts", Name: "posts", Expected: "exists"},
+		{Kind: models.SchemaObjectColumn, Table: "users", Name: "hotfix", Actual: "text NULL"},
+		{
+			Kind:     models.SchemaObjectColumn,
+			Table:    "users",
+			Name:     "name",
+			Expected: "text NOT NULL",
+			Actual:   "text NULL",
+		},
+		{Kind: models.SchemaObjectIndex, Table: "users", Name: "users_name_idx", Expected: "exists"},
+		{Kind: models.SchemaObjectConstraint, Table: "users", Name: "UNIQUE (name)", Actual: "exists"},
+	})
+}
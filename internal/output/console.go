@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
+	"time"
 )
 
 type ConsoleOutputter struct {
@@ -24,6 +26,10 @@ func (c ConsoleOutputter) Output(message string) error {
 	return nil
 }
 
+func (c ConsoleOutputter) Result(message string) error {
+	return c.Output(message)
+}
+
 func (c ConsoleOutputter) Error(err error) error {
 	msg := err.Error()
 	_, printErr := fmt.Fprintln(c.stderr, msg)
@@ -65,3 +71,89 @@ func (c ConsoleOutputter) Table(headers []string, rows [][]string) error {
 	w.Flush()
 	return nil
 }
+
+func (c ConsoleOutputter) Event(event Event) error {
+	var message string
+	switch {
+	case event.Type == EventMigrationStarted && event.Direction == DirectionUp:
+		message = fmt.Sprintf("Applying migration %s..", event.Migration)
+	case event.Type == EventMigrationStarted && event.Direction == DirectionDown:
+		message = fmt.Sprintf("Reverting migration %s..", event.Migration)
+	case event.Type == EventMigrationFinished && event.Direction == DirectionUp:
+		message = fmt.Sprintf(
+			"Successfully applied migration %s in %s!",
+			event.Migration,
+			event.Duration,
+		)
+	case event.Type == EventMigrationFinished && event.Direction == DirectionDown:
+		message = fmt.Sprintf(
+			"Successfully reverted migration %s in %s!",
+			event.Migration,
+			event.Duration,
+		)
+	default:
+		// Failures are output by the command that
+		// ran the migration along with its context.
+		return nil
+	}
+	if event.Tenant != "" {
+		message = fmt.Sprintf("[%s] %s", event.Tenant, message)
+	}
+	if event.Target != "" {
+		message = fmt.Sprintf("[%s] %s", event.Target, message)
+	}
+
+	return c.Output(message)
+}
+
+func (c ConsoleOutputter) Status(migrations []MigrationStatus, summary StatusSummary) error {
+	if len(migrations) == 0 && len(summary.Counts) == 0 {
+		return c.Output(
+			"No migrations have been created.\n" +
+				"Run 'bolt new' to create your first migration.",
+		)
+	}
+
+	if len(migrations) > 0 {
+		headers := []string{"Version", "Message", "State", "Applied At", "Duration"}
+		rows := make([][]string, len(migrations))
+		for i, migration := range migrations {
+			appliedAt := ""
+			if !migration.AppliedAt.IsZero() {
+				appliedAt = migration.AppliedAt.Local().Format(time.DateTime)
+			}
+			duration := ""
+			if migration.Duration != nil {
+				duration = formatDuration(*migration.Duration)
+			}
+
+			rows[i] = []string{
+				migration.Version,
+				migration.Message,
+				strings.ReplaceAll(string(migration.State), "_", " "),
+				appliedAt,
+				duration,
+			}
+		}
+
+		err := c.Table(headers, rows)
+		if err != nil {
+			return err
+		}
+		err = c.Output("")
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.Output(summary.String())
+}
+
+// formatDuration formats a recorded migration duration. Durations are
+// recorded to the millisecond, so shorter ones are shown as under one.
+func formatDuration(duration time.Duration) string {
+	if duration < time.Millisecond {
+		return "<1ms"
+	}
+	return duration.String()
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"io"
 	"testing"
+	"time"
 
+	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/checkmate/check"
 )
 
@@ -75,3 +77,132 @@ func TestConsoleOutputter_Table(t *testing.T) {
 		check.Equal(t, stderr.String(), "")
 	}
 }
+
+func TestConsoleOutputter_Event(t *testing.T) {
+	type test struct {
+		event          Event
+		expectedStdout string
+	}
+
+	tests := []test{
+		{
+			event:          Event{Type: EventMigrationStarted, Direction: DirectionUp, Migration: "001_test"},
+			expectedStdout: "Applying migration 001_test..\n",
+		},
+		{
+			event:          Event{Type: EventMigrationStarted, Direction: DirectionDown, Migration: "001_test"},
+			expectedStdout: "Reverting migration 001_test..\n",
+		},
+		{
+			event: Event{
+				Type:      EventMigrationFinished,
+				Direction: DirectionUp,
+				Migration: "001_test",
+				Duration:  time.Second,
+			},
+			expectedStdout: "Successfully applied migration 001_test in 1s!\n",
+		},
+		{
+			event: Event{
+				Type:      EventMigrationFinished,
+				Direction: DirectionDown,
+				Migration: "001_test",
+				Duration:  time.Second,
+			},
+			expectedStdout: "Successfully reverted migration 001_test in 1s!\n",
+		},
+		{
+			event: Event{
+				Type:      EventMigrationStarted,
+				Direction: DirectionUp,
+				Migration: "001_test",
+				Tenant:    "acme",
+			},
+			expectedStdout: "[acme] Applying migration 001_test..\n",
+		},
+		{
+			event: Event{
+				Type:      EventMigrationFailed,
+				Direction: DirectionUp,
+				Migration: "001_test",
+				Err:       errors.New("test error"),
+			},
+			expectedStdout: "",
+		},
+	}
+
+	for _, tc := range tests {
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		consoleOutputter := NewConsoleOutputterWithWriters(&stdout, &stderr)
+
+		consoleOutputter.Event(tc.event)
+
+		check.Equal(t, stdout.String(), tc.expectedStdout)
+		check.Equal(t, stderr.String(), "")
+	}
+}
+
+func TestConsoleOutputter_Status(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	consoleOutputter := NewConsoleOutputterWithWriters(&stdout, &stderr)
+	duration := 1500 * time.Millisecond
+	var fastDuration time.Duration
+	migrations := []MigrationStatus{
+		{
+			Version:   "001",
+			Message:   "first",
+			Applied:   true,
+			State:     models.MigrationStateApplied,
+			AppliedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local),
+			Duration:  &duration,
+		},
+		{
+			Version:   "002",
+			Message:   "second",
+			Applied:   true,
+			State:     models.MigrationStateApplied,
+			AppliedAt: time.Date(2024, 1, 2, 3, 4, 6, 0, time.Local),
+			Duration:  &fastDuration,
+		},
+		{Version: "003", Message: "third", State: models.MigrationStateOutOfOrder},
+		{Version: "004", Message: "fourth", Applied: true, State: models.MigrationStateChecksumMismatch},
+		{Version: "005", Message: "fifth", State: models.MigrationStatePending},
+	}
+
+	consoleOutputter.Status(migrations, NewStatusSummary(migrations))
+
+	check.Equal(t, stdout.String(), ""+
+		"Version    Message    State                Applied At             Duration    \n"+
+		"001        first      applied              2024-01-02 03:04:05    1.5s        \n"+
+		"002        second     applied              2024-01-02 03:04:06    <1ms        \n"+
+		"003        third      out of order                                            \n"+
+		"004        fourth     checksum mismatch                                       \n"+
+		"005        fifth      pending                                                 \n"+
+		"\n"+
+		"2 applied, 1 pending, 1 out of order, 1 checksum mismatch. Current version: 004.\n")
+	check.Equal(t, stderr.String(), "")
+}
+
+func TestConsoleOutputter_StatusWithoutMatchingMigrations(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	consoleOutputter := NewConsoleOutputterWithWriters(&stdout, &stderr)
+	migrations := []MigrationStatus{{Version: "001", State: models.MigrationStatePending}}
+
+	consoleOutputter.Status([]MigrationStatus{}, NewStatusSummary(migrations))
+
+	check.Equal(t, stdout.String(), "0 applied, 1 pending. Current version: none.\n")
+}
+
+func TestConsoleOutputter_StatusWithoutMigrations(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	consoleOutputter := NewConsoleOutputterWithWriters(&stdout, &stderr)
+
+	consoleOutputter.Status([]MigrationStatus{}, NewStatusSummary(nil))
+
+	check.Equal(t, stdout.String(), "No migrations have been created.\n"+
+		"Run 'bolt new' to create your first migration.\n")
+}
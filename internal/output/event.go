@@ -0,0 +1,103 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eugenetriguba/bolt/internal/models"
+)
+
+type EventType string
+
+const (
+	EventMigrationStarted  EventType = "migration_started"
+	EventMigrationFinished EventType = "migration_finished"
+	EventMigrationFailed   EventType = "migration_failed"
+)
+
+// Direction is whether a migration is being applied or reverted.
+type Direction string
+
+const (
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
+)
+
+// Event is a step in applying or reverting a single migration.
+type Event struct {
+	Type      EventType
+	Direction Direction
+	Version   string
+	// Migration is the name of the migration, which is its
+	// version and normalized message.
+	Migration string
+	// Duration is how long the migration took. It is only set
+	// once the migration has finished or failed.
+	Duration time.Duration
+	// Err is why the migration failed.
+	Err error
+	// Tenant is who the migration is run for when migrating
+	// many tenants. It is empty otherwise.
+	Tenant string
+	// Target is the configured target the migration is run
+	// against when running against every target. It is empty
+	// otherwise.
+	Target string
+}
+
+// MigrationStatus is the state of a single migration.
+type MigrationStatus struct {
+	Version string
+	Message string
+	Applied bool
+	State   models.MigrationState
+	// AppliedAt and Duration are when the migration was applied
+	// and how long it took. They're a zero value and nil when unknown.
+	AppliedAt time.Time
+	Duration  *time.Duration
+}
+
+// StatusSummary is an overview of the state of all migrations.
+type StatusSummary struct {
+	// Counts is how many migrations there are in each state.
+	Counts map[models.MigrationState]int
+	// CurrentVersion is the version of the latest applied
+	// migration. It is empty when none are applied.
+	CurrentVersion string
+}
+
+// NewStatusSummary summarizes the migrations, which are
+// expected to be in ascending order.
+func NewStatusSummary(migrations []MigrationStatus) StatusSummary {
+	summary := StatusSummary{Counts: make(map[models.MigrationState]int)}
+	for _, migration := range migrations {
+		summary.Counts[migration.State]++
+		if migration.Applied {
+			summary.CurrentVersion = migration.Version
+		}
+	}
+	return summary
+}
+
+// String gives back the summary as a sentence, such as
+// "2 applied, 1 pending. Current version: 002."
+func (s StatusSummary) String() string {
+	counts := make([]string, 0, len(models.MigrationStates))
+	for _, state := range models.MigrationStates {
+		count := s.Counts[state]
+		if count == 0 &&
+			state != models.MigrationStateApplied &&
+			state != models.MigrationStatePending {
+			continue
+		}
+		counts = append(counts, fmt.Sprintf("%d %s", count, strings.ReplaceAll(string(state), "_", " ")))
+	}
+
+	currentVersion := s.CurrentVersion
+	if currentVersion == "" {
+		currentVersion = "none"
+	}
+
+	return fmt.Sprintf("%s. Current version: %s.", strings.Join(counts, ", "), currentVersion)
+}
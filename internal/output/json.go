@@ -0,0 +1,192 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eugenetriguba/bolt/internal/models"
+)
+
+// JSONOutputter outputs JSON records for scripts to consume. When
+// streaming, each record is written on its own line as soon as it
+// happens. Otherwise, the records are written as a single JSON
+// array when the outputter is flushed.
+type JSONOutputter struct {
+	w       io.Writer
+	stream  bool
+	records *[]any
+}
+
+func NewJSONOutputter(stream bool) JSONOutputter {
+	return JSONOutputter{w: os.Stdout, stream: stream, records: &[]any{}}
+}
+
+type messageRecord struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type errorRecord struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+type tableRecord struct {
+	Type string              `json:"type"`
+	Rows []map[string]string `json:"rows"`
+}
+
+type eventRecord struct {
+	Type       EventType `json:"type"`
+	Direction  Direction `json:"direction"`
+	Version    string    `json:"version"`
+	Migration  string    `json:"migration"`
+	DurationMs *float64  `json:"duration_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Tenant     string    `json:"tenant,omitempty"`
+	Target     string    `json:"target,omitempty"`
+}
+
+type statusRecord struct {
+	Type       string                  `json:"type"`
+	Migrations []migrationStatusRecord `json:"migrations"`
+	Summary    statusSummaryRecord     `json:"summary"`
+}
+
+type migrationStatusRecord struct {
+	Version    string                `json:"version"`
+	Message    string                `json:"message"`
+	Applied    bool                  `json:"applied"`
+	State      models.MigrationState `json:"state"`
+	AppliedAt  *time.Time            `json:"applied_at,omitempty"`
+	DurationMs *float64              `json:"duration_ms,omitempty"`
+}
+
+type statusSummaryRecord struct {
+	Counts         map[models.MigrationState]int `json:"counts"`
+	CurrentVersion *string                       `json:"current_version"`
+}
+
+func (j JSONOutputter) Output(message string) error {
+	return j.write(messageRecord{Type: "message", Message: message})
+}
+
+func (j JSONOutputter) Result(message string) error {
+	return j.Output(message)
+}
+
+func (j JSONOutputter) Error(err error) error {
+	return j.write(errorRecord{Type: "error", Error: err.Error()})
+}
+
+// Table outputs each row as an object keyed by its
+// snake_cased header.
+func (j JSONOutputter) Table(headers []string, rows [][]string) error {
+	keys := make([]string, len(headers))
+	for i, header := range headers {
+		keys[i] = strings.ReplaceAll(strings.ToLower(header), " ", "_")
+	}
+
+	records := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		records[i] = make(map[string]string, len(row))
+		for k, cell := range row {
+			if k < len(keys) {
+				records[i][keys[k]] = cell
+			}
+		}
+	}
+
+	return j.write(tableRecord{Type: "table", Rows: records})
+}
+
+func (j JSONOutputter) Event(event Event) error {
+	record := eventRecord{
+		Type:      event.Type,
+		Direction: event.Direction,
+		Version:   event.Version,
+		Migration: event.Migration,
+		Tenant:    event.Tenant,
+		Target:    event.Target,
+	}
+	if event.Type != EventMigrationStarted {
+		record.DurationMs = durationMs(event.Duration)
+	}
+	if event.Err != nil {
+		record.Error = event.Err.Error()
+	}
+
+	return j.write(record)
+}
+
+func (j JSONOutputter) Status(migrations []MigrationStatus, summary StatusSummary) error {
+	record := statusRecord{
+		Type:       "status",
+		Migrations: make([]migrationStatusRecord, len(migrations)),
+		Summary:    statusSummaryRecord{Counts: make(map[models.MigrationState]int)},
+	}
+	for i, migration := range migrations {
+		record.Migrations[i] = migrationStatusRecord{
+			Version: migration.Version,
+			Message: migration.Message,
+			Applied: migration.Applied,
+			State:   migration.State,
+		}
+		if !migration.AppliedAt.IsZero() {
+			appliedAt := migration.AppliedAt
+			record.Migrations[i].AppliedAt = &appliedAt
+		}
+		if migration.Duration != nil {
+			record.Migrations[i].DurationMs = durationMs(*migration.Duration)
+		}
+	}
+	for _, state := range models.MigrationStates {
+		record.Summary.Counts[state] = summary.Counts[state]
+	}
+	if summary.CurrentVersion != "" {
+		record.Summary.CurrentVersion = &summary.CurrentVersion
+	}
+
+	return j.write(record)
+}
+
+// durationMs gives back the duration in milliseconds
+// with microsecond precision.
+func durationMs(duration time.Duration) *float64 {
+	ms := float64(duration.Microseconds()) / 1000
+	return &ms
+}
+
+func (j JSONOutputter) write(record any) error {
+	if !j.stream {
+		*j.records = append(*j.records, record)
+		return nil
+	}
+
+	err := json.NewEncoder(j.w).Encode(record)
+	if err != nil {
+		return fmt.Errorf("unable to output record: %w", err)
+	}
+	return nil
+}
+
+// Flush writes out the records held onto when not streaming.
+func (j JSONOutputter) Flush() error {
+	if j.stream {
+		return nil
+	}
+
+	encoder := json.NewEncoder(j.w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(*j.records)
+	if err != nil {
+		return fmt.Errorf("unable to output records: %w", err)
+	}
+
+	*j.records = (*j.records)[:0]
+	return nil
+}
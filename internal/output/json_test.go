@@ -0,0 +1,162 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func NewJSONOutputterWithWriter(w *bytes.Buffer, stream bool) JSONOutputter {
+	return JSONOutputter{w: w, stream: stream, records: &[]any{}}
+}
+
+func TestJSONOutputter_StreamWritesRecordPerLine(t *testing.T) {
+	var stdout bytes.Buffer
+	jsonOutputter := NewJSONOutputterWithWriter(&stdout, true)
+
+	jsonOutputter.Event(Event{
+		Type:      EventMigrationStarted,
+		Direction: DirectionUp,
+		Version:   "001",
+		Migration: "001_test",
+	})
+	jsonOutputter.Event(Event{
+		Type:      EventMigrationFinished,
+		Direction: DirectionUp,
+		Version:   "001",
+		Migration: "001_test",
+		Duration:  1500 * time.Microsecond,
+	})
+	jsonOutputter.Event(Event{
+		Type:      EventMigrationFailed,
+		Direction: DirectionDown,
+		Version:   "002",
+		Migration: "002_test",
+		Duration:  2 * time.Millisecond,
+		Err:       errors.New("test error"),
+	})
+
+	check.Equal(t, stdout.String(),
+		`{"type":"migration_started","direction":"up","version":"001","migration":"001_test"}`+"\n"+
+			`{"type":"migration_finished","direction":"up","version":"001","migration":"001_test","duration_ms":1.5}`+"\n"+
+			`{"type":"migration_failed","direction":"down","version":"002","migration":"002_test","duration_ms":2,"error":"test error"}`+"\n",
+	)
+
+	streamed := stdout.String()
+	assert.Nil(t, jsonOutputter.Flush())
+	check.Equal(t, stdout.String(), streamed)
+}
+
+func TestJSONOutputter_FlushWritesArray(t *testing.T) {
+	var stdout bytes.Buffer
+	jsonOutputter := NewJSONOutputterWithWriter(&stdout, false)
+
+	jsonOutputter.Output("test message")
+	jsonOutputter.Error(errors.New("test error"))
+	jsonOutputter.Table([]string{"Version", "Applied At"}, [][]string{{"001", "now"}})
+	duration := 1500 * time.Microsecond
+	statuses := []MigrationStatus{
+		{
+			Version:   "001",
+			Message:   "test",
+			Applied:   true,
+			State:     models.MigrationStateApplied,
+			AppliedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Duration:  &duration,
+		},
+		{Version: "002", Message: "test2", State: models.MigrationStatePending},
+	}
+	jsonOutputter.Status(statuses, NewStatusSummary(statuses))
+	check.Equal(t, stdout.String(), "")
+
+	err := jsonOutputter.Flush()
+	assert.Nil(t, err)
+
+	check.Equal(t, stdout.String(), `[
+  {
+    "type": "message",
+    "message": "test message"
+  },
+  {
+    "type": "error",
+    "error": "test error"
+  },
+  {
+    "type": "table",
+    "rows": [
+      {
+        "applied_at": "now",
+        "version": "001"
+      }
+    ]
+  },
+  {
+    "type": "status",
+    "migrations": [
+      {
+        "version": "001",
+        "message": "test",
+        "applied": true,
+        "state": "applied",
+        "applied_at": "2024-01-02T03:04:05Z",
+        "duration_ms": 1.5
+      },
+      {
+        "version": "002",
+        "message": "test2",
+        "applied": false,
+        "state": "pending"
+      }
+    ],
+    "summary": {
+      "counts": {
+        "applied": 1,
+        "checksum_mismatch": 0,
+        "dirty": 0,
+        "missing_file": 0,
+        "out_of_order": 0,
+        "pending": 1
+      },
+      "current_version": "001"
+    }
+  }
+]
+`)
+}
+
+func TestJSONOutputter_FlushWithoutRecords(t *testing.T) {
+	var stdout bytes.Buffer
+	jsonOutputter := NewJSONOutputterWithWriter(&stdout, false)
+
+	err := jsonOutputter.Flush()
+	assert.Nil(t, err)
+
+	check.Equal(t, stdout.String(), "[]\n")
+}
+
+func TestNewOutputter(t *testing.T) {
+	outputter, err := NewOutputter(FormatText)
+	assert.Nil(t, err)
+	_, ok := outputter.(ConsoleOutputter)
+	check.True(t, ok)
+
+	outputter, err = NewOutputter(FormatJSON)
+	assert.Nil(t, err)
+	jsonOutputter, ok := outputter.(JSONOutputter)
+	check.True(t, ok)
+	check.False(t, jsonOutputter.stream)
+
+	outputter, err = NewOutputter(FormatNDJSON)
+	assert.Nil(t, err)
+	jsonOutputter, ok = outputter.(JSONOutputter)
+	check.True(t, ok)
+	check.True(t, jsonOutputter.stream)
+
+	_, err = NewOutputter("yaml")
+	check.ErrorIs(t, err, ErrUnsupportedFormat)
+}
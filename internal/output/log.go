@@ -0,0 +1,81 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+var ErrUnsupportedLogLevel = fmt.Errorf(
+	"unsupported log level, supported levels are %s",
+	[]string{"debug", "info", "warn", "error"},
+)
+
+// ParseLogLevel parses the name of a log level, such as "debug",
+// into its slog.Level. The name is case-insensitive.
+func ParseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("%w: %s", ErrUnsupportedLogLevel, name)
+	}
+}
+
+// NewLogger creates a logger that writes records at or above
+// level to w. The records are JSON when the output format is
+// JSON so they can be consumed the same way. Any attributes
+// that look like they hold a secret are redacted.
+func NewLogger(w io.Writer, level slog.Level, format Format) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactSecretAttr}
+	if format == FormatJSON || format == FormatNDJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+func redactSecretAttr(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	if strings.Contains(key, "password") || strings.Contains(key, "secret") {
+		return slog.String(attr.Key, "[REDACTED]")
+	}
+	return attr
+}
+
+// QuietOutputter only outputs the results a command was run
+// for, such as results, tables, and statuses, and any errors. Progress
+// messages and migrations that finished successfully are left out.
+type QuietOutputter struct {
+	Outputter
+}
+
+func NewQuietOutputter(outputter Outputter) QuietOutputter {
+	return QuietOutputter{Outputter: outputter}
+}
+
+func (q QuietOutputter) Output(message string) error {
+	return nil
+}
+
+func (q QuietOutputter) Event(event Event) error {
+	if event.Type != EventMigrationFailed {
+		return nil
+	}
+	return q.Outputter.Event(event)
+}
+
+// Flush flushes the wrapped outputter if it holds
+// onto its output until the command is finished.
+func (q QuietOutputter) Flush() error {
+	if flusher, ok := q.Outputter.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	type test struct {
+		name          string
+		expectedLevel slog.Level
+	}
+
+	tests := []test{
+		{name: "debug", expectedLevel: slog.LevelDebug},
+		{name: "INFO", expectedLevel: slog.LevelInfo},
+		{name: "warn", expectedLevel: slog.LevelWarn},
+		{name: "warning", expectedLevel: slog.LevelWarn},
+		{name: " error ", expectedLevel: slog.LevelError},
+	}
+
+	for _, tc := range tests {
+		level, err := ParseLogLevel(tc.name)
+		assert.Nil(t, err)
+		check.Equal(t, level, tc.expectedLevel)
+	}
+}
+
+func TestParseLogLevel_Unsupported(t *testing.T) {
+	_, err := ParseLogLevel("trace")
+	check.ErrorIs(t, err, ErrUnsupportedLogLevel)
+}
+
+func TestNewLogger_FiltersByLevel(t *testing.T) {
+	var logs bytes.Buffer
+	logger := NewLogger(&logs, slog.LevelWarn, FormatText)
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	check.False(t, strings.Contains(logs.String(), "hidden"))
+	check.True(t, strings.Contains(logs.String(), "msg=shown"))
+}
+
+func TestNewLogger_RedactsSecrets(t *testing.T) {
+	var logs bytes.Buffer
+	logger := NewLogger(&logs, slog.LevelDebug, FormatJSON)
+
+	logger.Debug("connecting", slog.String("user", "bolt"), slog.String("password", "hunter2"))
+
+	check.True(t, strings.HasPrefix(logs.String(), "{"))
+	check.True(t, strings.Contains(logs.String(), `"user":"bolt"`))
+	check.True(t, strings.Contains(logs.String(), `"password":"[REDACTED]"`))
+	check.False(t, strings.Contains(logs.String(), "hunter2"))
+}
+
+func TestQuietOutputter_OnlyOutputsResultsAndErrors(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	quietOutputter := NewQuietOutputter(NewConsoleOutputterWithWriters(&stdout, &stderr))
+
+	quietOutputter.Output("progress")
+	quietOutputter.Event(Event{Type: EventMigrationStarted, Direction: DirectionUp, Migration: "001_test"})
+	quietOutputter.Event(Event{Type: EventMigrationFinished, Direction: DirectionUp, Migration: "001_test"})
+	quietOutputter.Result("002")
+	quietOutputter.Table([]string{"Version"}, [][]string{{"001"}})
+	quietOutputter.Error(errors.New("test error"))
+
+	check.Equal(t, stdout.String(), "002\nVersion    \n001        \n")
+	check.Equal(t, stderr.String(), "test error\n")
+}
+
+func TestQuietOutputter_FlushesWrappedOutputter(t *testing.T) {
+	var stdout bytes.Buffer
+	quietOutputter := NewQuietOutputter(NewJSONOutputterWithWriter(&stdout, false))
+
+	quietOutputter.Output("progress")
+	quietOutputter.Error(errors.New("test error"))
+	err := quietOutputter.Flush()
+	assert.Nil(t, err)
+
+	check.Equal(t, stdout.String(), "[\n  {\n    \"type\": \"error\",\n    \"error\": \"test error\"\n  }\n]\n")
+}
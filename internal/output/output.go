@@ -1,7 +1,56 @@
 package output
 
+import "fmt"
+
 type Outputter interface {
 	Output(message string) error
+	// Result outputs the result a command was run for when it is
+	// a single value, such as the current version. Unlike Output,
+	// it is kept in quiet mode.
+	Result(message string) error
 	Error(err error) error
 	Table(header []string, rows [][]string) error
+	// Event reports the progress of a migration as it is
+	// applied or reverted.
+	Event(event Event) error
+	// Status reports the state of the migrations along
+	// with a summary of all of them.
+	Status(migrations []MigrationStatus, summary StatusSummary) error
+}
+
+// Flusher is implemented by outputters that hold onto their
+// output until the command is finished.
+type Flusher interface {
+	Flush() error
+}
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	// FormatJSON outputs a single JSON array of records
+	// once the command is finished.
+	FormatJSON Format = "json"
+	// FormatNDJSON outputs a JSON record per line as soon
+	// as it happens.
+	FormatNDJSON Format = "ndjson"
+)
+
+var ErrUnsupportedFormat = fmt.Errorf(
+	"unsupported output format, supported formats are %s",
+	[]Format{FormatText, FormatJSON, FormatNDJSON},
+)
+
+// NewOutputter creates the outputter for the output format.
+func NewOutputter(format Format) (Outputter, error) {
+	switch format {
+	case FormatText:
+		return NewConsoleOutputter(), nil
+	case FormatJSON:
+		return NewJSONOutputter(false), nil
+	case FormatNDJSON:
+		return NewJSONOutputter(true), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
 }
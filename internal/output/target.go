@@ -0,0 +1,49 @@
+package output
+
+import "fmt"
+
+// TargetOutputter outputs for a single target while a command is
+// run against every target in turn. Its messages, errors, and
+// events are labeled with the target, and its tables and statuses
+// are preceded by a heading with the target.
+type TargetOutputter struct {
+	Outputter
+	target string
+}
+
+func NewTargetOutputter(outputter Outputter, target string) TargetOutputter {
+	return TargetOutputter{Outputter: outputter, target: target}
+}
+
+func (o TargetOutputter) Output(message string) error {
+	return o.Outputter.Output(fmt.Sprintf("[%s] %s", o.target, message))
+}
+
+func (o TargetOutputter) Result(message string) error {
+	return o.Outputter.Result(fmt.Sprintf("[%s] %s", o.target, message))
+}
+
+func (o TargetOutputter) Error(err error) error {
+	return o.Outputter.Error(fmt.Errorf("[%s] %w", o.target, err))
+}
+
+func (o TargetOutputter) Event(event Event) error {
+	event.Target = o.target
+	return o.Outputter.Event(event)
+}
+
+func (o TargetOutputter) Table(headers []string, rows [][]string) error {
+	err := o.Outputter.Output(fmt.Sprintf("Target %s:", o.target))
+	if err != nil {
+		return err
+	}
+	return o.Outputter.Table(headers, rows)
+}
+
+func (o TargetOutputter) Status(migrations []MigrationStatus, summary StatusSummary) error {
+	err := o.Outputter.Output(fmt.Sprintf("Target %s:", o.target))
+	if err != nil {
+		return err
+	}
+	return o.Outputter.Status(migrations, summary)
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func TestTargetOutputter_LabelsOutputWithTarget(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	targetOutputter := NewTargetOutputter(NewConsoleOutputterWithWriters(&stdout, &stderr), "main")
+
+	targetOutputter.Output("progress")
+	targetOutputter.Event(Event{Type: EventMigrationStarted, Direction: DirectionUp, Migration: "001_test"})
+	targetOutputter.Table([]string{"Version"}, [][]string{{"001"}})
+	targetOutputter.Error(errors.New("test error"))
+
+	check.Equal(
+		t,
+		stdout.String(),
+		"[main] progress\n[main] Applying migration 001_test..\nTarget main:\nVersion    \n001        \n",
+	)
+	check.Equal(t, stderr.String(), "[main] test error\n")
+}
+
+func TestTargetOutputter_LabelsTenantsWithinTarget(t *testing.T) {
+	var stdout bytes.Buffer
+	var mu sync.Mutex
+	targetOutputter := NewTargetOutputter(NewJSONOutputterWithWriter(&stdout, true), "main")
+	tenantOutputter := NewTenantOutputter(targetOutputter, "acme", &mu)
+
+	tenantOutputter.Output("progress")
+	tenantOutputter.Event(Event{
+		Type:      EventMigrationStarted,
+		Direction: DirectionUp,
+		Version:   "001",
+		Migration: "001_test",
+	})
+
+	check.Equal(
+		t,
+		stdout.String(),
+		`{"type":"message","message":"[main] [acme] progress"}`+"\n"+
+			`{"type":"migration_started","direction":"up","version":"001","migration":"001_test","tenant":"acme","target":"main"}`+"\n",
+	)
+}
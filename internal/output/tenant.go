@@ -0,0 +1,57 @@
+package output
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TenantOutputter outputs for a single tenant while other tenants
+// are being migrated at the same time. Its messages, errors, and
+// events are labeled with the tenant, and it shares a lock with
+// the other tenants' outputters so their output doesn't interleave.
+type TenantOutputter struct {
+	outputter Outputter
+	tenant    string
+	mu        *sync.Mutex
+}
+
+func NewTenantOutputter(outputter Outputter, tenant string, mu *sync.Mutex) TenantOutputter {
+	return TenantOutputter{outputter: outputter, tenant: tenant, mu: mu}
+}
+
+func (o TenantOutputter) Output(message string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.outputter.Output(fmt.Sprintf("[%s] %s", o.tenant, message))
+}
+
+func (o TenantOutputter) Result(message string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.outputter.Result(fmt.Sprintf("[%s] %s", o.tenant, message))
+}
+
+func (o TenantOutputter) Error(err error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.outputter.Error(fmt.Errorf("[%s] %w", o.tenant, err))
+}
+
+func (o TenantOutputter) Table(headers []string, rows [][]string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.outputter.Table(headers, rows)
+}
+
+func (o TenantOutputter) Event(event Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	event.Tenant = o.tenant
+	return o.outputter.Event(event)
+}
+
+func (o TenantOutputter) Status(migrations []MigrationStatus, summary StatusSummary) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.outputter.Status(migrations, summary)
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func TestTenantOutputter_LabelsOutputWithTenant(t *testing.T) {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	var mu sync.Mutex
+	tenantOutputter := NewTenantOutputter(NewConsoleOutputterWithWriters(&stdout, &stderr), "acme", &mu)
+
+	tenantOutputter.Output("progress")
+	tenantOutputter.Event(Event{Type: EventMigrationStarted, Direction: DirectionUp, Migration: "001_test"})
+	tenantOutputter.Table([]string{"Version"}, [][]string{{"001"}})
+	tenantOutputter.Error(errors.New("test error"))
+
+	check.Equal(
+		t,
+		stdout.String(),
+		"[acme] progress\n[acme] Applying migration 001_test..\nVersion    \n001        \n",
+	)
+	check.Equal(t, stderr.String(), "[acme] test error\n")
+}
+
+func TestTenantOutputter_AddsTenantToJSONEvents(t *testing.T) {
+	var stdout bytes.Buffer
+	var mu sync.Mutex
+	tenantOutputter := NewTenantOutputter(NewJSONOutputterWithWriter(&stdout, true), "acme", &mu)
+
+	tenantOutputter.Event(Event{
+		Type:      EventMigrationStarted,
+		Direction: DirectionUp,
+		Version:   "001",
+		Migration: "001_test",
+	})
+
+	check.Equal(
+		t,
+		stdout.String(),
+		`{"type":"migration_started","direction":"up","version":"001","migration":"001_test","tenant":"acme"}`+"\n",
+	)
+}
+
+func TestTenantOutputter_SharesLockAcrossTenants(t *testing.T) {
+	var stdout bytes.Buffer
+	var mu sync.Mutex
+	jsonOutputter := NewJSONOutputterWithWriter(&stdout, false)
+
+	var wg sync.WaitGroup
+	for _, tenant := range []string{"acme", "globex", "initech", "umbrella"} {
+		wg.Add(1)
+		go func(tenant string) {
+			defer wg.Done()
+			tenantOutputter := NewTenantOutputter(jsonOutputter, tenant, &mu)
+			for i := 0; i < 100; i++ {
+				tenantOutputter.Output("progress")
+			}
+		}(tenant)
+	}
+	wg.Wait()
+
+	check.Equal(t, len(*jsonOutputter.records), 400)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/bolt/internal/storage"
@@ -18,8 +19,34 @@ type MigrationDBRepo interface {
 	ApplyWithTx(upgradeScript string, migration *models.Migration) error
 	Revert(downgradeScript string, migration *models.Migration) error
 	RevertWithTx(downgradeScript string, migration *models.Migration) error
+	MarkApplied(migration *models.Migration) error
+	MarkUnapplied(migration *models.Migration) error
+	MarkClean(migration *models.Migration) error
+	Squash(migration *models.Migration, versions []string) error
+	DumpSchema() (string, error)
+	LoadSchema(schema string, versions []string) error
+	InspectSchema() (models.Schema, error)
+	Tx(fn func(repo MigrationDBRepo) error) error
 }
 
+// migrationTableColumns are the columns of the migrations table
+// that record how a migration was applied. They're added to
+// migrations tables created before they existed.
+var migrationTableColumns = []struct {
+	name       string
+	definition string
+}{
+	{name: "applied_at", definition: "VARCHAR(64)"},
+	{name: "execution_time_ms", definition: "BIGINT"},
+	{name: "checksum", definition: "VARCHAR(64)"},
+	{name: "dirty", definition: "SMALLINT"},
+}
+
+// appliedAtLayout is the layout applied_at is stored in. Times are
+// stored as UTC text, rather than a timestamp type, so they sort
+// and read back the same way on every database.
+const appliedAtLayout = "2006-01-02T15:04:05.000000Z07:00"
+
 type migrationDBRepo struct {
 	migrationTableName string
 	db                 storage.DB
@@ -30,7 +57,7 @@ type migrationDBRepo struct {
 // operates on exists. If it is unable to create or confirm
 // the table exists, an error is returned.
 func NewMigrationDBRepo(migrationTableName string, db storage.DB) (MigrationDBRepo, error) {
-	err := sanitizeTableName(migrationTableName)
+	err := ValidateTableName(migrationTableName)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"invalid migration table name: %w",
@@ -48,11 +75,16 @@ func NewMigrationDBRepo(migrationTableName string, db storage.DB) (MigrationDBRe
 	}
 
 	if !migrationTableExists {
+		columns := make([]string, len(migrationTableColumns))
+		for i, column := range migrationTableColumns {
+			columns[i] = fmt.Sprintf("%s %s NULL", column.name, column.definition)
+		}
 		_, err := db.Exec(fmt.Sprintf(`
 			CREATE TABLE %s (
-				version VARCHAR(255) PRIMARY KEY NOT NULL
-			);
-		`, migrationTableName))
+				version VARCHAR(255) NOT NULL PRIMARY KEY,
+				%s
+			) %s;
+		`, migrationTableName, strings.Join(columns, ",\n\t\t\t\t"), storage.TableOptions(db)))
 		if err != nil {
 			return nil, fmt.Errorf(
 				"unable to create '%s' database table: %w",
@@ -60,12 +92,53 @@ func NewMigrationDBRepo(migrationTableName string, db storage.DB) (MigrationDBRe
 				err,
 			)
 		}
+	} else {
+		err = addMissingColumns(migrationTableName, db)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &migrationDBRepo{migrationTableName: migrationTableName, db: db}, nil
 }
 
-func sanitizeTableName(tableName string) error {
+func addMissingColumns(migrationTableName string, db storage.DB) error {
+	for _, column := range migrationTableColumns {
+		exists, err := db.ColumnExists(migrationTableName, column.name)
+		if err != nil {
+			return fmt.Errorf(
+				"unable to confirm '%s' database table has the %s column: %w",
+				migrationTableName,
+				column.name,
+				err,
+			)
+		}
+		if exists {
+			continue
+		}
+
+		_, err = db.Exec(fmt.Sprintf(
+			"ALTER TABLE %s ADD %s %s NULL",
+			migrationTableName,
+			column.name,
+			column.definition,
+		))
+		if err != nil {
+			return fmt.Errorf(
+				"unable to add the %s column to '%s' database table: %w",
+				column.name,
+				migrationTableName,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ValidateTableName checks that the table name is safe to
+// use within a query.
+func ValidateTableName(tableName string) error {
 	// Allow alphanumeric characters, underscores, and a single dot for schema.table
 	validTableName := regexp.MustCompile(`^[a-zA-Z0-9_]+(\.[a-zA-Z0-9_]+)?$`)
 	if !validTableName.MatchString(tableName) {
@@ -81,7 +154,10 @@ func sanitizeTableName(tableName string) error {
 // will be ones that have been applied, and their message
 // will always be an empty string.
 func (mr migrationDBRepo) List() (map[string]*models.Migration, error) {
-	rows, err := mr.db.Query(fmt.Sprintf("SELECT version FROM %s;", mr.migrationTableName))
+	rows, err := mr.db.Query(fmt.Sprintf(
+		"SELECT version, applied_at, execution_time_ms, checksum, dirty FROM %s;",
+		mr.migrationTableName,
+	))
 	if err != nil {
 		return nil, fmt.Errorf(
 			"unable to execute query to select versions from "+
@@ -95,7 +171,11 @@ func (mr migrationDBRepo) List() (map[string]*models.Migration, error) {
 	var migrations = make(map[string]*models.Migration, 0)
 	for rows.Next() {
 		var version string
-		err := rows.Scan(&version)
+		var appliedAt sql.NullString
+		var executionTimeMs sql.NullInt64
+		var checksum sql.NullString
+		var dirty sql.NullInt64
+		err := rows.Scan(&version, &appliedAt, &executionTimeMs, &checksum, &dirty)
 		if err != nil {
 			return nil, fmt.Errorf(
 				"unable to scan version row from applied migrations: %w",
@@ -103,14 +183,31 @@ func (mr migrationDBRepo) List() (map[string]*models.Migration, error) {
 			)
 		}
 		trimmedVersion := strings.TrimSpace(version)
-		migrations[trimmedVersion] = &models.Migration{
+		migration := &models.Migration{
 			Version: trimmedVersion,
 			// Note: We don't store the user-friendly message for
 			// the migration in the database. It's purely for the
 			// user to understand what the migration was locally.
-			Message: "",
-			Applied: true,
+			Message:  "",
+			Applied:  true,
+			Checksum: strings.TrimSpace(checksum.String),
+			Dirty:    dirty.Valid && dirty.Int64 != 0,
+		}
+		if executionTimeMs.Valid {
+			executionTime := time.Duration(executionTimeMs.Int64) * time.Millisecond
+			migration.ExecutionTime = &executionTime
 		}
+		if appliedAt.Valid {
+			migration.AppliedAt, err = time.Parse(time.RFC3339Nano, strings.TrimSpace(appliedAt.String))
+			if err != nil {
+				return nil, fmt.Errorf(
+					"unable to parse when migration %s was applied: %w",
+					trimmedVersion,
+					err,
+				)
+			}
+		}
+		migrations[trimmedVersion] = migration
 	}
 
 	return migrations, nil
@@ -139,30 +236,76 @@ func (mr migrationDBRepo) IsApplied(version string) (bool, error) {
 }
 
 // Apply applies a migration by executing the corresponding upgrade script
-// and adding the applied migration version into the migrations table. When
-// successfully applied, the `migration` model's `Applied` field will be set
-// to true.
+// and adding the applied migration version into the migrations table along
+// with when it was applied, how long it took, and the script's checksum.
+// When successfully applied, the `migration` model's `Applied` field will
+// be set to true.
+//
+// Since the upgrade script isn't executed within a transaction, the
+// migration is recorded as dirty before the script is executed and is
+// only marked clean once it succeeds. If the script fails partway
+// through, the migration is left dirty.
 func (mr migrationDBRepo) Apply(
 	upgradeScript string,
 	migration *models.Migration,
 ) error {
-	err := mr.applyMigration(upgradeScript, *migration)
+	dirtyMigration := *migration
+	dirtyMigration.AppliedAt = time.Now().UTC()
+	dirtyMigration.Checksum = models.Checksum(upgradeScript)
+	dirtyMigration.Dirty = true
+	err := mr.insertMigration(mr.db, dirtyMigration)
 	if err != nil {
 		return err
 	}
 
-	migration.Applied = true
+	// The script's statements before a failing one stay applied
+	// outside of a transaction, so the script can't be retried.
+	startTime := time.Now()
+	_, err = storage.WithoutRetries(mr.db).Exec(upgradeScript)
+	if err != nil {
+		migration.Dirty = true
+		return fmt.Errorf("unable to execute upgrade script: %w", err)
+	}
+
+	executionTime := time.Since(startTime)
+	appliedMigration := dirtyMigration
+	appliedMigration.Applied = true
+	appliedMigration.ExecutionTime = &executionTime
+	appliedMigration.Dirty = false
+	_, err = mr.db.Exec(
+		storage.UpdateStatement(
+			mr.db,
+			mr.migrationTableName,
+			"execution_time_ms = ?, dirty = NULL",
+			"version = ?",
+		),
+		executionTime.Milliseconds(),
+		appliedMigration.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to mark migration as clean: %w", err)
+	}
+
+	*migration = appliedMigration
 	return nil
 }
 
 // ApplyWithTx applies a migration like Apply. However, it
-// wraps the operation is a database transaction.
+// wraps the operation is a database transaction. On databases
+// without transactions, it falls back to Apply so that a
+// failing migration is left dirty.
 func (mr migrationDBRepo) ApplyWithTx(
 	upgradeScript string,
 	migration *models.Migration,
 ) error {
+	if !storage.HasTransactions(mr.db) {
+		return mr.Apply(upgradeScript, migration)
+	}
+
+	var appliedMigration models.Migration
 	err := mr.db.Tx(func(db storage.DB) error {
-		err := mr.applyMigration(upgradeScript, *migration)
+		var err error
+		appliedMigration, err = mr.withDB(db).applyMigration(upgradeScript, *migration)
 		if err != nil {
 			return err
 		}
@@ -172,20 +315,53 @@ func (mr migrationDBRepo) ApplyWithTx(
 		return err
 	}
 
-	migration.Applied = true
+	*migration = appliedMigration
 	return nil
 }
 
 func (mr migrationDBRepo) applyMigration(
 	upgradeScript string,
 	migration models.Migration,
-) error {
+) (models.Migration, error) {
+	startTime := time.Now()
 	_, err := mr.db.Exec(upgradeScript)
 	if err != nil {
-		return fmt.Errorf("unable to execute upgrade script: %w", err)
+		return migration, fmt.Errorf("unable to execute upgrade script: %w", err)
+	}
+
+	executionTime := time.Since(startTime)
+	migration.Applied = true
+	migration.AppliedAt = time.Now().UTC()
+	migration.ExecutionTime = &executionTime
+	migration.Checksum = models.Checksum(upgradeScript)
+	return migration, mr.insertMigration(mr.db, migration)
+}
+
+func (mr migrationDBRepo) insertMigration(db storage.DB, migration models.Migration) error {
+	var executionTimeMs sql.NullInt64
+	if migration.ExecutionTime != nil {
+		executionTimeMs = sql.NullInt64{Int64: migration.ExecutionTime.Milliseconds(), Valid: true}
+	}
+	var checksum sql.NullString
+	if migration.Checksum != "" {
+		checksum = sql.NullString{String: migration.Checksum, Valid: true}
+	}
+	var dirty sql.NullInt64
+	if migration.Dirty {
+		dirty = sql.NullInt64{Int64: 1, Valid: true}
 	}
 
-	_, err = mr.db.Exec(fmt.Sprintf("INSERT INTO %s(version) VALUES(?)", mr.migrationTableName), migration.Version)
+	_, err := db.Exec(
+		fmt.Sprintf(
+			"INSERT INTO %s(version, applied_at, execution_time_ms, checksum, dirty) VALUES(?, ?, ?, ?, ?)",
+			mr.migrationTableName,
+		),
+		migration.Version,
+		migration.AppliedAt.UTC().Format(appliedAtLayout),
+		executionTimeMs,
+		checksum,
+		dirty,
+	)
 	if err != nil {
 		return fmt.Errorf(
 			"unable to insert migration: %w",
@@ -196,30 +372,65 @@ func (mr migrationDBRepo) applyMigration(
 	return nil
 }
 
+// MarkApplied adds the migration version into the migrations table
+// without executing its upgrade script. Only when it was marked as
+// applied is recorded. When successfully marked,
+// the `migration` model's `Applied` field will be set to true.
+func (mr migrationDBRepo) MarkApplied(migration *models.Migration) error {
+	markedMigration := *migration
+	markedMigration.AppliedAt = time.Now().UTC()
+	err := mr.insertMigration(mr.db, markedMigration)
+	if err != nil {
+		return err
+	}
+
+	migration.Applied = true
+	migration.AppliedAt = markedMigration.AppliedAt
+	return nil
+}
+
 // Revert reverts a migration by executing the corresponding downgrade script
 // and deleting the migration version from the migrations table. When successfully
 // reverted, the `migration` model's `Applied` field will be set to false.
+//
+// Like Apply, the migration is marked as dirty before the downgrade
+// script is executed and is left dirty if the script fails.
 func (mr migrationDBRepo) Revert(
 	downgradeScript string,
 	migration *models.Migration,
 ) error {
-	err := mr.revertMigration(downgradeScript, *migration)
+	_, err := mr.db.Exec(
+		storage.UpdateStatement(mr.db, mr.migrationTableName, "dirty = 1", "version = ?"),
+		migration.Version,
+	)
 	if err != nil {
+		return fmt.Errorf("unable to mark migration as dirty: %w", err)
+	}
+
+	err = mr.revertMigration(downgradeScript, *migration)
+	if err != nil {
+		migration.Dirty = true
 		return err
 	}
 
 	migration.Applied = false
+	migration.Dirty = false
 	return nil
 }
 
 // RevertWithTx reverts a migration like Revert. However, it
-// wraps the operation is a database transaction.
+// wraps the operation is a database transaction. On databases
+// without transactions, it falls back to Revert.
 func (mr migrationDBRepo) RevertWithTx(
 	downgradeScript string,
 	migration *models.Migration,
 ) error {
+	if !storage.HasTransactions(mr.db) {
+		return mr.Revert(downgradeScript, migration)
+	}
+
 	err := mr.db.Tx(func(db storage.DB) error {
-		err := mr.revertMigration(downgradeScript, *migration)
+		err := mr.withDB(db).revertMigration(downgradeScript, *migration)
 		if err != nil {
 			return err
 		}
@@ -237,19 +448,150 @@ func (mr migrationDBRepo) revertMigration(
 	downgradeScript string,
 	migration models.Migration,
 ) error {
-	_, err := mr.db.Exec(downgradeScript)
+	_, err := storage.WithoutRetries(mr.db).Exec(downgradeScript)
 	if err != nil {
 		return fmt.Errorf("unable to execute downgrade script: %w", err)
 	}
 
-	_, err = mr.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE version = ?", mr.migrationTableName), migration.Version)
+	return mr.deleteMigration(migration)
+}
+
+func (mr migrationDBRepo) deleteMigration(migration models.Migration) error {
+	_, err := mr.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE version = ?", mr.migrationTableName), migration.Version)
+	if err != nil {
+		return fmt.Errorf(
+			"unable to remove migration from %s table: %w",
+			mr.migrationTableName,
+			err,
+		)
+	}
+
+	return nil
+}
+
+// MarkUnapplied removes the migration version from the migrations
+// table without executing its downgrade script. When successfully
+// marked, the `migration` model's `Applied` field will be set to false.
+func (mr migrationDBRepo) MarkUnapplied(migration *models.Migration) error {
+	err := mr.deleteMigration(*migration)
+	if err != nil {
+		return err
+	}
+
+	migration.Applied = false
+	return nil
+}
+
+// MarkClean clears the dirty state of a migration left behind by an
+// upgrade script that failed partway through, so that the migration
+// is treated as applied. When successfully marked, the `migration`
+// model's `Dirty` field will be set to false.
+func (mr migrationDBRepo) MarkClean(migration *models.Migration) error {
+	_, err := mr.db.Exec(
+		storage.UpdateStatement(mr.db, mr.migrationTableName, "dirty = NULL", "version = ?"),
+		migration.Version,
+	)
 	if err != nil {
 		return fmt.Errorf(
-			"unable to remove reverted migration from %s table: %w",
+			"unable to mark migration as clean in %s table: %w",
 			mr.migrationTableName,
 			err,
 		)
 	}
 
+	migration.Dirty = false
 	return nil
 }
+
+// Squash replaces the versions in the migrations table with the
+// squashed migration within a transaction. Every version other than
+// the migration's is removed, and the migration's checksum is updated
+// to the squashed migration's so it doesn't show up as modified.
+func (mr migrationDBRepo) Squash(migration *models.Migration, versions []string) error {
+	return mr.db.Tx(func(db storage.DB) error {
+		repo := mr.withDB(db)
+		for _, version := range versions {
+			if version == migration.Version {
+				continue
+			}
+			err := repo.deleteMigration(models.Migration{Version: version})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := db.Exec(
+			storage.UpdateStatement(db, mr.migrationTableName, "checksum = ?", "version = ?"),
+			migration.Checksum,
+			migration.Version,
+		)
+		if err != nil {
+			return fmt.Errorf(
+				"unable to update migration checksum in %s table: %w",
+				mr.migrationTableName,
+				err,
+			)
+		}
+
+		return nil
+	})
+}
+
+// DumpSchema generates the statements that recreate the
+// database's schema, leaving out the migrations table.
+func (mr migrationDBRepo) DumpSchema() (string, error) {
+	schema, err := mr.db.DumpSchema(mr.migrationTableName)
+	if err != nil {
+		return "", fmt.Errorf("unable to dump database schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+// LoadSchema executes a schema dump and adds the versions it
+// was dumped at into the migrations table within a transaction.
+func (mr migrationDBRepo) LoadSchema(schema string, versions []string) error {
+	return mr.db.Tx(func(db storage.DB) error {
+		_, err := db.Exec(schema)
+		if err != nil {
+			return fmt.Errorf("unable to execute schema: %w", err)
+		}
+
+		appliedAt := time.Now().UTC()
+		for _, version := range versions {
+			err = mr.insertMigration(db, models.Migration{Version: version, AppliedAt: appliedAt})
+			if err != nil {
+				return fmt.Errorf("unable to insert migration %s: %w", version, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// InspectSchema retrieves the database's tables along with their
+// columns, indexes, and constraints, leaving out the migrations table.
+func (mr migrationDBRepo) InspectSchema() (models.Schema, error) {
+	schema, err := mr.db.InspectSchema(mr.migrationTableName)
+	if err != nil {
+		return models.Schema{}, fmt.Errorf("unable to inspect database schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+// Tx executes fn within a transaction block. The repo given to fn
+// operates within the transaction, so everything done with it is
+// rolled back if fn returns an error. Otherwise, it is committed.
+func (mr migrationDBRepo) Tx(fn func(repo MigrationDBRepo) error) error {
+	return mr.db.Tx(func(db storage.DB) error {
+		return fn(mr.withDB(db))
+	})
+}
+
+// withDB gives back a copy of the repo that operates on db,
+// such as a transaction, rather than the repo's database.
+func (mr migrationDBRepo) withDB(db storage.DB) migrationDBRepo {
+	mr.db = db
+	return mr
+}
@@ -3,12 +3,14 @@ package repositories_test
 import (
 	"database/sql"
 	"errors"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/eugenetriguba/bolt/internal/bolttest"
 	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/storage"
 	"github.com/eugenetriguba/checkmate/assert"
 )
 
@@ -71,6 +73,47 @@ func TestNewMigrationDBRepo_TableAlreadyExists(t *testing.T) {
 	assert.Equal(t, scanResult, 1)
 }
 
+func TestNewMigrationDBRepo_AddsMissingColumns(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	_, err := testdb.Exec(`CREATE TABLE bolt_migrations(version VARCHAR(255) PRIMARY KEY NOT NULL)`)
+	assert.Nil(t, err)
+	_, err = testdb.Exec(`INSERT INTO bolt_migrations(version) VALUES ('001');`)
+	assert.Nil(t, err)
+
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+
+	for _, column := range []string{"applied_at", "execution_time_ms", "checksum", "dirty"} {
+		exists, err := testdb.ColumnExists("bolt_migrations", column)
+		assert.Nil(t, err)
+		assert.True(t, exists)
+	}
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.DeepEqual(
+		t,
+		migrations["001"],
+		&models.Migration{Version: "001", Message: "", Applied: true},
+	)
+}
+
+func TestNewMigrationDBRepo_ColumnExistsError(t *testing.T) {
+	mockDB := &bolttest.MockDB{
+		TableExistsFunc: func(tableName string) (bool, error) {
+			return true, nil
+		},
+		ColumnExistsFunc: func(tableName string, columnName string) (bool, error) {
+			return false, errors.New("column exists failed")
+		},
+	}
+	_, err := repositories.NewMigrationDBRepo("bolt_migrations", mockDB)
+	assert.ErrorContains(
+		t,
+		err,
+		"unable to confirm 'bolt_migrations' database table has the applied_at column: column exists failed",
+	)
+}
+
 func TestList_EmptyTable(t *testing.T) {
 	db := bolttest.NewTestDB(t)
 	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
@@ -86,6 +129,9 @@ func TestList_QueryError(t *testing.T) {
 		TableExistsFunc: func(tableName string) (bool, error) {
 			return true, nil
 		},
+		ColumnExistsFunc: func(tableName string, columnName string) (bool, error) {
+			return true, nil
+		},
 		QueryFunc: func(query string, args ...interface{}) (*sql.Rows, error) {
 			return nil, errors.New("query error")
 		},
@@ -194,6 +240,27 @@ func TestApply(t *testing.T) {
 	assert.Equal(t, applied, true)
 }
 
+func TestApply_RecordsHowMigrationWasApplied(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+
+	upgradeScript := `CREATE TABLE tmp(id INT NOT NULL PRIMARY KEY)`
+	migration := models.NewTimestampMigration(time.Now(), "test")
+	beforeApply := time.Now().Add(-time.Second)
+	err = repo.Apply(upgradeScript, migration)
+	assert.Nil(t, err)
+
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	appliedMigration := migrations[migration.Version]
+	assert.Equal(t, appliedMigration.Checksum, models.Checksum(upgradeScript))
+	assert.Equal(t, appliedMigration.Checksum, migration.Checksum)
+	assert.True(t, appliedMigration.AppliedAt.After(beforeApply))
+	assert.Equal(t, appliedMigration.AppliedAt.Location(), time.UTC)
+	assert.Equal(t, *appliedMigration.ExecutionTime, migration.ExecutionTime.Truncate(time.Millisecond))
+}
+
 func TestApply_MalformedSql(t *testing.T) {
 	db := bolttest.NewTestDB(t)
 	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
@@ -206,6 +273,36 @@ func TestApply_MalformedSql(t *testing.T) {
 	assert.Equal(t, migration.Applied, false)
 }
 
+func TestApply_MarksMigrationDirtyWhenScriptFails(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+
+	err = repo.Apply("this is not SQL", migration)
+	assert.ErrorContains(t, err, "unable to execute upgrade script")
+	assert.True(t, migration.Dirty)
+
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.True(t, migrations[migration.Version].Dirty)
+}
+
+func TestApply_IsNotDirtyOnceApplied(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+
+	err = repo.Apply("CREATE TABLE tmp(id INT NOT NULL PRIMARY KEY)", migration)
+	assert.Nil(t, err)
+	assert.False(t, migration.Dirty)
+
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.False(t, migrations[migration.Version].Dirty)
+}
+
 func TestApplyWithTx_ExecErr(t *testing.T) {
 	db := bolttest.NewTestDB(t)
 	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
@@ -276,6 +373,23 @@ func TestRevert_MalformedSql(t *testing.T) {
 	assert.Equal(t, migration.Applied, true)
 }
 
+func TestRevert_MarksMigrationDirtyWhenScriptFails(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+	err = repo.MarkApplied(migration)
+	assert.Nil(t, err)
+
+	err = repo.Revert("this is not SQL", migration)
+	assert.ErrorContains(t, err, "unable to execute downgrade script")
+	assert.True(t, migration.Dirty)
+
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.True(t, migrations[migration.Version].Dirty)
+}
+
 func TestRevertWithTx_ExecErr(t *testing.T) {
 	db := bolttest.NewTestDB(t)
 	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
@@ -316,6 +430,189 @@ func TestRevertWithTx_SuccessfullyReverted(t *testing.T) {
 	assert.Equal(t, count, 0)
 }
 
+func TestMarkApplied(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+
+	err = repo.MarkApplied(migration)
+	assert.Nil(t, err)
+	assert.Equal(t, migration.Applied, true)
+
+	applied, err := repo.IsApplied(migration.Version)
+	assert.Nil(t, err)
+	assert.Equal(t, applied, true)
+}
+
+func TestMarkApplied_RecordsAppliedAt(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+
+	err = repo.MarkApplied(migration)
+	assert.Nil(t, err)
+
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	appliedMigration := migrations[migration.Version]
+	assert.False(t, appliedMigration.AppliedAt.IsZero())
+	assert.Equal(t, appliedMigration.Checksum, "")
+	assert.Nil(t, appliedMigration.ExecutionTime)
+}
+
+func TestMarkApplied_AlreadyApplied(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+	err = repo.MarkApplied(migration)
+	assert.Nil(t, err)
+
+	err = repo.MarkApplied(migration)
+
+	assert.ErrorContains(t, err, "unable to insert migration")
+}
+
+func TestMarkUnapplied(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+	err = repo.MarkApplied(migration)
+	assert.Nil(t, err)
+
+	err = repo.MarkUnapplied(migration)
+	assert.Nil(t, err)
+	assert.Equal(t, migration.Applied, false)
+
+	applied, err := repo.IsApplied(migration.Version)
+	assert.Nil(t, err)
+	assert.Equal(t, applied, false)
+}
+
+func TestMarkClean(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
+	assert.Nil(t, err)
+	migration := models.NewTimestampMigration(time.Now(), "test")
+	err = repo.Apply("this is not SQL", migration)
+	assert.NotNil(t, err)
+
+	err = repo.MarkClean(migration)
+	assert.Nil(t, err)
+	assert.False(t, migration.Dirty)
+
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.False(t, migrations[migration.Version].Dirty)
+	assert.True(t, migrations[migration.Version].Applied)
+}
+
+func TestSquash(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", db)
+	assert.Nil(t, err)
+	for _, version := range []string{"001", "002", "003"} {
+		err = repo.Apply("SELECT 1;", &models.Migration{Version: version})
+		assert.Nil(t, err)
+	}
+	migration := &models.Migration{Version: "002", Checksum: models.Checksum("squashed")}
+
+	err = repo.Squash(migration, []string{"001", "002"})
+	assert.Nil(t, err)
+
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 2)
+	assert.Equal(t, migrations["002"].Checksum, migration.Checksum)
+	assert.Equal(t, migrations["003"].Checksum, models.Checksum("SELECT 1;"))
+}
+
+func TestDumpSchema_ExcludesMigrationsTable(t *testing.T) {
+	mockDB := &bolttest.MockDB{
+		TableExistsFunc: func(tableName string) (bool, error) {
+			return true, nil
+		},
+		ColumnExistsFunc: func(tableName string, columnName string) (bool, error) {
+			return true, nil
+		},
+		DumpSchemaFunc: func(excludeTables ...string) (string, error) {
+			assert.DeepEqual(t, excludeTables, []string{"bolt_migrations"})
+			return "CREATE TABLE users(id INT);\n", nil
+		},
+	}
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", mockDB)
+	assert.Nil(t, err)
+
+	schema, err := repo.DumpSchema()
+
+	assert.Nil(t, err)
+	assert.Equal(t, schema, "CREATE TABLE users(id INT);\n")
+}
+
+func TestLoadSchema(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+
+	err = repo.LoadSchema("CREATE TABLE tmp(id INT PRIMARY KEY);", []string{"001", "002"})
+	assert.Nil(t, err)
+
+	exists, err := testdb.TableExists("tmp")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 2)
+}
+
+func TestLoadSchema_RoundTrip(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	dropTables := func() {
+		bolttest.DropTable(t, testdb, "tmp_child")
+		bolttest.DropTable(t, testdb, "tmp")
+	}
+	t.Cleanup(dropTables)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+	statements := []string{
+		"CREATE TABLE tmp(id INT PRIMARY KEY, name VARCHAR(255));",
+		"CREATE INDEX tmp_name_idx ON tmp(name);",
+		"CREATE TABLE tmp_child(id INT PRIMARY KEY, tmp_id INT REFERENCES tmp(id));",
+	}
+	for _, statement := range statements {
+		_, err = testdb.Exec(statement)
+		assert.Nil(t, err)
+	}
+	expectedSchema, err := repo.InspectSchema()
+	assert.Nil(t, err)
+	schema, err := repo.DumpSchema()
+	assert.Nil(t, err)
+	dropTables()
+
+	err = repo.LoadSchema(schema, []string{"001"})
+	assert.Nil(t, err)
+
+	loadedSchema, err := repo.InspectSchema()
+	assert.Nil(t, err)
+	assert.DeepEqual(t, loadedSchema, expectedSchema)
+}
+
+func TestLoadSchema_ExecError(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+
+	err = repo.LoadSchema("CREATE TABLE ;", []string{"001"})
+
+	assert.ErrorContains(t, err, "unable to execute schema")
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 0)
+}
+
 func TestNewMigrationDBRepo_InvalidTableName(t *testing.T) {
 	db := bolttest.NewTestDB(t)
 	invalidTableNames := []string{
@@ -338,3 +635,73 @@ func TestNewMigrationDBRepo_InvalidTableName(t *testing.T) {
 		assert.ErrorContains(t, err, "invalid migration table name")
 	}
 }
+
+func TestInspectSchema_ExcludesMigrationsTable(t *testing.T) {
+	expectedSchema := models.Schema{Tables: []models.Table{{Name: "users"}}}
+	mockDB := &bolttest.MockDB{
+		TableExistsFunc: func(tableName string) (bool, error) {
+			return true, nil
+		},
+		ColumnExistsFunc: func(tableName string, columnName string) (bool, error) {
+			return true, nil
+		},
+		InspectSchemaFunc: func(excludeTables ...string) (models.Schema, error) {
+			assert.DeepEqual(t, excludeTables, []string{"bolt_migrations"})
+			return expectedSchema, nil
+		},
+	}
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", mockDB)
+	assert.Nil(t, err)
+
+	schema, err := repo.InspectSchema()
+
+	assert.Nil(t, err)
+	assert.DeepEqual(t, schema, expectedSchema)
+}
+
+func TestTx_CommitsEverythingAppliedWithinIt(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+	migration := models.NewSequentialMigration(1, "test")
+
+	err = repo.Tx(func(repo repositories.MigrationDBRepo) error {
+		return repo.Apply(`CREATE TABLE tmp(id INT NOT NULL PRIMARY KEY)`, migration)
+	})
+	assert.Nil(t, err)
+
+	exists, err := testdb.TableExists("tmp")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	applied, err := repo.IsApplied(migration.Version)
+	assert.Nil(t, err)
+	assert.True(t, applied)
+}
+
+func TestTx_RollsBackEverythingAppliedWithinIt(t *testing.T) {
+	driver := os.Getenv("BOLT_DB_DRIVER")
+	if !storage.SupportsTransactionalDDL(driver) {
+		t.Skipf("%s doesn't roll back DDL statements", driver)
+	}
+	testdb := bolttest.NewTestDB(t)
+	repo, err := repositories.NewMigrationDBRepo("bolt_migrations", testdb)
+	assert.Nil(t, err)
+	firstMigration := models.NewSequentialMigration(1, "first")
+	secondMigration := models.NewSequentialMigration(2, "second")
+
+	err = repo.Tx(func(repo repositories.MigrationDBRepo) error {
+		err := repo.Apply(`CREATE TABLE tmp(id INT NOT NULL PRIMARY KEY)`, firstMigration)
+		if err != nil {
+			return err
+		}
+		return repo.Apply("SELECT 1 FROM abc123donotexist;", secondMigration)
+	})
+	assert.ErrorContains(t, err, "unable to execute upgrade script")
+
+	exists, err := testdb.TableExists("tmp")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 0)
+}
@@ -31,10 +31,19 @@ type MigrationFsRepo interface {
 	List() (map[string]*models.Migration, error)
 	ReadUpgradeScript(migration *models.Migration) (sqlparse.MigrationScript, error)
 	ReadDowngradeScript(migration *models.Migration) (sqlparse.MigrationScript, error)
+	Write(
+		migration *models.Migration,
+		upgradeScript sqlparse.MigrationScript,
+		downgradeScript sqlparse.MigrationScript,
+	) error
+	Archive(migration *models.Migration, archiveDirPath string) error
+	Restore(migration *models.Migration, archiveDirPath string) error
+	Remove(migration *models.Migration) error
 }
 
 type migrationFsRepo struct {
 	migrationsDirPath string
+	layout            configloader.MigrationLayout
 }
 
 func NewMigrationFsRepo(
@@ -60,51 +69,317 @@ func NewMigrationFsRepo(
 		return nil, &ErrIsNotDir{path: migrationsConfig.DirectoryPath}
 	}
 
-	return &migrationFsRepo{migrationsDirPath: migrationsConfig.DirectoryPath}, nil
+	layout := migrationsConfig.Layout
+	if layout == "" {
+		layout = configloader.MigrationLayoutSingle
+	}
+	if !layout.Valid() {
+		return nil, configloader.ErrInvalidMigrationLayout
+	}
+
+	return &migrationFsRepo{
+		migrationsDirPath: migrationsConfig.DirectoryPath,
+		layout:            layout,
+	}, nil
+}
+
+const (
+	singleFileExt     = ".sql"
+	upgradeFileExt    = ".up.sql"
+	downgradeFileExt  = ".down.sql"
+	upgradeFileName   = "up.sql"
+	downgradeFileName = "down.sql"
+)
+
+// migrationFiles are the paths to the files that make up a
+// migration on the filesystem. When the migration is in the
+// single file layout, the upgrade and downgrade paths are the same.
+type migrationFiles struct {
+	layout        configloader.MigrationLayout
+	upgradePath   string
+	downgradePath string
+}
+
+func (mr migrationFsRepo) filesForLayout(
+	migration *models.Migration,
+	layout configloader.MigrationLayout,
+) migrationFiles {
+	basePath := filepath.Join(mr.migrationsDirPath, migration.Name())
+	switch layout {
+	case configloader.MigrationLayoutSplit:
+		return migrationFiles{
+			layout:        layout,
+			upgradePath:   basePath + upgradeFileExt,
+			downgradePath: basePath + downgradeFileExt,
+		}
+	case configloader.MigrationLayoutDirectory:
+		return migrationFiles{
+			layout:        layout,
+			upgradePath:   filepath.Join(basePath, upgradeFileName),
+			downgradePath: filepath.Join(basePath, downgradeFileName),
+		}
+	default:
+		return migrationFiles{
+			layout:        configloader.MigrationLayoutSingle,
+			upgradePath:   basePath + singleFileExt,
+			downgradePath: basePath + singleFileExt,
+		}
+	}
+}
+
+// locate finds the files for an existing migration in whichever
+// layout it was written in. If the migration can't be found in any
+// layout, the files for the configured layout are returned.
+func (mr migrationFsRepo) locate(migration *models.Migration) migrationFiles {
+	for _, layout := range []configloader.MigrationLayout{
+		configloader.MigrationLayoutSingle,
+		configloader.MigrationLayoutSplit,
+		configloader.MigrationLayoutDirectory,
+	} {
+		files := mr.filesForLayout(migration, layout)
+		if _, err := os.Stat(files.upgradePath); err == nil {
+			return files
+		}
+	}
+
+	return mr.filesForLayout(migration, mr.layout)
 }
 
 func (mr migrationFsRepo) Create(migration *models.Migration) error {
-	newMigrationPath := filepath.Join(mr.migrationsDirPath, migration.Name()+".sql")
-	file, err := os.Create(newMigrationPath)
+	return mr.Write(
+		migration,
+		sqlparse.NewMigrationScript(""),
+		sqlparse.NewMigrationScript(""),
+	)
+}
+
+// Write creates a migration in the configured layout with
+// the given upgrade and downgrade scripts.
+func (mr migrationFsRepo) Write(
+	migration *models.Migration,
+	upgradeScript sqlparse.MigrationScript,
+	downgradeScript sqlparse.MigrationScript,
+) error {
+	files := mr.filesForLayout(migration, mr.layout)
+
+	if files.layout == configloader.MigrationLayoutDirectory {
+		dirPath := filepath.Dir(files.upgradePath)
+		err := os.Mkdir(dirPath, 0755)
+		if err != nil {
+			return fmt.Errorf("unable to create directory at %s: %w", dirPath, err)
+		}
+	}
+
+	if files.layout == configloader.MigrationLayoutSingle {
+		return createScriptFile(
+			files.upgradePath,
+			sqlparse.FormatUpgradeScript(upgradeScript)+"\n"+
+				sqlparse.FormatDowngradeScript(downgradeScript),
+		)
+	}
+
+	err := createScriptFile(files.upgradePath, sqlparse.FormatUpgradeScript(upgradeScript))
+	if err != nil {
+		return err
+	}
+	return createScriptFile(files.downgradePath, sqlparse.FormatDowngradeScript(downgradeScript))
+}
+
+// Archive moves the files of an existing migration out of the
+// migrations directory and into the archive directory, creating
+// the archive directory if it doesn't exist.
+func (mr migrationFsRepo) Archive(
+	migration *models.Migration,
+	archiveDirPath string,
+) error {
+	files := mr.locate(migration)
+	paths := []string{files.upgradePath}
+	switch files.layout {
+	case configloader.MigrationLayoutDirectory:
+		paths = []string{filepath.Dir(files.upgradePath)}
+	case configloader.MigrationLayoutSplit:
+		if _, err := os.Stat(files.downgradePath); err == nil {
+			paths = append(paths, files.downgradePath)
+		}
+	}
+
+	err := os.MkdirAll(archiveDirPath, 0755)
+	if err != nil {
+		return fmt.Errorf(
+			"unable to create archive directory at %s: %w",
+			archiveDirPath,
+			err,
+		)
+	}
+
+	for _, path := range paths {
+		archivePath := filepath.Join(archiveDirPath, filepath.Base(path))
+		if _, err := os.Stat(archivePath); err == nil {
+			return fmt.Errorf("unable to archive %s: %s already exists", path, archivePath)
+		}
+
+		err = os.Rename(path, archivePath)
+		if err != nil {
+			return fmt.Errorf("unable to archive %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// Restore moves the files of a migration that was archived
+// back into the migrations directory.
+func (mr migrationFsRepo) Restore(
+	migration *models.Migration,
+	archiveDirPath string,
+) error {
+	restored := false
+	for _, name := range []string{
+		migration.Name() + singleFileExt,
+		migration.Name() + upgradeFileExt,
+		migration.Name() + downgradeFileExt,
+		migration.Name(),
+	} {
+		archivePath := filepath.Join(archiveDirPath, name)
+		if _, err := os.Stat(archivePath); err != nil {
+			continue
+		}
+
+		path := filepath.Join(mr.migrationsDirPath, name)
+		err := os.Rename(archivePath, path)
+		if err != nil {
+			return fmt.Errorf("unable to restore %s: %w", archivePath, err)
+		}
+		restored = true
+	}
+
+	if !restored {
+		return fmt.Errorf(
+			"unable to restore %s: it isn't in %s",
+			migration.Name(),
+			archiveDirPath,
+		)
+	}
+	return nil
+}
+
+// Remove deletes the files of an existing migration.
+func (mr migrationFsRepo) Remove(migration *models.Migration) error {
+	files := mr.locate(migration)
+	paths := []string{files.upgradePath}
+	switch files.layout {
+	case configloader.MigrationLayoutDirectory:
+		paths = []string{filepath.Dir(files.upgradePath)}
+	case configloader.MigrationLayoutSplit:
+		paths = append(paths, files.downgradePath)
+	}
+
+	for _, path := range paths {
+		err := os.RemoveAll(path)
+		if err != nil {
+			return fmt.Errorf("unable to remove %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+const (
+	upgradeSectionComment   = "-- migrate:up\n"
+	downgradeSectionComment = "-- migrate:down\n"
+)
+
+func createScriptFile(path string, contents string) error {
+	file, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("unable to create file at %s: %w", newMigrationPath, err)
+		return fmt.Errorf("unable to create file at %s: %w", path, err)
 	}
-	_, err = file.WriteString("-- migrate:up\n\n-- migrate:down\n")
+	defer file.Close()
+
+	_, err = file.WriteString(contents)
 	if err != nil {
-		return fmt.Errorf("unable to write template to new migration script: %w", err)
+		return fmt.Errorf("unable to write migration script to %s: %w", path, err)
 	}
 	return nil
 }
 
+// localMigration is a migration found while listing out the
+// migrations directory along with what we've found of it so far.
+type localMigration struct {
+	migration    *models.Migration
+	entryName    string
+	hasUpgrade   bool
+	hasDowngrade bool
+}
+
 func (mr migrationFsRepo) List() (map[string]*models.Migration, error) {
 	entries, err := os.ReadDir(mr.migrationsDirPath)
 	if err != nil {
 		return nil, err
 	}
 
-	var migrations = make(map[string]*models.Migration, 0)
+	var localMigrations = make(map[string]*localMigration, 0)
 	for _, entry := range entries {
-		migration, err := dirEntryToMigration(entry)
+		found, err := dirEntryToLocalMigration(entry)
 		if err != nil {
 			return nil, err
 		}
-		_, exists := migrations[migration.Version]
-		if exists {
+
+		existing, exists := localMigrations[found.migration.Version]
+		if !exists {
+			localMigrations[found.migration.Version] = found
+			continue
+		}
+
+		// The split layout is the only one where multiple entries make
+		// up the same migration. Anything else is a conflict.
+		isSplitPair := existing.entryName == found.entryName &&
+			existing.hasUpgrade != found.hasUpgrade &&
+			existing.hasDowngrade != found.hasDowngrade
+		if !isSplitPair {
 			return nil, fmt.Errorf(
 				"%w: a local migration file with version %s already exists",
 				ErrMigrationVersionConflict,
-				migration.Version,
+				found.migration.Version,
 			)
 		}
+		existing.hasUpgrade = true
+		existing.hasDowngrade = true
+	}
 
-		migrations[migration.Version] = migration
+	var migrations = make(map[string]*models.Migration, len(localMigrations))
+	for version, local := range localMigrations {
+		if !local.hasUpgrade {
+			return nil, fmt.Errorf(
+				"%s has a downgrade script but no %s upgrade script",
+				local.entryName+downgradeFileExt,
+				local.entryName+upgradeFileExt,
+			)
+		}
+		migrations[version] = local.migration
 	}
 
 	return migrations, nil
 }
 
-func dirEntryToMigration(entry fs.DirEntry) (*models.Migration, error) {
-	parts := strings.SplitN(entry.Name(), "_", 2)
+func dirEntryToLocalMigration(entry fs.DirEntry) (*localMigration, error) {
+	name := entry.Name()
+	hasUpgrade := true
+	hasDowngrade := true
+	if !entry.IsDir() {
+		switch {
+		case strings.HasSuffix(name, upgradeFileExt):
+			name = strings.TrimSuffix(name, upgradeFileExt)
+			hasDowngrade = false
+		case strings.HasSuffix(name, downgradeFileExt):
+			name = strings.TrimSuffix(name, downgradeFileExt)
+			hasUpgrade = false
+		default:
+			name = strings.TrimSuffix(name, filepath.Ext(name)) // Remove .sql
+		}
+	}
+
+	parts := strings.SplitN(name, "_", 2)
 	if len(parts) != 2 {
 		return nil, fmt.Errorf(
 			"%s is an invalid migration name: expected a "+
@@ -112,34 +387,54 @@ func dirEntryToMigration(entry fs.DirEntry) (*models.Migration, error) {
 			entry.Name(),
 		)
 	}
-	version := parts[0]
-	message := strings.TrimSuffix(parts[1], filepath.Ext(parts[1])) // Remove .sql
-	return &models.Migration{
-		Version: version,
-		Message: message,
-		Applied: false,
+	return &localMigration{
+		migration: &models.Migration{
+			Version: parts[0],
+			Message: parts[1],
+			Applied: false,
+		},
+		entryName:    name,
+		hasUpgrade:   hasUpgrade,
+		hasDowngrade: hasDowngrade,
 	}, nil
 }
 
 func (mr migrationFsRepo) ReadUpgradeScript(
 	migration *models.Migration,
 ) (sqlparse.MigrationScript, error) {
-	scriptPath := filepath.Join(
-		mr.migrationsDirPath,
-		migration.Name()+".sql",
+	files := mr.locate(migration)
+	if files.layout == configloader.MigrationLayoutSingle {
+		upgradeScript, _, err := mr.getMigrationScripts(files.upgradePath)
+		return upgradeScript, err
+	}
+
+	upgradeScript, _, err := mr.getSplitMigrationScripts(
+		files.upgradePath,
+		upgradeSectionComment,
 	)
-	upgradeScript, _, err := mr.getMigrationScripts(scriptPath)
 	return upgradeScript, err
 }
 
 func (mr migrationFsRepo) ReadDowngradeScript(
 	migration *models.Migration,
 ) (sqlparse.MigrationScript, error) {
-	scriptPath := filepath.Join(
-		mr.migrationsDirPath,
-		migration.Name()+".sql",
+	files := mr.locate(migration)
+	if files.layout == configloader.MigrationLayoutSingle {
+		_, downgradeScript, err := mr.getMigrationScripts(files.downgradePath)
+		return downgradeScript, err
+	}
+
+	// A missing downgrade file is treated the same as an
+	// empty downgrade section in a single file migration.
+	_, err := os.Stat(files.downgradePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sqlparse.MigrationScript{}, nil
+	}
+
+	_, downgradeScript, err := mr.getSplitMigrationScripts(
+		files.downgradePath,
+		downgradeSectionComment,
 	)
-	_, downgradeScript, err := mr.getMigrationScripts(scriptPath)
 	return downgradeScript, err
 }
 
@@ -158,6 +453,39 @@ func (mr migrationFsRepo) getMigrationScripts(
 	return sqlParser.Parse(strings.NewReader(scriptContents))
 }
 
+// getSplitMigrationScripts parses a script file that only holds one
+// side of a migration. The section comment is optional in these files
+// since other tools don't use them, so it is added when it is missing.
+func (mr migrationFsRepo) getSplitMigrationScripts(
+	scriptPath string,
+	sectionComment string,
+) (sqlparse.MigrationScript, sqlparse.MigrationScript, error) {
+	scriptContents, err := mr.readScriptContents(scriptPath)
+	if err != nil {
+		return sqlparse.MigrationScript{}, sqlparse.MigrationScript{}, fmt.Errorf(
+			"unable to read %s script: %w",
+			scriptPath,
+			err,
+		)
+	}
+	if !hasSectionComment(scriptContents, sectionComment) {
+		scriptContents = sectionComment + scriptContents
+	}
+	sqlParser := sqlparse.NewSqlParser()
+	return sqlParser.Parse(strings.NewReader(scriptContents))
+}
+
+func hasSectionComment(scriptContents string, sectionComment string) bool {
+	sectionComment = strings.TrimSpace(sectionComment)
+	for _, line := range strings.Split(scriptContents, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if strings.HasPrefix(line, sectionComment) {
+			return true
+		}
+	}
+	return false
+}
+
 func (mr migrationFsRepo) readScriptContents(scriptPath string) (string, error) {
 	contents, err := os.ReadFile(scriptPath)
 	if err != nil {
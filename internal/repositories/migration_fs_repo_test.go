@@ -10,6 +10,7 @@ import (
 	"github.com/eugenetriguba/bolt/internal/configloader"
 	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/sqlparse"
 	"github.com/eugenetriguba/checkmate/assert"
 )
 
@@ -231,3 +232,354 @@ func TestList_DuplicateMigrationVersion(t *testing.T) {
 		"a local migration file with version 001 already exists",
 	)
 }
+
+func TestNewMigrationFsRepo_InvalidLayout(t *testing.T) {
+	migrationsConfig := configloader.MigrationsConfig{
+		DirectoryPath: t.TempDir(),
+		Layout:        "invalid",
+	}
+
+	_, err := repositories.NewMigrationFsRepo(&migrationsConfig)
+
+	assert.ErrorIs(t, err, configloader.ErrInvalidMigrationLayout)
+}
+
+func TestCreate_SplitLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repositories.NewMigrationFsRepo(&configloader.MigrationsConfig{
+		DirectoryPath: tempDir,
+		Layout:        configloader.MigrationLayoutSplit,
+	})
+	assert.Nil(t, err)
+	migration := models.NewSequentialMigration(1, "add_users_table")
+
+	err = repo.Create(migration)
+	assert.Nil(t, err)
+
+	assertFileExists(t, filepath.Join(tempDir, migration.Name()+".up.sql"))
+	assertFileExists(t, filepath.Join(tempDir, migration.Name()+".down.sql"))
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 1)
+	assert.DeepEqual(t, migrations[migration.Version], migration)
+}
+
+func TestCreate_DirectoryLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repositories.NewMigrationFsRepo(&configloader.MigrationsConfig{
+		DirectoryPath: tempDir,
+		Layout:        configloader.MigrationLayoutDirectory,
+	})
+	assert.Nil(t, err)
+	migration := models.NewSequentialMigration(1, "add_users_table")
+
+	err = repo.Create(migration)
+	assert.Nil(t, err)
+
+	assertFileExists(t, filepath.Join(tempDir, migration.Name(), "up.sql"))
+	assertFileExists(t, filepath.Join(tempDir, migration.Name(), "down.sql"))
+	migrations, err := repo.List()
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), 1)
+	assert.DeepEqual(t, migrations[migration.Version], migration)
+}
+
+func TestReadScripts_SplitLayout(t *testing.T) {
+	type test struct {
+		upgradeFileContents     string
+		downgradeFileContents   *string
+		expectedUpgradeScript   sqlparse.MigrationScript
+		expectedDowngradeScript sqlparse.MigrationScript
+	}
+
+	downgradeWithComment := "-- migrate:down transaction:false\nDROP TABLE users;\n"
+	downgradeWithoutComment := "DROP TABLE users;\n"
+	tests := []test{
+		// Ensure files without section comments, like the ones
+		// other tools create, are read in whole.
+		{
+			upgradeFileContents:   "CREATE TABLE users(id int PRIMARY KEY);\n",
+			downgradeFileContents: &downgradeWithoutComment,
+			expectedUpgradeScript: sqlparse.MigrationScript{
+				Contents: "CREATE TABLE users(id int PRIMARY KEY);\n",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+			expectedDowngradeScript: sqlparse.MigrationScript{
+				Contents: "DROP TABLE users;\n",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+		},
+		// Ensure section comments can still be used for options.
+		{
+			upgradeFileContents:   "-- migrate:up transaction:false\nCREATE TABLE users(id int PRIMARY KEY);\n",
+			downgradeFileContents: &downgradeWithComment,
+			expectedUpgradeScript: sqlparse.MigrationScript{
+				Contents: "CREATE TABLE users(id int PRIMARY KEY);\n",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: false},
+			},
+			expectedDowngradeScript: sqlparse.MigrationScript{
+				Contents: "DROP TABLE users;\n",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: false},
+			},
+		},
+		// Ensure a missing downgrade file is an empty downgrade script.
+		{
+			upgradeFileContents:   "CREATE TABLE users(id int PRIMARY KEY);\n",
+			downgradeFileContents: nil,
+			expectedUpgradeScript: sqlparse.MigrationScript{
+				Contents: "CREATE TABLE users(id int PRIMARY KEY);\n",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+			expectedDowngradeScript: sqlparse.MigrationScript{},
+		},
+	}
+
+	for _, tc := range tests {
+		tempDir := t.TempDir()
+		repo, err := repositories.NewMigrationFsRepo(
+			&configloader.MigrationsConfig{DirectoryPath: tempDir},
+		)
+		assert.Nil(t, err)
+		migration := models.NewSequentialMigration(1, "add users table")
+		err = os.WriteFile(
+			filepath.Join(tempDir, migration.Name()+".up.sql"),
+			[]byte(tc.upgradeFileContents),
+			0644,
+		)
+		assert.Nil(t, err)
+		if tc.downgradeFileContents != nil {
+			err = os.WriteFile(
+				filepath.Join(tempDir, migration.Name()+".down.sql"),
+				[]byte(*tc.downgradeFileContents),
+				0644,
+			)
+			assert.Nil(t, err)
+		}
+
+		upgradeScript, err := repo.ReadUpgradeScript(migration)
+		assert.Nil(t, err)
+		downgradeScript, err := repo.ReadDowngradeScript(migration)
+		assert.Nil(t, err)
+
+		assert.DeepEqual(t, upgradeScript, tc.expectedUpgradeScript)
+		assert.DeepEqual(t, downgradeScript, tc.expectedDowngradeScript)
+	}
+}
+
+func TestReadScripts_DirectoryLayout(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repositories.NewMigrationFsRepo(
+		&configloader.MigrationsConfig{DirectoryPath: tempDir},
+	)
+	assert.Nil(t, err)
+	migration := models.NewSequentialMigration(1, "add users table")
+	err = os.Mkdir(filepath.Join(tempDir, migration.Name()), 0755)
+	assert.Nil(t, err)
+	err = os.WriteFile(
+		filepath.Join(tempDir, migration.Name(), "up.sql"),
+		[]byte("CREATE TABLE users(id int PRIMARY KEY);\n"),
+		0644,
+	)
+	assert.Nil(t, err)
+	err = os.WriteFile(
+		filepath.Join(tempDir, migration.Name(), "down.sql"),
+		[]byte("DROP TABLE users;\n"),
+		0644,
+	)
+	assert.Nil(t, err)
+
+	upgradeScript, err := repo.ReadUpgradeScript(migration)
+	assert.Nil(t, err)
+	downgradeScript, err := repo.ReadDowngradeScript(migration)
+	assert.Nil(t, err)
+
+	assert.Equal(t, upgradeScript.Contents, "CREATE TABLE users(id int PRIMARY KEY);\n")
+	assert.Equal(t, downgradeScript.Contents, "DROP TABLE users;\n")
+}
+
+func TestList_MixedLayouts(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repositories.NewMigrationFsRepo(
+		&configloader.MigrationsConfig{DirectoryPath: tempDir},
+	)
+	assert.Nil(t, err)
+	for _, path := range []string{
+		"001_single.sql",
+		"002_split.up.sql",
+		"002_split.down.sql",
+		"003_split_up_only.up.sql",
+		filepath.Join("004_directory", "up.sql"),
+	} {
+		err = os.MkdirAll(filepath.Join(tempDir, filepath.Dir(path)), 0755)
+		assert.Nil(t, err)
+		err = os.WriteFile(filepath.Join(tempDir, path), []byte(""), 0644)
+		assert.Nil(t, err)
+	}
+
+	migrations, err := repo.List()
+
+	assert.Nil(t, err)
+	assert.DeepEqual(t, migrations, map[string]*models.Migration{
+		"001": {Version: "001", Message: "single"},
+		"002": {Version: "002", Message: "split"},
+		"003": {Version: "003", Message: "split_up_only"},
+		"004": {Version: "004", Message: "directory"},
+	})
+}
+
+func TestList_DowngradeFileWithoutUpgradeFile(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repositories.NewMigrationFsRepo(
+		&configloader.MigrationsConfig{DirectoryPath: tempDir},
+	)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "001_split.down.sql"), []byte(""), 0644)
+	assert.Nil(t, err)
+
+	_, err = repo.List()
+
+	assert.ErrorContains(t, err, "001_split.down.sql has a downgrade script but no")
+}
+
+func TestList_DuplicateMigrationVersionAcrossLayouts(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := repositories.NewMigrationFsRepo(
+		&configloader.MigrationsConfig{DirectoryPath: tempDir},
+	)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "001_migration.sql"), []byte(""), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "001_migration.up.sql"), []byte(""), 0644)
+	assert.Nil(t, err)
+
+	_, err = repo.List()
+
+	assert.ErrorIs(t, err, repositories.ErrMigrationVersionConflict)
+}
+
+func TestArchive_MovesMigrationFilesInEachLayout(t *testing.T) {
+	layouts := []configloader.MigrationLayout{
+		configloader.MigrationLayoutSingle,
+		configloader.MigrationLayoutSplit,
+		configloader.MigrationLayoutDirectory,
+	}
+
+	for _, layout := range layouts {
+		migrationsDir := t.TempDir()
+		archiveDir := filepath.Join(t.TempDir(), "archive")
+		repo, err := repositories.NewMigrationFsRepo(
+			&configloader.MigrationsConfig{DirectoryPath: migrationsDir, Layout: layout},
+		)
+		assert.Nil(t, err)
+		migration := models.NewSequentialMigration(1, "add_users_table")
+		err = repo.Create(migration)
+		assert.Nil(t, err)
+
+		err = repo.Archive(migration, archiveDir)
+		assert.Nil(t, err)
+
+		migrations, err := repo.List()
+		assert.Nil(t, err)
+		assert.Equal(t, len(migrations), 0)
+
+		archiveRepo, err := repositories.NewMigrationFsRepo(
+			&configloader.MigrationsConfig{DirectoryPath: archiveDir},
+		)
+		assert.Nil(t, err)
+		archivedMigrations, err := archiveRepo.List()
+		assert.Nil(t, err)
+		assert.DeepEqual(
+			t,
+			archivedMigrations,
+			map[string]*models.Migration{"001": migration},
+		)
+	}
+}
+
+func TestArchive_AlreadyArchived(t *testing.T) {
+	migrationsDir := t.TempDir()
+	archiveDir := t.TempDir()
+	repo, err := repositories.NewMigrationFsRepo(
+		&configloader.MigrationsConfig{DirectoryPath: migrationsDir},
+	)
+	assert.Nil(t, err)
+	migration := models.NewSequentialMigration(1, "add_users_table")
+	err = repo.Create(migration)
+	assert.Nil(t, err)
+	_, err = os.Create(filepath.Join(archiveDir, migration.Name()+".sql"))
+	assert.Nil(t, err)
+
+	err = repo.Archive(migration, archiveDir)
+
+	assert.ErrorContains(t, err, "already exists")
+	assertFileExists(t, filepath.Join(migrationsDir, migration.Name()+".sql"))
+}
+
+func TestRestore_MovesArchivedFilesBackInEachLayout(t *testing.T) {
+	layouts := []configloader.MigrationLayout{
+		configloader.MigrationLayoutSingle,
+		configloader.MigrationLayoutSplit,
+		configloader.MigrationLayoutDirectory,
+	}
+
+	for _, layout := range layouts {
+		migrationsDir := t.TempDir()
+		archiveDir := filepath.Join(t.TempDir(), "archive")
+		repo, err := repositories.NewMigrationFsRepo(
+			&configloader.MigrationsConfig{DirectoryPath: migrationsDir, Layout: layout},
+		)
+		assert.Nil(t, err)
+		migration := models.NewSequentialMigration(1, "add_users_table")
+		err = repo.Create(migration)
+		assert.Nil(t, err)
+		err = repo.Archive(migration, archiveDir)
+		assert.Nil(t, err)
+
+		err = repo.Restore(migration, archiveDir)
+		assert.Nil(t, err)
+
+		migrations, err := repo.List()
+		assert.Nil(t, err)
+		assert.DeepEqual(t, migrations, map[string]*models.Migration{"001": migration})
+		archivedEntries, err := os.ReadDir(archiveDir)
+		assert.Nil(t, err)
+		assert.Equal(t, len(archivedEntries), 0)
+	}
+}
+
+func TestRestore_NotArchived(t *testing.T) {
+	repo, err := repositories.NewMigrationFsRepo(
+		&configloader.MigrationsConfig{DirectoryPath: t.TempDir()},
+	)
+	assert.Nil(t, err)
+
+	err = repo.Restore(models.NewSequentialMigration(1, "add_users_table"), t.TempDir())
+
+	assert.ErrorContains(t, err, "unable to restore 001_add_users_table")
+}
+
+func TestRemove_DeletesMigrationFilesInEachLayout(t *testing.T) {
+	layouts := []configloader.MigrationLayout{
+		configloader.MigrationLayoutSingle,
+		configloader.MigrationLayoutSplit,
+		configloader.MigrationLayoutDirectory,
+	}
+
+	for _, layout := range layouts {
+		migrationsDir := t.TempDir()
+		repo, err := repositories.NewMigrationFsRepo(
+			&configloader.MigrationsConfig{DirectoryPath: migrationsDir, Layout: layout},
+		)
+		assert.Nil(t, err)
+		migration := models.NewSequentialMigration(1, "add_users_table")
+		err = repo.Create(migration)
+		assert.Nil(t, err)
+
+		err = repo.Remove(migration)
+		assert.Nil(t, err)
+
+		entries, err := os.ReadDir(migrationsDir)
+		assert.Nil(t, err)
+		assert.Equal(t, len(entries), 0)
+	}
+}
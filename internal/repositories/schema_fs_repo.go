@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type SchemaFsRepo interface {
+	Write(schema string) error
+	Read() (string, error)
+}
+
+type schemaFsRepo struct {
+	schemaFilePath string
+}
+
+// NewSchemaFsRepo initializes the SchemaFsRepo with the
+// path to the schema file it reads and writes.
+func NewSchemaFsRepo(schemaFilePath string) SchemaFsRepo {
+	return &schemaFsRepo{schemaFilePath: schemaFilePath}
+}
+
+// Write replaces the contents of the schema file with the
+// schema, creating the file and its directory if needed.
+func (sr schemaFsRepo) Write(schema string) error {
+	dirPath := filepath.Dir(sr.schemaFilePath)
+	err := os.MkdirAll(dirPath, 0755)
+	if err != nil {
+		return fmt.Errorf("unable to create directory at %s: %w", dirPath, err)
+	}
+
+	err = os.WriteFile(sr.schemaFilePath, []byte(schema), 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write schema to %s: %w", sr.schemaFilePath, err)
+	}
+
+	return nil
+}
+
+// Read retrieves the contents of the schema file.
+func (sr schemaFsRepo) Read() (string, error) {
+	contents, err := os.ReadFile(sr.schemaFilePath)
+	if err != nil {
+		return "", fmt.Errorf("unable to read schema from %s: %w", sr.schemaFilePath, err)
+	}
+
+	return string(contents), nil
+}
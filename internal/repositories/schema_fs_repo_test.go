@@ -0,0 +1,44 @@
+package repositories_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/checkmate/assert"
+)
+
+func TestSchemaFsRepo_WriteThenRead(t *testing.T) {
+	schemaFilePath := filepath.Join(t.TempDir(), "db", "schema.sql")
+	repo := repositories.NewSchemaFsRepo(schemaFilePath)
+
+	err := repo.Write("CREATE TABLE users(id INT);\n")
+	assert.Nil(t, err)
+
+	contents, err := repo.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, contents, "CREATE TABLE users(id INT);\n")
+}
+
+func TestSchemaFsRepo_WriteReplacesContents(t *testing.T) {
+	schemaFilePath := filepath.Join(t.TempDir(), "schema.sql")
+	err := os.WriteFile(schemaFilePath, []byte("CREATE TABLE old(id INT);\n"), 0644)
+	assert.Nil(t, err)
+	repo := repositories.NewSchemaFsRepo(schemaFilePath)
+
+	err = repo.Write("CREATE TABLE new(id INT);\n")
+	assert.Nil(t, err)
+
+	contents, err := os.ReadFile(schemaFilePath)
+	assert.Nil(t, err)
+	assert.Equal(t, string(contents), "CREATE TABLE new(id INT);\n")
+}
+
+func TestSchemaFsRepo_ReadMissingFile(t *testing.T) {
+	repo := repositories.NewSchemaFsRepo(filepath.Join(t.TempDir(), "schema.sql"))
+
+	_, err := repo.Read()
+
+	assert.ErrorContains(t, err, "unable to read schema")
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eugenetriguba/bolt/internal/storage"
+)
+
+// ListTenants discovers the tenants by running the query against
+// the database. The first column of each row it returns is a
+// tenant, in the order they're returned.
+func ListTenants(db storage.DB, query string) ([]string, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute query to discover tenants: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the columns of the tenants query: %w", err)
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("the tenants query doesn't return any columns")
+	}
+
+	tenants := make([]string, 0)
+	for rows.Next() {
+		var tenant sql.NullString
+		dest := make([]any, len(columns))
+		dest[0] = &tenant
+		for i := 1; i < len(dest); i++ {
+			dest[i] = new(any)
+		}
+		err = rows.Scan(dest...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan tenant row: %w", err)
+		}
+		if !tenant.Valid {
+			return nil, errors.New("the tenants query returned a NULL tenant")
+		}
+		tenants = append(tenants, strings.TrimSpace(tenant.String))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read tenant rows: %w", err)
+	}
+
+	return tenants, nil
+}
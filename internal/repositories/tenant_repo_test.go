@@ -0,0 +1,50 @@
+package repositories_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/bolttest"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/checkmate/assert"
+)
+
+func TestListTenants(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	_, err := testdb.Exec("CREATE TABLE tmp(name VARCHAR(255), active INT);")
+	assert.Nil(t, err)
+	_, err = testdb.Exec(
+		"INSERT INTO tmp(name, active) VALUES ('globex', 1), ('acme', 1), ('initech', 0);",
+	)
+	assert.Nil(t, err)
+
+	tenants, err := repositories.ListTenants(
+		testdb,
+		"SELECT name, active FROM tmp WHERE active = 1 ORDER BY name;",
+	)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, tenants, []string{"acme", "globex"})
+}
+
+func TestListTenants_NullTenant(t *testing.T) {
+	testdb := bolttest.NewTestDB(t)
+	_, err := testdb.Exec("CREATE TABLE tmp(name VARCHAR(255));")
+	assert.Nil(t, err)
+	_, err = testdb.Exec("INSERT INTO tmp(name) VALUES (NULL);")
+	assert.Nil(t, err)
+
+	_, err = repositories.ListTenants(testdb, "SELECT name FROM tmp;")
+	assert.ErrorContains(t, err, "the tenants query returned a NULL tenant")
+}
+
+func TestListTenants_QueryError(t *testing.T) {
+	mockDB := &bolttest.MockDB{
+		QueryFunc: func(query string, args ...interface{}) (*sql.Rows, error) {
+			return nil, errors.New("query error")
+		},
+	}
+
+	_, err := repositories.ListTenants(mockDB, "SELECT name FROM tenants;")
+	assert.ErrorContains(t, err, "unable to execute query to discover tenants: query error")
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/importer"
+	"github.com/eugenetriguba/bolt/internal/models"
+)
+
+// ImportMigrations writes the migrations imported from another tool
+// into the local migrations directory and marks the applied versions
+// as applied in the database without running them.
+//
+// Importing is safe to run more than once. Migrations that already
+// exist locally with the same message are left alone and versions
+// that are already marked as applied are skipped.
+func (ms MigrationService) ImportMigrations(
+	migrations []importer.Migration,
+	appliedVersions []string,
+) error {
+	for _, migration := range migrations {
+		err := ms.validateImportedVersion(migration.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	localMigrations, err := ms.fsRepo.List()
+	if err != nil {
+		return fmt.Errorf("unable to list out local filesystem migrations: %w", err)
+	}
+
+	appliedMigrations, err := ms.dbRepo.List()
+	if err != nil {
+		return fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+
+	for _, importedMigration := range migrations {
+		migration := &models.Migration{
+			Version: importedMigration.Version,
+			Message: importedMigration.Message,
+		}
+
+		localMigration, ok := localMigrations[migration.Version]
+		if ok {
+			if localMigration.NormalizedMessage() != migration.NormalizedMessage() {
+				return fmt.Errorf(
+					"unable to import migration %s: migration %s already "+
+						"exists locally with the same version",
+					migration.Name(),
+					localMigration.Name(),
+				)
+			}
+			continue
+		}
+
+		err = ms.fsRepo.Write(
+			migration,
+			importedMigration.UpgradeScript,
+			importedMigration.DowngradeScript,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to import migration %s: %w", migration.Name(), err)
+		}
+		localMigrations[migration.Version] = migration
+		ms.outputter.Output(fmt.Sprintf("Imported migration %s.", migration.Name()))
+	}
+
+	for _, version := range appliedVersions {
+		if _, ok := appliedMigrations[version]; ok {
+			continue
+		}
+
+		migration, ok := localMigrations[version]
+		if !ok {
+			return fmt.Errorf(
+				"migration with version %s is applied but does not exist locally",
+				version,
+			)
+		}
+
+		err = ms.markApplied(migration)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateImportedVersion checks that the version can be sorted
+// alongside the other migrations with the configured version style.
+func (ms MigrationService) validateImportedVersion(version string) error {
+	var err error
+	if ms.cfg.Migrations.VersionStyle == configloader.VersionStyleTimestamp {
+		_, err = time.Parse("20060102150405", version)
+	} else {
+		_, err = strconv.ParseUint(version, 10, 64)
+	}
+	if err != nil {
+		return fmt.Errorf(
+			"unable to import migration with version %s: it doesn't match "+
+				"the configured %s version style",
+			version,
+			ms.cfg.Migrations.VersionStyle,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/bolttest"
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/importer"
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func newImportService(
+	dbRepo *bolttest.MockMigrationDBRepo,
+	fsRepo *bolttest.MockMigrationFsRepo,
+	versionStyle configloader.VersionStyle,
+) MigrationService {
+	return NewMigrationService(
+		dbRepo,
+		fsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{VersionStyle: versionStyle},
+		},
+		bolttest.NullOutputter{},
+	)
+}
+
+func TestImportMigrations_WritesAndMarksApplied(t *testing.T) {
+	dbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	fsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	svc := newImportService(dbRepo, fsRepo, configloader.VersionStyleSequential)
+
+	err := svc.ImportMigrations(
+		[]importer.Migration{
+			{Version: "001", Message: "create_users"},
+			{Version: "002", Message: "add_index"},
+		},
+		[]string{"001"},
+	)
+
+	assert.Nil(t, err)
+	check.Equal(t, fsRepo.WriteCallCount, 2)
+	check.Equal(t, dbRepo.MarkAppliedCallCount, 1)
+}
+
+func TestImportMigrations_SkipsExistingMigrations(t *testing.T) {
+	dbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "create_users", Applied: true},
+			},
+		},
+	}
+	fsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "create_users"},
+			},
+		},
+	}
+	svc := newImportService(dbRepo, fsRepo, configloader.VersionStyleSequential)
+
+	err := svc.ImportMigrations(
+		[]importer.Migration{{Version: "001", Message: "create_users"}},
+		[]string{"001"},
+	)
+
+	assert.Nil(t, err)
+	check.Equal(t, fsRepo.WriteCallCount, 0)
+	check.Equal(t, dbRepo.MarkAppliedCallCount, 0)
+}
+
+func TestImportMigrations_ConflictingLocalMigration(t *testing.T) {
+	dbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	fsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "something_else"},
+			},
+		},
+	}
+	svc := newImportService(dbRepo, fsRepo, configloader.VersionStyleSequential)
+
+	err := svc.ImportMigrations(
+		[]importer.Migration{{Version: "001", Message: "create_users"}},
+		[]string{},
+	)
+
+	check.NotNil(t, err)
+	check.Equal(t, fsRepo.WriteCallCount, 0)
+}
+
+func TestImportMigrations_AppliedVersionMissingLocally(t *testing.T) {
+	dbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	fsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	svc := newImportService(dbRepo, fsRepo, configloader.VersionStyleSequential)
+
+	err := svc.ImportMigrations([]importer.Migration{}, []string{"001"})
+
+	check.NotNil(t, err)
+	check.Equal(t, dbRepo.MarkAppliedCallCount, 0)
+}
+
+func TestImportMigrations_VersionStyleMismatch(t *testing.T) {
+	dbRepo := &bolttest.MockMigrationDBRepo{}
+	fsRepo := &bolttest.MockMigrationFsRepo{}
+	svc := newImportService(dbRepo, fsRepo, configloader.VersionStyleTimestamp)
+
+	err := svc.ImportMigrations(
+		[]importer.Migration{{Version: "001", Message: "create_users"}},
+		[]string{},
+	)
+
+	check.NotNil(t, err)
+	check.Equal(t, fsRepo.ListCallCount, 0)
+}
+
+func TestImportMigrations_WriteErr(t *testing.T) {
+	expectedErr := errors.New("write failed")
+	dbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	fsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+		WriteReturnValue: bolttest.WriteReturnValue{Err: expectedErr},
+	}
+	svc := newImportService(dbRepo, fsRepo, configloader.VersionStyleSequential)
+
+	err := svc.ImportMigrations(
+		[]importer.Migration{{Version: "001", Message: "create_users"}},
+		[]string{"001"},
+	)
+
+	check.ErrorIs(t, err, expectedErr)
+	check.Equal(t, dbRepo.MarkAppliedCallCount, 0)
+}
@@ -1,15 +1,27 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
 	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/bolt/internal/output"
 	"github.com/eugenetriguba/bolt/internal/repositories"
+	"github.com/eugenetriguba/bolt/internal/sqlparse"
+)
+
+var (
+	ErrIrreversibleMigration     = errors.New("migration is irreversible")
+	ErrDirtyMigration            = errors.New("migration is dirty")
+	ErrNonTransactionalMigration = errors.New(
+		"migration is marked transaction:false and can't be applied in a single transaction",
+	)
 )
 
 type MigrationService struct {
@@ -33,6 +45,77 @@ func NewMigrationService(
 	}
 }
 
+// ApplyOptions customizes how migrations are applied.
+type ApplyOptions struct {
+	// Atomic applies every migration within a single transaction,
+	// so either all of them are applied or none are.
+	Atomic bool
+	// RollbackOnFailure reverts the migrations applied before a
+	// migration that fails to apply, in reverse order, using
+	// their downgrade scripts.
+	RollbackOnFailure bool
+}
+
+// RollbackError is returned when a migration fails to apply and
+// the migrations applied before it were rolled back.
+type RollbackError struct {
+	// Err is why the migration failed to apply.
+	Err error
+	// RolledBack are the migrations that were reverted,
+	// in the order they were reverted.
+	RolledBack []*models.Migration
+	// NotRolledBack are the migrations that are still applied, in
+	// the order they were applied, because the last one of them
+	// couldn't be reverted. The others were skipped since they
+	// were applied before it.
+	NotRolledBack []*models.Migration
+	// RollbackErr is why the last of the NotRolledBack
+	// migrations couldn't be reverted.
+	RollbackErr error
+}
+
+func (e *RollbackError) Error() string {
+	message := e.Err.Error()
+	if len(e.RolledBack) > 0 {
+		message += fmt.Sprintf("; rolled back %s", migrationNames(e.RolledBack))
+	}
+	if len(e.NotRolledBack) == 0 {
+		return message
+	}
+
+	failed := e.NotRolledBack[len(e.NotRolledBack)-1]
+	message += fmt.Sprintf("; could not roll back %s: %v", failed.Name(), e.RollbackErr)
+	if skipped := e.NotRolledBack[:len(e.NotRolledBack)-1]; len(skipped) > 0 {
+		message += fmt.Sprintf(
+			"; skipped rolling back %s since %s is still applied",
+			migrationNames(skipped),
+			failed.Name(),
+		)
+	}
+	return message
+}
+
+func (e *RollbackError) Unwrap() []error {
+	if e.RollbackErr == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.RollbackErr}
+}
+
+func migrationNames(migrations []*models.Migration) string {
+	names := make([]string, len(migrations))
+	for i, migration := range migrations {
+		names[i] = migration.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// RevertOptions customizes how migrations are reverted.
+type RevertOptions struct {
+	// Force reverts migrations even if they're irreversible.
+	Force bool
+}
+
 type sortOrder int
 
 const (
@@ -40,33 +123,35 @@ const (
 	SortOrderAsc
 )
 
-func (ms MigrationService) ApplyAllMigrations() error {
+func (ms MigrationService) ApplyAllMigrations(opts ApplyOptions) error {
 	migrations, err := ms.ListMigrations(SortOrderAsc)
 	if err != nil {
 		return err
 	}
+	err = checkNotDirty(migrations)
+	if err != nil {
+		return err
+	}
 
+	pendingMigrations := make([]*models.Migration, 0, len(migrations))
 	for _, migration := range migrations {
 		if !migration.Applied {
-			err := ms.ApplyMigration(migration)
-			if err != nil {
-				return fmt.Errorf(
-					"unable to apply migration %s: %w",
-					migration.Name(),
-					err,
-				)
-			}
+			pendingMigrations = append(pendingMigrations, migration)
 		}
 	}
 
-	return nil
+	return ms.applyMigrations(pendingMigrations, opts)
 }
 
-func (ms MigrationService) ApplyUpToVersion(version string) error {
+func (ms MigrationService) ApplyUpToVersion(version string, opts ApplyOptions) error {
 	migrations, err := ms.ListMigrations(SortOrderAsc)
 	if err != nil {
 		return err
 	}
+	err = checkNotDirty(migrations)
+	if err != nil {
+		return err
+	}
 
 	var targetMigration *models.Migration
 	for _, migration := range migrations {
@@ -87,16 +172,10 @@ func (ms MigrationService) ApplyUpToVersion(version string) error {
 		)
 	}
 
+	pendingMigrations := make([]*models.Migration, 0, len(migrations))
 	for _, migration := range migrations {
 		if !migration.Applied {
-			err := ms.ApplyMigration(migration)
-			if err != nil {
-				return fmt.Errorf(
-					"unable to apply migration %s: %w",
-					migration.Name(),
-					err,
-				)
-			}
+			pendingMigrations = append(pendingMigrations, migration)
 		}
 
 		if migration.Version == version {
@@ -104,66 +183,571 @@ func (ms MigrationService) ApplyUpToVersion(version string) error {
 		}
 	}
 
+	return ms.applyMigrations(pendingMigrations, opts)
+}
+
+func (ms MigrationService) applyMigrations(
+	migrations []*models.Migration,
+	opts ApplyOptions,
+) error {
+	if opts.Atomic {
+		return ms.applyMigrationsInTx(migrations)
+	}
+
+	appliedMigrations := make([]*models.Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		err := ms.ApplyMigration(migration)
+		if err != nil {
+			if opts.RollbackOnFailure {
+				return ms.rollbackMigrations(appliedMigrations, err)
+			}
+			return err
+		}
+		appliedMigrations = append(appliedMigrations, migration)
+	}
+
+	return nil
+}
+
+// rollbackMigrations reverts the appliedMigrations in reverse order
+// after applyErr stopped the rest from being applied. It stops at
+// the first migration it can't revert, since the migrations applied
+// before it may be needed by it.
+func (ms MigrationService) rollbackMigrations(
+	appliedMigrations []*models.Migration,
+	applyErr error,
+) error {
+	rollbackErr := &RollbackError{Err: applyErr, RolledBack: make([]*models.Migration, 0)}
+	if len(appliedMigrations) == 0 {
+		return rollbackErr
+	}
+
+	ms.outputter.Output(fmt.Sprintf(
+		"Rolling back the %d migration(s) applied before the failure..",
+		len(appliedMigrations),
+	))
+	for i := len(appliedMigrations) - 1; i >= 0; i-- {
+		migration := appliedMigrations[i]
+
+		downgradeScript, err := ms.fsRepo.ReadDowngradeScript(migration)
+		if err != nil {
+			err = fmt.Errorf("unable to read downgrade script: %w", err)
+		} else if ms.isIrreversible(downgradeScript) {
+			err = ErrIrreversibleMigration
+		} else {
+			err = ms.revertMigration(migration, downgradeScript)
+		}
+
+		if err != nil {
+			rollbackErr.NotRolledBack = slices.Clone(appliedMigrations[:i+1])
+			rollbackErr.RollbackErr = err
+			return rollbackErr
+		}
+		rollbackErr.RolledBack = append(rollbackErr.RolledBack, migration)
+	}
+
+	return rollbackErr
+}
+
+// applyMigrationsInTx applies the migrations within a single
+// transaction. Every upgrade script is read before any are
+// applied so a migration that can't be applied in a transaction
+// is caught upfront.
+func (ms MigrationService) applyMigrationsInTx(migrations []*models.Migration) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	upgradeScripts := make([]sqlparse.MigrationScript, len(migrations))
+	for i, migration := range migrations {
+		upgradeScript, err := ms.fsRepo.ReadUpgradeScript(migration)
+		if err != nil {
+			return fmt.Errorf("unable to read upgrade script of %s: %w", migration.Name(), err)
+		}
+		if !upgradeScript.Options.UseTransaction {
+			return fmt.Errorf("%w: %s", ErrNonTransactionalMigration, migration.Name())
+		}
+		upgradeScripts[i] = upgradeScript
+	}
+
+	err := ms.dbRepo.Tx(func(repo repositories.MigrationDBRepo) error {
+		txService := ms
+		txService.dbRepo = repo
+
+		for i, migration := range migrations {
+			ms.outputter.Event(migrationEvent(output.EventMigrationStarted, output.DirectionUp, migration))
+			err := txService.executeUpgradeScript(migration, upgradeScripts[i], false, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		for _, migration := range migrations {
+			migration.Applied = false
+		}
+		return fmt.Errorf(
+			"unable to apply migrations in a single transaction, none of them were applied: %w",
+			err,
+		)
+	}
+
 	return nil
 }
 
 func (ms MigrationService) ApplyMigration(migration *models.Migration) error {
-	ms.outputter.Output(fmt.Sprintf("Applying migration %s..", migration.Name()))
+	ms.outputter.Event(migrationEvent(output.EventMigrationStarted, output.DirectionUp, migration))
 	startTime := time.Now()
 
 	upgradeScript, err := ms.fsRepo.ReadUpgradeScript(migration)
 	if err != nil {
-		return fmt.Errorf("unable to read upgrade script: %w", err)
+		err = fmt.Errorf("unable to read upgrade script: %w", err)
+		ms.outputMigrationFailed(output.DirectionUp, migration, startTime, err)
+		return fmt.Errorf("unable to apply migration %s: %w", migration.Name(), err)
 	}
 
-	if upgradeScript.Options.UseTransaction {
+	return ms.executeUpgradeScript(
+		migration,
+		upgradeScript,
+		upgradeScript.Options.UseTransaction,
+		startTime,
+	)
+}
+
+func (ms MigrationService) executeUpgradeScript(
+	migration *models.Migration,
+	upgradeScript sqlparse.MigrationScript,
+	useTransaction bool,
+	startTime time.Time,
+) error {
+	var err error
+	if useTransaction {
 		err = ms.dbRepo.ApplyWithTx(upgradeScript.Contents, migration)
 	} else {
 		err = ms.dbRepo.Apply(upgradeScript.Contents, migration)
 	}
 
 	if err != nil {
+		ms.outputMigrationFailed(output.DirectionUp, migration, startTime, err)
 		return fmt.Errorf("unable to apply migration %s: %w", migration.Name(), err)
 	}
 
-	ms.outputter.Output(
-		fmt.Sprintf(
-			"Successfully applied migration %s in %s!",
-			migration.Name(),
-			time.Since(startTime),
-		),
-	)
+	event := migrationEvent(output.EventMigrationFinished, output.DirectionUp, migration)
+	event.Duration = time.Since(startTime)
+	ms.outputter.Event(event)
 
 	return nil
 }
 
-func (ms MigrationService) RevertAllMigrations() error {
-	migrations, err := ms.ListMigrations(SortOrderDesc)
+func migrationEvent(
+	eventType output.EventType,
+	direction output.Direction,
+	migration *models.Migration,
+) output.Event {
+	return output.Event{
+		Type:      eventType,
+		Direction: direction,
+		Version:   migration.Version,
+		Migration: migration.Name(),
+	}
+}
+
+func (ms MigrationService) outputMigrationFailed(
+	direction output.Direction,
+	migration *models.Migration,
+	startTime time.Time,
+	err error,
+) {
+	event := migrationEvent(output.EventMigrationFailed, direction, migration)
+	event.Duration = time.Since(startTime)
+	event.Err = err
+	ms.outputter.Event(event)
+}
+
+// ApplyMigrationsByVersion applies the local migrations with
+// the given versions in order, skipping any that are already
+// applied. Every version must have a local migration.
+func (ms MigrationService) ApplyMigrationsByVersion(versions []string) error {
+	migrations, err := ms.ListMigrations(SortOrderAsc)
 	if err != nil {
 		return err
 	}
 
+	for _, version := range versions {
+		if findMigration(migrations, version) == nil {
+			return fmt.Errorf("migration with version %s does not exist", version)
+		}
+	}
+
 	for _, migration := range migrations {
-		if migration.Applied {
-			err := ms.RevertMigration(migration)
+		if migration.Applied || !slices.Contains(versions, migration.Version) {
+			continue
+		}
+
+		err := ms.ApplyMigration(migration)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BaselineToVersion marks every local migration up to and including
+// the version as applied without executing their upgrade scripts. It
+// is meant for databases whose schema already matches those migrations.
+func (ms MigrationService) BaselineToVersion(version string) error {
+	migrations, err := ms.ListMigrations(SortOrderAsc)
+	if err != nil {
+		return err
+	}
+
+	targetMigration := findMigration(migrations, version)
+	if targetMigration == nil {
+		return fmt.Errorf("migration with version %s does not exist", version)
+	}
+
+	for _, migration := range migrations {
+		if !migration.Applied {
+			err = ms.markApplied(migration)
 			if err != nil {
-				return fmt.Errorf(
-					"unable to revert migration %s: %w",
-					migration.Name(),
-					err,
-				)
+				return err
 			}
 		}
+
+		if migration.Version == version {
+			break
+		}
+	}
+
+	return nil
+}
+
+// MarkMigrationApplied marks a single local migration as applied
+// without executing its upgrade script.
+func (ms MigrationService) MarkMigrationApplied(version string) error {
+	migrations, err := ms.ListMigrations(SortOrderAsc)
+	if err != nil {
+		return err
+	}
+
+	migration := findMigration(migrations, version)
+	if migration == nil {
+		return fmt.Errorf("migration with version %s does not exist", version)
+	}
+	if migration.Applied {
+		return fmt.Errorf("migration with version %s is already applied", version)
+	}
+
+	return ms.markApplied(migration)
+}
+
+// MarkMigrationUnapplied marks a single migration as not applied
+// without executing its downgrade script. The migration doesn't
+// need to exist locally so that history can be fixed up after a
+// migration was removed by hand.
+func (ms MigrationService) MarkMigrationUnapplied(version string) error {
+	appliedMigrations, err := ms.dbRepo.List()
+	if err != nil {
+		return fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+
+	migration, ok := appliedMigrations[version]
+	if !ok {
+		return fmt.Errorf("migration with version %s isn't applied", version)
+	}
+
+	localMigrations, err := ms.fsRepo.List()
+	if err != nil {
+		return fmt.Errorf("unable to list out local filesystem migrations: %w", err)
+	}
+	if localMigration, ok := localMigrations[version]; ok {
+		migration = localMigration
+	}
+
+	err = ms.dbRepo.MarkUnapplied(migration)
+	if err != nil {
+		return fmt.Errorf(
+			"unable to mark migration %s as unapplied: %w",
+			migration.Name(),
+			err,
+		)
+	}
+	ms.outputter.Output(fmt.Sprintf("Marked migration %s as unapplied.", migration.Name()))
+
+	return nil
+}
+
+// ResolveMigration clears up a migration left dirty by a script that
+// failed partway through, once the database has been fixed by hand.
+// When applied is true, the migration is kept as applied. Otherwise,
+// it is removed from the migration history as rolled back.
+func (ms MigrationService) ResolveMigration(version string, applied bool) error {
+	appliedMigrations, err := ms.dbRepo.List()
+	if err != nil {
+		return fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+
+	migration, ok := appliedMigrations[version]
+	if !ok || !migration.Dirty {
+		return fmt.Errorf("migration with version %s isn't dirty, nothing to resolve", version)
+	}
+
+	localMigrations, err := ms.fsRepo.List()
+	if err != nil {
+		return fmt.Errorf("unable to list out local filesystem migrations: %w", err)
+	}
+	if localMigration, ok := localMigrations[version]; ok {
+		localMigration.Applied = true
+		localMigration.Dirty = true
+		migration = localMigration
+	}
+
+	if applied {
+		err = ms.dbRepo.MarkClean(migration)
+		if err != nil {
+			return fmt.Errorf("unable to resolve migration %s as applied: %w", migration.Name(), err)
+		}
+		ms.outputter.Output(fmt.Sprintf("Resolved migration %s as applied.", migration.Name()))
+		return nil
+	}
+
+	err = ms.dbRepo.MarkUnapplied(migration)
+	if err != nil {
+		return fmt.Errorf("unable to resolve migration %s as rolled back: %w", migration.Name(), err)
+	}
+	ms.outputter.Output(fmt.Sprintf("Resolved migration %s as rolled back.", migration.Name()))
+
+	return nil
+}
+
+func (ms MigrationService) markApplied(migration *models.Migration) error {
+	err := ms.dbRepo.MarkApplied(migration)
+	if err != nil {
+		return fmt.Errorf(
+			"unable to mark migration %s as applied: %w",
+			migration.Name(),
+			err,
+		)
+	}
+	ms.outputter.Output(fmt.Sprintf("Marked migration %s as applied.", migration.Name()))
+
+	return nil
+}
+
+func findMigration(migrations []*models.Migration, version string) *models.Migration {
+	for _, migration := range migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+// SquashToVersion replaces every local migration up to and including
+// the version with a single migration whose upgrade script is a dump
+// of the database's schema. The originals are moved into the archive
+// directory.
+//
+// The squashed migration records the versions it was squashed from,
+// so databases that already applied the originals treat it as applied.
+// Because of that, the database being dumped must have exactly the
+// migrations up to and including the version applied. Its migrations
+// table is updated to only have the squashed migration.
+//
+// If the originals can't be archived or the migrations table can't be
+// updated, the squashed migration is removed and the originals are
+// moved back.
+func (ms MigrationService) SquashToVersion(version string, archiveDirPath string) error {
+	migrations, err := ms.ListMigrations(SortOrderAsc)
+	if err != nil {
+		return err
+	}
+
+	targetMigration := findMigration(migrations, version)
+	if targetMigration == nil {
+		return fmt.Errorf("migration with version %s does not exist", version)
+	}
+
+	squashedMigrations := make([]*models.Migration, 0)
+	pastTarget := false
+	for _, migration := range migrations {
+		if !pastTarget && !migration.Applied {
+			return fmt.Errorf(
+				"migration %s isn't applied, the database must be at version %s to squash",
+				migration.Name(),
+				version,
+			)
+		}
+		if pastTarget && migration.Applied {
+			return fmt.Errorf(
+				"migration %s is applied, the database must be at version %s to squash",
+				migration.Name(),
+				version,
+			)
+		}
+
+		if !pastTarget {
+			squashedMigrations = append(squashedMigrations, migration)
+		}
+		if migration == targetMigration {
+			pastTarget = true
+		}
+	}
+
+	schema, err := ms.dbRepo.DumpSchema()
+	if err != nil {
+		return err
+	}
+
+	versions := make([]string, len(squashedMigrations))
+	for i, migration := range squashedMigrations {
+		versions[i] = migration.Version
+	}
+	squashedMigration := &models.Migration{
+		Version: version,
+		Message: "squashed",
+		Applied: true,
+	}
+	upgradeScript := sqlparse.NewMigrationScript(fmt.Sprintf(
+		"-- Squashed from migrations %s through %s.\n%s",
+		squashedMigrations[0].Version,
+		version,
+		schema,
+	))
+	upgradeScript.Options.Squashes = versions
+	downgradeScript := sqlparse.NewMigrationScript("")
+	downgradeScript.Options.Irreversible = true
+	err = ms.fsRepo.Write(squashedMigration, upgradeScript, downgradeScript)
+	if err != nil {
+		return fmt.Errorf(
+			"unable to write squashed migration %s: %w",
+			squashedMigration.Name(),
+			err,
+		)
+	}
+
+	// The squashed migration's checksum is taken from the script as
+	// it is read back so that it matches what status compares it to.
+	writtenScript, err := ms.fsRepo.ReadUpgradeScript(squashedMigration)
+	if err != nil {
+		return ms.undoSquash(squashedMigration, nil, archiveDirPath, fmt.Errorf(
+			"unable to read squashed migration %s: %w",
+			squashedMigration.Name(),
+			err,
+		))
+	}
+	squashedMigration.Checksum = models.Checksum(writtenScript.Contents)
+
+	archivedMigrations := make([]*models.Migration, 0, len(squashedMigrations))
+	for _, migration := range squashedMigrations {
+		err = ms.fsRepo.Archive(migration, archiveDirPath)
+		if err != nil {
+			return ms.undoSquash(squashedMigration, archivedMigrations, archiveDirPath, fmt.Errorf(
+				"unable to archive migration %s: %w",
+				migration.Name(),
+				err,
+			))
+		}
+		archivedMigrations = append(archivedMigrations, migration)
+	}
+
+	err = ms.dbRepo.Squash(squashedMigration, versions)
+	if err != nil {
+		return ms.undoSquash(squashedMigration, archivedMigrations, archiveDirPath, fmt.Errorf(
+			"unable to record squashed migration %s: %w",
+			squashedMigration.Name(),
+			err,
+		))
+	}
+
+	ms.outputter.Output(fmt.Sprintf(
+		"Squashed %d migrations into %s. The originals were archived to %s.",
+		len(squashedMigrations),
+		squashedMigration.Name(),
+		archiveDirPath,
+	))
+	return nil
+}
+
+// undoSquash removes the squashed migration and moves the migrations
+// that were archived back, so that a squash that failed partway
+// through leaves the migrations directory as it found it.
+func (ms MigrationService) undoSquash(
+	squashedMigration *models.Migration,
+	archivedMigrations []*models.Migration,
+	archiveDirPath string,
+	squashErr error,
+) error {
+	errs := []error{squashErr}
+	for _, migration := range archivedMigrations {
+		err := ms.fsRepo.Restore(migration, archiveDirPath)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err := ms.fsRepo.Remove(squashedMigration)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ResetMigrations reverts every applied migration and then
+// applies every migration again. Nothing is applied if any
+// migration can't be reverted.
+func (ms MigrationService) ResetMigrations(opts RevertOptions) error {
+	err := ms.RevertAllMigrations(opts)
+	if err != nil {
+		return fmt.Errorf("unable to revert all migrations: %w", err)
+	}
+
+	err = ms.ApplyAllMigrations(ApplyOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to apply all migrations: %w", err)
 	}
 
 	return nil
 }
 
-func (ms MigrationService) RevertDownToVersion(version string) error {
+func (ms MigrationService) RevertAllMigrations(opts RevertOptions) error {
 	migrations, err := ms.ListMigrations(SortOrderDesc)
 	if err != nil {
 		return err
 	}
+	err = checkNotDirty(migrations)
+	if err != nil {
+		return err
+	}
+
+	plan := make([]*models.Migration, 0)
+	for _, migration := range migrations {
+		if migration.Applied {
+			plan = append(plan, migration)
+		}
+	}
+
+	return ms.revertMigrations(plan, opts)
+}
+
+func (ms MigrationService) RevertDownToVersion(version string, opts RevertOptions) error {
+	migrations, err := ms.ListMigrations(SortOrderDesc)
+	if err != nil {
+		return err
+	}
+	err = checkNotDirty(migrations)
+	if err != nil {
+		return err
+	}
 
 	var targetMigration *models.Migration
 	for _, migration := range migrations {
@@ -184,16 +768,10 @@ func (ms MigrationService) RevertDownToVersion(version string) error {
 		)
 	}
 
+	plan := make([]*models.Migration, 0)
 	for _, migration := range migrations {
 		if migration.Applied {
-			err := ms.RevertMigration(migration)
-			if err != nil {
-				return fmt.Errorf(
-					"unable to revert migration %s: %w",
-					migration.Name(),
-					err,
-				)
-			}
+			plan = append(plan, migration)
 		}
 
 		if migration.Version == version {
@@ -201,18 +779,100 @@ func (ms MigrationService) RevertDownToVersion(version string) error {
 		}
 	}
 
+	return ms.revertMigrations(plan, opts)
+}
+
+// checkNotDirty ensures none of the migrations were left dirty by a
+// script that failed partway through. The database has to be fixed up
+// by hand before anything else is applied or reverted on top of it.
+func checkNotDirty(migrations []*models.Migration) error {
+	for _, migration := range migrations {
+		if migration.Dirty {
+			return fmt.Errorf(
+				"%w: %s failed partway through and may have left the database "+
+					"partially changed, fix the database by hand and then run "+
+					"'bolt resolve -version %s' with -applied or -rolled-back",
+				ErrDirtyMigration,
+				migration.Name(),
+				migration.Version,
+			)
+		}
+	}
 	return nil
 }
 
-func (ms MigrationService) RevertMigration(migration *models.Migration) error {
-	ms.outputter.Output(fmt.Sprintf("Reverting migration %s..", migration.Name()))
-	startTime := time.Now()
+// revertMigrations reverts each migration in the order given. Before
+// anything is reverted, the whole plan is checked for irreversible
+// migrations so that we don't stop halfway through.
+func (ms MigrationService) revertMigrations(
+	migrations []*models.Migration,
+	opts RevertOptions,
+) error {
+	downgradeScripts := make([]sqlparse.MigrationScript, len(migrations))
+	for i, migration := range migrations {
+		downgradeScript, err := ms.fsRepo.ReadDowngradeScript(migration)
+		if err != nil {
+			return fmt.Errorf(
+				"unable to read downgrade script for migration %s: %w",
+				migration.Name(),
+				err,
+			)
+		}
+
+		if !opts.Force && ms.isIrreversible(downgradeScript) {
+			return fmt.Errorf(
+				"%w: %s cannot be reverted",
+				ErrIrreversibleMigration,
+				migration.Name(),
+			)
+		}
+
+		downgradeScripts[i] = downgradeScript
+	}
 
+	for i, migration := range migrations {
+		err := ms.revertMigration(migration, downgradeScripts[i])
+		if err != nil {
+			return fmt.Errorf(
+				"unable to revert migration %s: %w",
+				migration.Name(),
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// isIrreversible checks whether the downgrade script is explicitly
+// marked as irreversible or, when configured to do so, whether it
+// is empty.
+func (ms MigrationService) isIrreversible(downgradeScript sqlparse.MigrationScript) bool {
+	if downgradeScript.Options.Irreversible {
+		return true
+	}
+
+	return ms.cfg.Migrations.EmptyDownIrreversible &&
+		strings.TrimSpace(downgradeScript.Contents) == ""
+}
+
+func (ms MigrationService) RevertMigration(migration *models.Migration) error {
 	downgradeScript, err := ms.fsRepo.ReadDowngradeScript(migration)
 	if err != nil {
 		return fmt.Errorf("unable to read downgrade script: %w", err)
 	}
 
+	return ms.revertMigration(migration, downgradeScript)
+}
+
+func (ms MigrationService) revertMigration(
+	migration *models.Migration,
+	downgradeScript sqlparse.MigrationScript,
+) error {
+	ms.outputter.Event(migrationEvent(output.EventMigrationStarted, output.DirectionDown, migration))
+	startTime := time.Now()
+
+	var err error
 	if downgradeScript.Options.UseTransaction {
 		err = ms.dbRepo.RevertWithTx(downgradeScript.Contents, migration)
 	} else {
@@ -220,16 +880,13 @@ func (ms MigrationService) RevertMigration(migration *models.Migration) error {
 	}
 
 	if err != nil {
+		ms.outputMigrationFailed(output.DirectionDown, migration, startTime, err)
 		return err
 	}
 
-	ms.outputter.Output(
-		fmt.Sprintf(
-			"Successfully reverted migration %s in %s!",
-			migration.Name(),
-			time.Since(startTime),
-		),
-	)
+	event := migrationEvent(output.EventMigrationFinished, output.DirectionDown, migration)
+	event.Duration = time.Since(startTime)
+	ms.outputter.Event(event)
 
 	return nil
 }
@@ -305,9 +962,239 @@ func (ms MigrationService) ListMigrations(order sortOrder) ([]*models.Migration,
 		)
 	}
 
+	err = ms.resolveSquashedMigrations(localMigrations, appliedMigrations)
+	if err != nil {
+		return nil, err
+	}
+
 	return ms.combineMigrations(localMigrations, appliedMigrations, order)
 }
 
+// CurrentMigration retrieves the latest applied migration, along
+// with its message when it exists locally, and how many local
+// migrations aren't applied. The current migration is nil when
+// no migrations are applied.
+func (ms MigrationService) CurrentMigration() (*models.Migration, int, error) {
+	appliedMigrations, err := ms.dbRepo.List()
+	if err != nil {
+		return nil, 0, fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+
+	localMigrations, err := ms.fsRepo.List()
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to list out local filesystem migrations: %w", err)
+	}
+
+	err = ms.resolveSquashedMigrations(localMigrations, appliedMigrations)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pendingCount := 0
+	for version := range localMigrations {
+		if _, ok := appliedMigrations[version]; !ok {
+			pendingCount++
+		}
+	}
+
+	migrations := make([]*models.Migration, 0, len(appliedMigrations))
+	for _, appliedMigration := range appliedMigrations {
+		migrations = append(migrations, appliedMigration)
+	}
+	if len(migrations) == 0 {
+		return nil, pendingCount, nil
+	}
+
+	err = ms.sortMigrations(migrations, SortOrderDesc)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	current := migrations[0]
+	if localMigration, ok := localMigrations[current.Version]; ok {
+		current.Message = localMigration.Message
+	}
+
+	return current, pendingCount, nil
+}
+
+// MigrationStatus is a migration along with its state.
+type MigrationStatus struct {
+	Migration *models.Migration
+	State     models.MigrationState
+}
+
+// ListMigrationStatuses retrieves every local migration, along with any
+// applied migrations that no longer exist locally, in ascending order
+// with their state.
+func (ms MigrationService) ListMigrationStatuses() ([]MigrationStatus, error) {
+	localMigrations, err := ms.fsRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list out local filesystem migrations: %w", err)
+	}
+
+	appliedMigrations, err := ms.dbRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+
+	err = ms.resolveSquashedMigrations(localMigrations, appliedMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]*models.Migration, 0, len(localMigrations))
+	for _, localMigration := range localMigrations {
+		appliedMigration, ok := appliedMigrations[localMigration.Version]
+		if ok {
+			localMigration.Applied = true
+			localMigration.AppliedAt = appliedMigration.AppliedAt
+			localMigration.ExecutionTime = appliedMigration.ExecutionTime
+			localMigration.Checksum = appliedMigration.Checksum
+			localMigration.Dirty = appliedMigration.Dirty
+		}
+		migrations = append(migrations, localMigration)
+	}
+	for version, appliedMigration := range appliedMigrations {
+		if _, ok := localMigrations[version]; !ok {
+			migrations = append(migrations, appliedMigration)
+		}
+	}
+
+	err = ms.sortMigrations(migrations, SortOrderAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	latestAppliedIndex := -1
+	for i, migration := range migrations {
+		if migration.Applied {
+			latestAppliedIndex = i
+		}
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, migration := range migrations {
+		_, isLocal := localMigrations[migration.Version]
+		state, err := ms.migrationState(migration, isLocal, i < latestAppliedIndex)
+		if err != nil {
+			return nil, err
+		}
+		statuses[i] = MigrationStatus{Migration: migration, State: state}
+	}
+
+	return statuses, nil
+}
+
+// resolveSquashedMigrations treats a database that applied every
+// migration a local squashed migration was squashed from as having
+// applied the squashed migration. The originals are taken out of the
+// applied migrations, since they were archived when they were squashed.
+//
+// Only databases with applied migrations that don't exist locally can
+// have applied the originals, so the upgrade scripts aren't read for
+// the others.
+func (ms MigrationService) resolveSquashedMigrations(
+	localMigrations map[string]*models.Migration,
+	appliedMigrations map[string]*models.Migration,
+) error {
+	hasMissingFiles := false
+	for version := range appliedMigrations {
+		if _, ok := localMigrations[version]; !ok {
+			hasMissingFiles = true
+			break
+		}
+	}
+	if !hasMissingFiles {
+		return nil
+	}
+
+	for version, localMigration := range localMigrations {
+		appliedMigration, ok := appliedMigrations[version]
+		if !ok {
+			continue
+		}
+
+		upgradeScript, err := ms.fsRepo.ReadUpgradeScript(localMigration)
+		if err != nil {
+			return fmt.Errorf(
+				"unable to read upgrade script for migration %s: %w",
+				localMigration.Name(),
+				err,
+			)
+		}
+		squashes := upgradeScript.Options.Squashes
+		if len(squashes) == 0 {
+			continue
+		}
+
+		allApplied := true
+		for _, squashedVersion := range squashes {
+			if _, ok := appliedMigrations[squashedVersion]; !ok {
+				allApplied = false
+				break
+			}
+		}
+		if !allApplied {
+			continue
+		}
+
+		for _, squashedVersion := range squashes {
+			if squashedVersion != version {
+				delete(appliedMigrations, squashedVersion)
+			}
+		}
+		squashedMigration := *appliedMigration
+		squashedMigration.Checksum = models.Checksum(upgradeScript.Contents)
+		appliedMigrations[version] = &squashedMigration
+	}
+
+	return nil
+}
+
+func (ms MigrationService) migrationState(
+	migration *models.Migration,
+	isLocal bool,
+	beforeLatestApplied bool,
+) (models.MigrationState, error) {
+	if !migration.Applied {
+		if beforeLatestApplied {
+			return models.MigrationStateOutOfOrder, nil
+		}
+		return models.MigrationStatePending, nil
+	}
+
+	if migration.Dirty {
+		return models.MigrationStateDirty, nil
+	}
+
+	if !isLocal {
+		return models.MigrationStateMissingFile, nil
+	}
+
+	// Migrations applied before checksums were recorded, or
+	// that were only marked as applied, can't be compared.
+	if migration.Checksum == "" {
+		return models.MigrationStateApplied, nil
+	}
+
+	upgradeScript, err := ms.fsRepo.ReadUpgradeScript(migration)
+	if err != nil {
+		return "", fmt.Errorf("unable to read upgrade script: %w", err)
+	}
+	if models.Checksum(upgradeScript.Contents) != migration.Checksum {
+		return models.MigrationStateChecksumMismatch, nil
+	}
+
+	return models.MigrationStateApplied, nil
+}
+
 func (ms MigrationService) combineMigrations(
 	localMigrations map[string]*models.Migration,
 	appliedMigrations map[string]*models.Migration,
@@ -315,9 +1202,10 @@ func (ms MigrationService) combineMigrations(
 ) ([]*models.Migration, error) {
 	migrations := make([]*models.Migration, 0)
 	for _, localMigration := range localMigrations {
-		_, ok := appliedMigrations[localMigration.Version]
+		appliedMigration, ok := appliedMigrations[localMigration.Version]
 		if ok {
 			localMigration.Applied = true
+			localMigration.Dirty = appliedMigration.Dirty
 		}
 		migrations = append(migrations, localMigration)
 	}
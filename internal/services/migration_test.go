@@ -2,12 +2,15 @@ package services
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/eugenetriguba/bolt/internal/bolttest"
 	"github.com/eugenetriguba/bolt/internal/configloader"
 	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/bolt/internal/repositories"
 	"github.com/eugenetriguba/bolt/internal/sqlparse"
 	"github.com/eugenetriguba/checkmate/assert"
 	"github.com/eugenetriguba/checkmate/check"
@@ -356,6 +359,214 @@ func TestListMigrations_ParseErrDuringSort(t *testing.T) {
 	}
 }
 
+func TestListMigrationStatuses(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	executionTime := time.Second
+	upgradeScript := sqlparse.MigrationScript{Contents: "CREATE TABLE tmp(id INT);"}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+				"002": {Version: "002", Message: "second"},
+				"003": {Version: "003", Message: "third"},
+				"005": {Version: "005", Message: "fifth"},
+				"006": {Version: "006", Message: "sixth"},
+			},
+		},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{Script: upgradeScript},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {
+					Version:       "001",
+					Applied:       true,
+					AppliedAt:     appliedAt,
+					ExecutionTime: &executionTime,
+					Checksum:      models.Checksum(upgradeScript.Contents),
+				},
+				"003": {Version: "003", Applied: true, Checksum: models.Checksum("changed")},
+				"004": {Version: "004", Applied: true},
+				"005": {Version: "005", Applied: true},
+			},
+		},
+	}
+
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+	statuses, err := svc.ListMigrationStatuses()
+
+	assert.Nil(t, err)
+	assert.DeepEqual(t, statuses, []MigrationStatus{
+		{
+			Migration: &models.Migration{
+				Version:       "001",
+				Message:       "first",
+				Applied:       true,
+				AppliedAt:     appliedAt,
+				ExecutionTime: &executionTime,
+				Checksum:      models.Checksum(upgradeScript.Contents),
+			},
+			State: models.MigrationStateApplied,
+		},
+		{
+			Migration: &models.Migration{Version: "002", Message: "second"},
+			State:     models.MigrationStateOutOfOrder,
+		},
+		{
+			Migration: &models.Migration{
+				Version:  "003",
+				Message:  "third",
+				Applied:  true,
+				Checksum: models.Checksum("changed"),
+			},
+			State: models.MigrationStateChecksumMismatch,
+		},
+		{
+			Migration: &models.Migration{Version: "004", Applied: true},
+			State:     models.MigrationStateMissingFile,
+		},
+		{
+			Migration: &models.Migration{Version: "005", Message: "fifth", Applied: true},
+			State:     models.MigrationStateApplied,
+		},
+		{
+			Migration: &models.Migration{Version: "006", Message: "sixth"},
+			State:     models.MigrationStatePending,
+		},
+	})
+	// 004 is missing, so the applied migrations are read to check
+	// whether they were squashed from it, along with the 2 checksums.
+	check.Equal(t, migrationFsRepo.ReadUpgradeScriptCallCount, 5)
+}
+
+func TestListMigrationStatuses_ReadUpgradeScriptErr(t *testing.T) {
+	expectedErr := errors.New("read error")
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+			},
+		},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{Err: expectedErr},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true, Checksum: models.Checksum("script")},
+			},
+		},
+	}
+
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+	_, err := svc.ListMigrationStatuses()
+
+	assert.ErrorIs(t, err, expectedErr)
+}
+
+func TestCurrentMigration(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+				"002": {Version: "002", Message: "second"},
+				"010": {Version: "010", Message: "tenth"},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+				"002": {Version: "002", Applied: true, AppliedAt: appliedAt},
+			},
+		},
+	}
+
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+	current, pendingCount, err := svc.CurrentMigration()
+
+	assert.Nil(t, err)
+	assert.DeepEqual(t, current, &models.Migration{
+		Version:   "002",
+		Message:   "second",
+		Applied:   true,
+		AppliedAt: appliedAt,
+	})
+	check.Equal(t, pendingCount, 1)
+}
+
+func TestCurrentMigration_NoneApplied(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+	current, pendingCount, err := svc.CurrentMigration()
+
+	assert.Nil(t, err)
+	check.Nil(t, current)
+	check.Equal(t, pendingCount, 1)
+}
+
+func TestCurrentMigration_DbRepoListError(t *testing.T) {
+	expectedErr := errors.New("db repo error")
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{Err: expectedErr},
+	}
+
+	svc := NewMigrationService(
+		migrationDbRepo,
+		&bolttest.MockMigrationFsRepo{},
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+	_, _, err := svc.CurrentMigration()
+
+	assert.ErrorIs(t, err, expectedErr)
+}
+
 func TestCreateMigration_VersionStyleTimestamp(t *testing.T) {
 	migrationFsRepo := &bolttest.MockMigrationFsRepo{
 		CreateReturnValue: bolttest.CreateReturnValue{
@@ -731,7 +942,7 @@ func TestApplyAllMigrations_ListMigrationsErr(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.ApplyAllMigrations()
+	err := svc.ApplyAllMigrations(ApplyOptions{})
 
 	assert.ErrorIs(t, err, expectedErr)
 }
@@ -759,7 +970,7 @@ func TestApplyAllMigrations_AppliesNothingIfAllApplied(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.ApplyAllMigrations()
+	err := svc.ApplyAllMigrations(ApplyOptions{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, migrationDbRepo.ApplyCallCount, 0)
@@ -792,117 +1003,152 @@ func TestApplyAllMigrations_ApplyMigrationErr(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.ApplyAllMigrations()
+	err := svc.ApplyAllMigrations(ApplyOptions{})
 
 	assert.ErrorIs(t, err, expectedErr)
 	assert.Equal(t, migrationDbRepo.ApplyCallCount, 0)
 	assert.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 0)
 }
 
-func TestApplyUpToVersion_ListMigrationsErr(t *testing.T) {
-	expectedErr := errors.New("error!")
-	migrationFsRepo := &bolttest.MockMigrationFsRepo{
-		ListReturnValue: bolttest.ListReturnValue{
-			Migrations: nil,
-			Err:        expectedErr,
-		},
-	}
-	svc := NewMigrationService(
-		&bolttest.MockMigrationDBRepo{},
-		migrationFsRepo,
-		configloader.Config{},
-		bolttest.NullOutputter{},
-	)
-
-	err := svc.ApplyUpToVersion("001")
-
-	assert.ErrorIs(t, err, expectedErr)
-}
-
-func TestApplyUpToVersion_TargetMigrationNotFound(t *testing.T) {
+func TestApplyAllMigrations_AtomicAppliesInSingleTx(t *testing.T) {
 	migrations := map[string]*models.Migration{
 		"001": {Version: "001", Applied: false},
+		"002": {Version: "002", Applied: false},
+		"003": {Version: "003", Applied: true},
 	}
 	migrationFsRepo := &bolttest.MockMigrationFsRepo{
 		ListReturnValue: bolttest.ListReturnValue{
 			Migrations: migrations,
 			Err:        nil,
 		},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+		},
 	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
 	svc := NewMigrationService(
-		&bolttest.MockMigrationDBRepo{},
+		migrationDbRepo,
 		migrationFsRepo,
-		configloader.Config{},
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.ApplyUpToVersion("002")
+	err := svc.ApplyAllMigrations(ApplyOptions{Atomic: true})
 
-	assert.ErrorContains(t, err, "migration with version 002 does not exist")
+	assert.Nil(t, err)
+	assert.Equal(t, migrationDbRepo.TxCallCount, 1)
+	assert.Equal(t, migrationDbRepo.ApplyCallCount, 2)
+	assert.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 0)
 }
 
-func TestApplyUpToVersion_TargetMigrationAlreadyApplied(t *testing.T) {
+func TestApplyAllMigrations_AtomicRejectsNonTransactionalMigration(t *testing.T) {
 	migrations := map[string]*models.Migration{
-		"001": {Version: "001", Applied: true},
+		"001": {Version: "001", Applied: false},
 	}
 	migrationFsRepo := &bolttest.MockMigrationFsRepo{
 		ListReturnValue: bolttest.ListReturnValue{
 			Migrations: migrations,
 			Err:        nil,
 		},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "CREATE INDEX CONCURRENTLY idx ON tmp(id);",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: false},
+			},
+		},
 	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
 	svc := NewMigrationService(
-		&bolttest.MockMigrationDBRepo{},
+		migrationDbRepo,
 		migrationFsRepo,
-		configloader.Config{},
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.ApplyUpToVersion("001")
+	err := svc.ApplyAllMigrations(ApplyOptions{Atomic: true})
 
-	assert.ErrorContains(t, err, "migration with version 001 is already applied")
+	assert.ErrorIs(t, err, ErrNonTransactionalMigration)
+	assert.Equal(t, migrationDbRepo.TxCallCount, 0)
+	assert.Equal(t, migrationDbRepo.ApplyCallCount, 0)
 }
 
-func TestApplyUpToVersion_ApplyMigrationErr(t *testing.T) {
+func TestApplyAllMigrations_AtomicTxErr(t *testing.T) {
 	migrations := map[string]*models.Migration{
 		"001": {Version: "001", Applied: false},
 	}
-	expectedErr := errors.New("error!")
+	expectedErr := errors.New("tx error")
 	migrationFsRepo := &bolttest.MockMigrationFsRepo{
 		ListReturnValue: bolttest.ListReturnValue{
 			Migrations: migrations,
 			Err:        nil,
 		},
 		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
-			Err: expectedErr,
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
 		},
 	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		TxReturnValue: bolttest.TxReturnValue{Err: expectedErr},
+	}
 	svc := NewMigrationService(
-		&bolttest.MockMigrationDBRepo{},
+		migrationDbRepo,
 		migrationFsRepo,
-		configloader.Config{},
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.ApplyUpToVersion("001")
+	err := svc.ApplyAllMigrations(ApplyOptions{Atomic: true})
 
 	assert.ErrorIs(t, err, expectedErr)
+	assert.ErrorContains(t, err, "none of them were applied")
+	assert.False(t, migrations["001"].Applied)
 }
 
-func TestApplyUpToVersion_AppliesMigrations(t *testing.T) {
+func TestApplyAllMigrations_RollbackOnFailure(t *testing.T) {
 	migrations := map[string]*models.Migration{
-		"001": {Version: "001", Applied: false},
-		"002": {Version: "002", Applied: false},
-		"003": {Version: "003", Applied: false},
+		"001": {Version: "001", Message: "first"},
+		"002": {Version: "002", Message: "second"},
+		"003": {Version: "003", Message: "third"},
 	}
+	expectedErr := errors.New("apply error")
 	migrationFsRepo := &bolttest.MockMigrationFsRepo{
-		ListReturnValue: bolttest.ListReturnValue{
-			Migrations: migrations,
-			Err:        nil,
+		ListReturnValue: bolttest.ListReturnValue{Migrations: migrations},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+		},
+		ReadDowngradeScriptReturnValue: bolttest.ReadDowngradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
 		},
 	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ApplyWithTxReturnValue: bolttest.ApplyWithTxReturnValue{Err: expectedErr},
+		ApplyWithTxErrOnCall:   3,
+	}
 	svc := NewMigrationService(
-		&bolttest.MockMigrationDBRepo{},
+		migrationDbRepo,
 		migrationFsRepo,
 		configloader.Config{
 			Migrations: configloader.MigrationsConfig{
@@ -912,40 +1158,250 @@ func TestApplyUpToVersion_AppliesMigrations(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.ApplyUpToVersion("002")
+	err := svc.ApplyAllMigrations(ApplyOptions{RollbackOnFailure: true})
 
-	assert.Nil(t, err)
-	assert.Equal(t, migrationFsRepo.ReadUpgradeScriptCallCount, 2)
+	assert.ErrorIs(t, err, expectedErr)
+	var rollbackErr *RollbackError
+	assert.True(t, errors.As(err, &rollbackErr))
+	assert.DeepEqual(t, rollbackErr.RolledBack, []*models.Migration{migrations["002"], migrations["001"]})
+	assert.Equal(t, len(rollbackErr.NotRolledBack), 0)
+	assert.ErrorContains(t, err, "; rolled back 002_second, 001_first")
+	assert.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 3)
+	assert.Equal(t, migrationDbRepo.RevertWithTxCallCount, 2)
 }
 
-func TestRevertMigration_WithTransaction(t *testing.T) {
+func TestApplyAllMigrations_RollbackOnFailureStopsAtIrreversibleMigration(t *testing.T) {
+	migrations := map[string]*models.Migration{
+		"001": {Version: "001", Message: "first"},
+		"002": {Version: "002", Message: "second"},
+		"003": {Version: "003", Message: "third"},
+	}
+	expectedErr := errors.New("apply error")
 	migrationFsRepo := &bolttest.MockMigrationFsRepo{
-		ReadDowngradeScriptReturnValue: bolttest.ReadDowngradeScriptReturnValue{
+		ListReturnValue: bolttest.ListReturnValue{Migrations: migrations},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
 			Script: sqlparse.MigrationScript{
-				Contents: "DROP TABLE tmp;",
+				Contents: "SELECT 1;",
 				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
 			},
-			Err: nil,
+		},
+		ReadDowngradeScriptReturnValue: bolttest.ReadDowngradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Options: sqlparse.ExecutionOptions{UseTransaction: true, Irreversible: true},
+			},
 		},
 	}
-
 	migrationDbRepo := &bolttest.MockMigrationDBRepo{
-		RevertWithTxReturnValue: bolttest.RevertWithTxReturnValue{
-			Err: nil,
-		},
+		ApplyWithTxReturnValue: bolttest.ApplyWithTxReturnValue{Err: expectedErr},
+		ApplyWithTxErrOnCall:   3,
 	}
 	svc := NewMigrationService(
 		migrationDbRepo,
 		migrationFsRepo,
-		configloader.Config{},
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertMigration(&models.Migration{})
+	err := svc.ApplyAllMigrations(ApplyOptions{RollbackOnFailure: true})
 
-	assert.Nil(t, err)
-	assert.Equal(t, migrationDbRepo.RevertWithTxCallCount, 1)
-	assert.Equal(t, migrationDbRepo.RevertCallCount, 0)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.ErrorIs(t, err, ErrIrreversibleMigration)
+	assert.Equal(
+		t,
+		err.Error(),
+		"unable to apply migration 003_third: apply error; "+
+			"could not roll back 002_second: migration is irreversible; "+
+			"skipped rolling back 001_first since 002_second is still applied",
+	)
+	assert.Equal(t, migrationDbRepo.RevertWithTxCallCount, 0)
+}
+
+func TestApplyAllMigrations_RollbackOnFailureWithNothingApplied(t *testing.T) {
+	migrations := map[string]*models.Migration{
+		"001": {Version: "001", Message: "first"},
+	}
+	expectedErr := errors.New("apply error")
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{Migrations: migrations},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ApplyWithTxReturnValue: bolttest.ApplyWithTxReturnValue{Err: expectedErr},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyAllMigrations(ApplyOptions{RollbackOnFailure: true})
+
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, err.Error(), "unable to apply migration 001_first: apply error")
+	assert.Equal(t, migrationFsRepo.ReadDowngradeScriptCallCount, 0)
+}
+
+func TestApplyUpToVersion_ListMigrationsErr(t *testing.T) {
+	expectedErr := errors.New("error!")
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: nil,
+			Err:        expectedErr,
+		},
+	}
+	svc := NewMigrationService(
+		&bolttest.MockMigrationDBRepo{},
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyUpToVersion("001", ApplyOptions{})
+
+	assert.ErrorIs(t, err, expectedErr)
+}
+
+func TestApplyUpToVersion_TargetMigrationNotFound(t *testing.T) {
+	migrations := map[string]*models.Migration{
+		"001": {Version: "001", Applied: false},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: migrations,
+			Err:        nil,
+		},
+	}
+	svc := NewMigrationService(
+		&bolttest.MockMigrationDBRepo{},
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyUpToVersion("002", ApplyOptions{})
+
+	assert.ErrorContains(t, err, "migration with version 002 does not exist")
+}
+
+func TestApplyUpToVersion_TargetMigrationAlreadyApplied(t *testing.T) {
+	migrations := map[string]*models.Migration{
+		"001": {Version: "001", Applied: true},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: migrations,
+			Err:        nil,
+		},
+	}
+	svc := NewMigrationService(
+		&bolttest.MockMigrationDBRepo{},
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyUpToVersion("001", ApplyOptions{})
+
+	assert.ErrorContains(t, err, "migration with version 001 is already applied")
+}
+
+func TestApplyUpToVersion_ApplyMigrationErr(t *testing.T) {
+	migrations := map[string]*models.Migration{
+		"001": {Version: "001", Applied: false},
+	}
+	expectedErr := errors.New("error!")
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: migrations,
+			Err:        nil,
+		},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Err: expectedErr,
+		},
+	}
+	svc := NewMigrationService(
+		&bolttest.MockMigrationDBRepo{},
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyUpToVersion("001", ApplyOptions{})
+
+	assert.ErrorIs(t, err, expectedErr)
+}
+
+func TestApplyUpToVersion_AppliesMigrations(t *testing.T) {
+	migrations := map[string]*models.Migration{
+		"001": {Version: "001", Applied: false},
+		"002": {Version: "002", Applied: false},
+		"003": {Version: "003", Applied: false},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: migrations,
+			Err:        nil,
+		},
+	}
+	svc := NewMigrationService(
+		&bolttest.MockMigrationDBRepo{},
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyUpToVersion("002", ApplyOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, migrationFsRepo.ReadUpgradeScriptCallCount, 2)
+}
+
+func TestRevertMigration_WithTransaction(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ReadDowngradeScriptReturnValue: bolttest.ReadDowngradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "DROP TABLE tmp;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+			Err: nil,
+		},
+	}
+
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		RevertWithTxReturnValue: bolttest.RevertWithTxReturnValue{
+			Err: nil,
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.RevertMigration(&models.Migration{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, migrationDbRepo.RevertWithTxCallCount, 1)
+	assert.Equal(t, migrationDbRepo.RevertCallCount, 0)
 }
 
 func TestRevertMigration_WithoutTransaction(t *testing.T) {
@@ -1067,7 +1523,7 @@ func TestRevertDownToVersion_ListMigrationsErr(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertDownToVersion("001")
+	err := svc.RevertDownToVersion("001", RevertOptions{})
 
 	assert.ErrorIs(t, err, expectedErr)
 }
@@ -1089,7 +1545,7 @@ func TestRevertDownToVersion_TargetMigrationNotFound(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertDownToVersion("002")
+	err := svc.RevertDownToVersion("002", RevertOptions{})
 
 	assert.ErrorContains(t, err, "migration with version 002 does not exist")
 }
@@ -1111,7 +1567,7 @@ func TestRevertDownToVersion_TargetMigrationNotApplied(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertDownToVersion("001")
+	err := svc.RevertDownToVersion("001", RevertOptions{})
 
 	assert.ErrorContains(t, err, "migration with version 001 isn't applied")
 }
@@ -1137,7 +1593,7 @@ func TestRevertDownToVersion_RevertMigrationErr(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertDownToVersion("001")
+	err := svc.RevertDownToVersion("001", RevertOptions{})
 
 	assert.ErrorIs(t, err, expectedErr)
 }
@@ -1165,7 +1621,7 @@ func TestRevertDownToVersion_RevertsMigrations(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertDownToVersion("001")
+	err := svc.RevertDownToVersion("001", RevertOptions{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, migrationFsRepo.ReadDowngradeScriptCallCount, 2)
@@ -1186,7 +1642,7 @@ func TestRevertAllMigrations_ListMigrationsErr(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertAllMigrations()
+	err := svc.RevertAllMigrations(RevertOptions{})
 
 	assert.ErrorIs(t, err, expectedErr)
 }
@@ -1214,7 +1670,7 @@ func TestRevertAllMigrations_RevertsNothingIfNoneApplied(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertAllMigrations()
+	err := svc.RevertAllMigrations(RevertOptions{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, migrationDbRepo.RevertCallCount, 0)
@@ -1247,9 +1703,955 @@ func TestRevertAllMigrations_RevertMigrationErr(t *testing.T) {
 		bolttest.NullOutputter{},
 	)
 
-	err := svc.RevertAllMigrations()
+	err := svc.RevertAllMigrations(RevertOptions{})
 
 	assert.ErrorIs(t, err, expectedErr)
 	assert.Equal(t, migrationDbRepo.RevertCallCount, 0)
 	assert.Equal(t, migrationDbRepo.RevertWithTxCallCount, 0)
 }
+
+func TestRevertAllMigrations_IrreversibleMigration(t *testing.T) {
+	type test struct {
+		downgradeScript       sqlparse.MigrationScript
+		emptyDownIrreversible bool
+		force                 bool
+		expectedRevertCount   int
+	}
+
+	tests := []test{
+		// Ensure an explicitly irreversible migration blocks reverting.
+		{
+			downgradeScript: sqlparse.MigrationScript{
+				Options: sqlparse.ExecutionOptions{Irreversible: true},
+			},
+			expectedRevertCount: 0,
+		},
+		// Ensure an explicitly irreversible migration can be forced.
+		{
+			downgradeScript: sqlparse.MigrationScript{
+				Options: sqlparse.ExecutionOptions{Irreversible: true},
+			},
+			force:               true,
+			expectedRevertCount: 2,
+		},
+		// Ensure an empty downgrade script is reversible by default.
+		{
+			downgradeScript:     sqlparse.MigrationScript{Contents: "\n"},
+			expectedRevertCount: 2,
+		},
+		// Ensure an empty downgrade script blocks reverting when configured.
+		{
+			downgradeScript:       sqlparse.MigrationScript{Contents: "\n"},
+			emptyDownIrreversible: true,
+			expectedRevertCount:   0,
+		},
+		// Ensure a non-empty downgrade script is reversible when configured.
+		{
+			downgradeScript:       sqlparse.MigrationScript{Contents: "DROP TABLE tmp;"},
+			emptyDownIrreversible: true,
+			expectedRevertCount:   2,
+		},
+	}
+
+	for _, tc := range tests {
+		migrationFsRepo := &bolttest.MockMigrationFsRepo{
+			ListReturnValue: bolttest.ListReturnValue{
+				Migrations: map[string]*models.Migration{
+					"001": {Version: "001", Applied: true},
+					"002": {Version: "002", Applied: true},
+				},
+			},
+			ReadDowngradeScriptReturnValue: bolttest.ReadDowngradeScriptReturnValue{
+				Script: tc.downgradeScript,
+			},
+		}
+		migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+		svc := NewMigrationService(
+			migrationDbRepo,
+			migrationFsRepo,
+			configloader.Config{
+				Migrations: configloader.MigrationsConfig{
+					VersionStyle:          configloader.VersionStyleSequential,
+					EmptyDownIrreversible: tc.emptyDownIrreversible,
+				},
+			},
+			bolttest.NullOutputter{},
+		)
+
+		err := svc.RevertAllMigrations(RevertOptions{Force: tc.force})
+
+		if tc.expectedRevertCount == 0 {
+			assert.ErrorIs(t, err, ErrIrreversibleMigration)
+		} else {
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, migrationDbRepo.RevertCallCount, tc.expectedRevertCount)
+	}
+}
+
+func TestRevertDownToVersion_IrreversibleMigrationOutsidePlan(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+				"002": {Version: "002", Applied: true},
+			},
+		},
+		ReadDowngradeScriptReturnValue: bolttest.ReadDowngradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{Contents: "DROP TABLE tmp;"},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.RevertDownToVersion("002", RevertOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, migrationFsRepo.ReadDowngradeScriptCallCount, 1)
+	assert.Equal(t, migrationDbRepo.RevertCallCount, 1)
+}
+
+func TestBaselineToVersion_MarksMigrationsUpToVersion(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001"},
+				"002": {Version: "002"},
+				"003": {Version: "003"},
+				"004": {Version: "004"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.BaselineToVersion("003")
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.MarkAppliedCallCount, 2)
+	check.Equal(t, migrationDbRepo.ApplyCallCount, 0)
+	check.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 0)
+	check.Equal(t, migrationFsRepo.ReadUpgradeScriptCallCount, 0)
+}
+
+func TestBaselineToVersion_TargetMigrationNotFound(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.BaselineToVersion("002")
+
+	check.ErrorContains(t, err, "migration with version 002 does not exist")
+	check.Equal(t, migrationDbRepo.MarkAppliedCallCount, 0)
+}
+
+func TestBaselineToVersion_MarkAppliedErr(t *testing.T) {
+	expectedErr := errors.New("mark applied failed")
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+		MarkAppliedReturnValue: bolttest.MarkAppliedReturnValue{Err: expectedErr},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001"},
+				"002": {Version: "002"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.BaselineToVersion("002")
+
+	check.ErrorIs(t, err, expectedErr)
+	check.Equal(t, migrationDbRepo.MarkAppliedCallCount, 1)
+}
+
+func TestMarkMigrationApplied_AlreadyApplied(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.MarkMigrationApplied("001")
+
+	check.ErrorContains(t, err, "migration with version 001 is already applied")
+	check.Equal(t, migrationDbRepo.MarkAppliedCallCount, 0)
+}
+
+func TestMarkMigrationApplied_MarksOnlyThatMigration(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001"},
+				"002": {Version: "002"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.MarkMigrationApplied("002")
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.MarkAppliedCallCount, 1)
+}
+
+func TestMarkMigrationUnapplied_NotApplied(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		&bolttest.MockMigrationFsRepo{},
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.MarkMigrationUnapplied("001")
+
+	check.ErrorContains(t, err, "migration with version 001 isn't applied")
+	check.Equal(t, migrationDbRepo.MarkUnappliedCallCount, 0)
+}
+
+func TestMarkMigrationUnapplied_MissingLocally(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.MarkMigrationUnapplied("001")
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.MarkUnappliedCallCount, 1)
+	check.Equal(t, migrationDbRepo.RevertCallCount, 0)
+	check.Equal(t, migrationDbRepo.RevertWithTxCallCount, 0)
+}
+
+func TestListMigrationStatuses_Dirty(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true, Checksum: models.Checksum("changed"), Dirty: true},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	statuses, err := svc.ListMigrationStatuses()
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(statuses), 1)
+	check.Equal(t, statuses[0].State, models.MigrationStateDirty)
+	check.Equal(t, migrationFsRepo.ReadUpgradeScriptCallCount, 0)
+}
+
+func TestApplyAllMigrations_DirtyMigration(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+				"002": {Version: "002", Message: "second"},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true, Dirty: true},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyAllMigrations(ApplyOptions{})
+
+	assert.ErrorIs(t, err, ErrDirtyMigration)
+	check.ErrorContains(t, err, "'bolt resolve -version 001' with -applied or -rolled-back")
+	check.Equal(t, migrationFsRepo.ReadUpgradeScriptCallCount, 0)
+	check.Equal(t, migrationDbRepo.ApplyCallCount, 0)
+	check.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 0)
+}
+
+func TestRevertAllMigrations_DirtyMigration(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+				"002": {Version: "002", Message: "second"},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+				"002": {Version: "002", Applied: true, Dirty: true},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.RevertAllMigrations(RevertOptions{})
+
+	assert.ErrorIs(t, err, ErrDirtyMigration)
+	check.Equal(t, migrationFsRepo.ReadDowngradeScriptCallCount, 0)
+	check.Equal(t, migrationDbRepo.RevertCallCount, 0)
+	check.Equal(t, migrationDbRepo.RevertWithTxCallCount, 0)
+}
+
+func TestResolveMigration_Applied(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true, Dirty: true},
+			},
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Message: "first"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ResolveMigration("001", true)
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.MarkCleanCallCount, 1)
+	check.Equal(t, migrationDbRepo.MarkUnappliedCallCount, 0)
+}
+
+func TestResolveMigration_RolledBack(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true, Dirty: true},
+			},
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ResolveMigration("001", false)
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.MarkCleanCallCount, 0)
+	check.Equal(t, migrationDbRepo.MarkUnappliedCallCount, 1)
+}
+
+func TestResolveMigration_NotDirty(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		&bolttest.MockMigrationFsRepo{},
+		configloader.Config{},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ResolveMigration("001", true)
+
+	check.ErrorContains(t, err, "migration with version 001 isn't dirty, nothing to resolve")
+	check.Equal(t, migrationDbRepo.MarkCleanCallCount, 0)
+}
+
+func TestSquashToVersion_SquashesAppliedMigrations(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+				"002": {Version: "002", Applied: true},
+			},
+		},
+		DumpSchemaReturnValue: bolttest.DumpSchemaReturnValue{
+			Schema: "CREATE TABLE users(id INT);\n",
+		},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001"},
+				"002": {Version: "002"},
+				"003": {Version: "003"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.SquashToVersion("002", "migrations_archive")
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.DumpSchemaCallCount, 1)
+	check.Equal(t, migrationFsRepo.ArchiveCallCount, 2)
+	check.Equal(t, migrationFsRepo.WriteCallCount, 1)
+	check.Equal(t, migrationDbRepo.SquashCallCount, 1)
+	check.DeepEqual(t, migrationDbRepo.SquashedVersions, []string{"001", "002"})
+}
+
+func TestSquashToVersion_UpdatesMigrationsTable(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	migrationsConfig := configloader.MigrationsConfig{
+		DirectoryPath: filepath.Join(t.TempDir(), "migrations"),
+		VersionStyle:  configloader.VersionStyleSequential,
+	}
+	migrationDbRepo, err := repositories.NewMigrationDBRepo(
+		bolttest.NewTestConnectionConfig().MigrationsTable,
+		db,
+	)
+	assert.Nil(t, err)
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&migrationsConfig)
+	assert.Nil(t, err)
+	scripts := map[string]string{
+		"001_create_tmp.sql": "-- migrate:up\nCREATE TABLE tmp(id INT PRIMARY KEY);\n" +
+			"-- migrate:down\nDROP TABLE tmp;\n",
+		"002_add_name.sql": "-- migrate:up\nALTER TABLE tmp ADD name VARCHAR(255);\n" +
+			"-- migrate:down\n",
+		"003_add_email.sql": "-- migrate:up\nALTER TABLE tmp ADD email VARCHAR(255);\n" +
+			"-- migrate:down\n",
+	}
+	for name, contents := range scripts {
+		err = os.WriteFile(
+			filepath.Join(migrationsConfig.DirectoryPath, name),
+			[]byte(contents),
+			0644,
+		)
+		assert.Nil(t, err)
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{Migrations: migrationsConfig},
+		bolttest.NullOutputter{},
+	)
+	err = svc.ApplyUpToVersion("002", ApplyOptions{})
+	assert.Nil(t, err)
+
+	err = svc.SquashToVersion("002", filepath.Join(t.TempDir(), "archive"))
+	assert.Nil(t, err)
+
+	statuses, err := svc.ListMigrationStatuses()
+	assert.Nil(t, err)
+	assert.Equal(t, len(statuses), 2)
+	check.Equal(t, statuses[0].Migration.Name(), "002_squashed")
+	check.Equal(t, statuses[0].State, models.MigrationStateApplied)
+	check.Equal(t, statuses[1].Migration.Name(), "003_add_email")
+	check.Equal(t, statuses[1].State, models.MigrationStatePending)
+}
+
+func TestSquashToVersion_OtherDatabaseAppliedOriginals(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	migrationsConfig := configloader.MigrationsConfig{
+		DirectoryPath: filepath.Join(t.TempDir(), "migrations"),
+		VersionStyle:  configloader.VersionStyleSequential,
+	}
+	migrationFsRepo, err := repositories.NewMigrationFsRepo(&migrationsConfig)
+	assert.Nil(t, err)
+	scripts := map[string]string{
+		"001_create_tmp.sql": "-- migrate:up\nCREATE TABLE tmp(id INT PRIMARY KEY);\n" +
+			"-- migrate:down\nDROP TABLE tmp;\n",
+		"002_add_name.sql": "-- migrate:up\nALTER TABLE tmp ADD name VARCHAR(255);\n" +
+			"-- migrate:down\n",
+		"003_add_email.sql": "-- migrate:up\nALTER TABLE tmp ADD email VARCHAR(255);\n" +
+			"-- migrate:down\n",
+	}
+	for name, contents := range scripts {
+		err = os.WriteFile(
+			filepath.Join(migrationsConfig.DirectoryPath, name),
+			[]byte(contents),
+			0644,
+		)
+		assert.Nil(t, err)
+	}
+	newService := func(migrationsTable string) MigrationService {
+		migrationDbRepo, err := repositories.NewMigrationDBRepo(migrationsTable, db)
+		assert.Nil(t, err)
+		return NewMigrationService(
+			migrationDbRepo,
+			migrationFsRepo,
+			configloader.Config{Migrations: migrationsConfig},
+			bolttest.NullOutputter{},
+		)
+	}
+	// The other database is stood in for by its own migrations table,
+	// which applies the originals before the database being squashed.
+	otherMigrationsTable := "bolt_migrations_other"
+	t.Cleanup(func() { bolttest.DropTable(t, db, otherMigrationsTable) })
+	otherSvc := newService(otherMigrationsTable)
+	err = otherSvc.ApplyUpToVersion("002", ApplyOptions{})
+	assert.Nil(t, err)
+	bolttest.DropTable(t, db, "tmp")
+	svc := newService(bolttest.NewTestConnectionConfig().MigrationsTable)
+	err = svc.ApplyUpToVersion("002", ApplyOptions{})
+	assert.Nil(t, err)
+
+	err = svc.SquashToVersion("002", filepath.Join(t.TempDir(), "archive"))
+	assert.Nil(t, err)
+
+	statuses, err := otherSvc.ListMigrationStatuses()
+	assert.Nil(t, err)
+	assert.Equal(t, len(statuses), 2)
+	check.Equal(t, statuses[0].Migration.Name(), "002_squashed")
+	check.Equal(t, statuses[0].State, models.MigrationStateApplied)
+	check.Equal(t, statuses[1].Migration.Name(), "003_add_email")
+	check.Equal(t, statuses[1].State, models.MigrationStatePending)
+
+	current, pendingCount, err := otherSvc.CurrentMigration()
+	assert.Nil(t, err)
+	check.Equal(t, current.Name(), "002_squashed")
+	check.Equal(t, pendingCount, 1)
+
+	err = otherSvc.ApplyAllMigrations(ApplyOptions{})
+	assert.Nil(t, err)
+	statuses, err = otherSvc.ListMigrationStatuses()
+	assert.Nil(t, err)
+	assert.Equal(t, len(statuses), 2)
+	check.Equal(t, statuses[0].State, models.MigrationStateApplied)
+	check.Equal(t, statuses[1].State, models.MigrationStateApplied)
+}
+
+func TestSquashToVersion_UndoesSquashOnFailure(t *testing.T) {
+	type test struct {
+		archiveErr           error
+		squashErr            error
+		expectedErr          string
+		expectedRestoreCount int
+	}
+
+	tests := []test{
+		// Ensure nothing archived is left behind when archiving fails.
+		{
+			archiveErr:           errors.New("archive failed"),
+			expectedErr:          "unable to archive migration 001_: archive failed",
+			expectedRestoreCount: 0,
+		},
+		// Ensure the originals are moved back when the migrations
+		// table can't be updated.
+		{
+			squashErr:            errors.New("squash failed"),
+			expectedErr:          "unable to record squashed migration 002_squashed: squash failed",
+			expectedRestoreCount: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		migrationDbRepo := &bolttest.MockMigrationDBRepo{
+			ListReturnValue: bolttest.ListReturnValue{
+				Migrations: map[string]*models.Migration{
+					"001": {Version: "001", Applied: true},
+					"002": {Version: "002", Applied: true},
+				},
+			},
+			SquashReturnValue: bolttest.SquashReturnValue{Err: tc.squashErr},
+		}
+		migrationFsRepo := &bolttest.MockMigrationFsRepo{
+			ListReturnValue: bolttest.ListReturnValue{
+				Migrations: map[string]*models.Migration{
+					"001": {Version: "001"},
+					"002": {Version: "002"},
+				},
+			},
+			ArchiveReturnValue: bolttest.ArchiveReturnValue{Err: tc.archiveErr},
+		}
+		svc := NewMigrationService(
+			migrationDbRepo,
+			migrationFsRepo,
+			configloader.Config{
+				Migrations: configloader.MigrationsConfig{
+					VersionStyle: configloader.VersionStyleSequential,
+				},
+			},
+			bolttest.NullOutputter{},
+		)
+
+		err := svc.SquashToVersion("002", "migrations_archive")
+
+		check.ErrorContains(t, err, tc.expectedErr)
+		check.Equal(t, migrationFsRepo.WriteCallCount, 1)
+		check.Equal(t, migrationFsRepo.RestoreCallCount, tc.expectedRestoreCount)
+		check.Equal(t, migrationFsRepo.RemoveCallCount, 1)
+	}
+}
+
+func TestSquashToVersion_DatabaseNotAtVersion(t *testing.T) {
+	type test struct {
+		appliedMigrations map[string]*models.Migration
+		expectedErr       string
+	}
+
+	tests := []test{
+		// Ensure a migration being squashed must be applied.
+		{
+			appliedMigrations: map[string]*models.Migration{
+				"002": {Version: "002", Applied: true},
+			},
+			expectedErr: "migration 001_ isn't applied",
+		},
+		// Ensure a migration after the version can't be applied.
+		{
+			appliedMigrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+				"002": {Version: "002", Applied: true},
+				"003": {Version: "003", Applied: true},
+			},
+			expectedErr: "migration 003_ is applied",
+		},
+	}
+
+	for _, tc := range tests {
+		migrationDbRepo := &bolttest.MockMigrationDBRepo{
+			ListReturnValue: bolttest.ListReturnValue{
+				Migrations: tc.appliedMigrations,
+			},
+		}
+		migrationFsRepo := &bolttest.MockMigrationFsRepo{
+			ListReturnValue: bolttest.ListReturnValue{
+				Migrations: map[string]*models.Migration{
+					"001": {Version: "001"},
+					"002": {Version: "002"},
+					"003": {Version: "003"},
+				},
+			},
+		}
+		svc := NewMigrationService(
+			migrationDbRepo,
+			migrationFsRepo,
+			configloader.Config{
+				Migrations: configloader.MigrationsConfig{
+					VersionStyle: configloader.VersionStyleSequential,
+				},
+			},
+			bolttest.NullOutputter{},
+		)
+
+		err := svc.SquashToVersion("002", "migrations_archive")
+
+		check.ErrorContains(t, err, tc.expectedErr)
+		check.Equal(t, migrationDbRepo.DumpSchemaCallCount, 0)
+		check.Equal(t, migrationFsRepo.ArchiveCallCount, 0)
+		check.Equal(t, migrationFsRepo.WriteCallCount, 0)
+	}
+}
+
+func TestSquashToVersion_DumpSchemaErr(t *testing.T) {
+	expectedErr := errors.New("dump failed")
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+		DumpSchemaReturnValue: bolttest.DumpSchemaReturnValue{Err: expectedErr},
+	}
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001"},
+			},
+		},
+	}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.SquashToVersion("001", "migrations_archive")
+
+	check.ErrorIs(t, err, expectedErr)
+	check.Equal(t, migrationFsRepo.ArchiveCallCount, 0)
+}
+
+func TestApplyMigrationsByVersion_AppliesOnlyGivenVersions(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: false},
+				"002": {Version: "002", Applied: false},
+				"003": {Version: "003", Applied: false},
+			},
+		},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyMigrationsByVersion([]string{"003", "001"})
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 2)
+}
+
+func TestApplyMigrationsByVersion_MissingMigration(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: false},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ApplyMigrationsByVersion([]string{"001", "002"})
+
+	check.ErrorContains(t, err, "migration with version 002 does not exist")
+	check.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 0)
+	check.Equal(t, migrationDbRepo.ApplyCallCount, 0)
+}
+
+func TestResetMigrations_RevertsAndAppliesMigrations(t *testing.T) {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: false},
+				"002": {Version: "002", Applied: false},
+			},
+		},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ResetMigrations(RevertOptions{})
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.RevertWithTxCallCount, 0)
+	check.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 2)
+}
+
+func TestResetMigrations_RevertErr(t *testing.T) {
+	expectedErr := errors.New("error!")
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+		ReadDowngradeScriptReturnValue: bolttest.ReadDowngradeScriptReturnValue{
+			Err: expectedErr,
+		},
+	}
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+	svc := NewMigrationService(
+		migrationDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+
+	err := svc.ResetMigrations(RevertOptions{})
+
+	check.ErrorIs(t, err, expectedErr)
+	check.Equal(t, migrationDbRepo.ApplyCallCount, 0)
+	check.Equal(t, migrationDbRepo.ApplyWithTxCallCount, 0)
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/bolt/internal/output"
+	"github.com/eugenetriguba/bolt/internal/repositories"
+)
+
+const schemaFileHeader = "-- This file is generated by bolt. Do not edit it by hand.\n" +
+	"-- It is the schema of the database after the applied migrations.\n"
+
+// appliedVersionPrefix marks the lines at the end of a schema dump
+// that record which migrations had been applied when it was dumped.
+// They're comments so that the dump can be executed as-is.
+const appliedVersionPrefix = "-- bolt:applied "
+
+type SchemaService struct {
+	dbRepo       repositories.MigrationDBRepo
+	schemaFsRepo repositories.SchemaFsRepo
+	outputter    output.Outputter
+}
+
+func NewSchemaService(
+	dbRepo repositories.MigrationDBRepo,
+	schemaFsRepo repositories.SchemaFsRepo,
+	outputter output.Outputter,
+) SchemaService {
+	return SchemaService{
+		dbRepo:       dbRepo,
+		schemaFsRepo: schemaFsRepo,
+		outputter:    outputter,
+	}
+}
+
+// DumpSchema writes the database's current schema to the
+// schema file.
+func (ss SchemaService) DumpSchema() error {
+	schema, err := ss.dbRepo.DumpSchema()
+	if err != nil {
+		return err
+	}
+
+	appliedMigrations, err := ss.dbRepo.List()
+	if err != nil {
+		return fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+
+	versions := make([]string, 0, len(appliedMigrations))
+	for version := range appliedMigrations {
+		versions = append(versions, version)
+	}
+	// Versions of either style sort correctly by their length
+	// and then lexically since they're zero-padded numbers.
+	sort.Slice(versions, func(i, j int) bool {
+		if len(versions[i]) != len(versions[j]) {
+			return len(versions[i]) < len(versions[j])
+		}
+		return versions[i] < versions[j]
+	})
+
+	var dump strings.Builder
+	dump.WriteString(schemaFileHeader + "\n")
+	dump.WriteString(normalizeSchema(schema))
+	if len(versions) > 0 {
+		dump.WriteString("\n")
+	}
+	for _, version := range versions {
+		dump.WriteString(appliedVersionPrefix + version + "\n")
+	}
+
+	err = ss.schemaFsRepo.Write(dump.String())
+	if err != nil {
+		return err
+	}
+
+	ss.outputter.Output("Dumped database schema.")
+	return nil
+}
+
+// LoadSchema executes the schema file against the database and
+// records the migrations that were applied when it was dumped as
+// applied. It is meant for fresh databases, so it refuses to run
+// when any migrations are already applied.
+func (ss SchemaService) LoadSchema() error {
+	appliedMigrations, err := ss.dbRepo.List()
+	if err != nil {
+		return fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+	if len(appliedMigrations) > 0 {
+		return errors.New(
+			"the database already has migrations applied, a schema " +
+				"can only be loaded into a fresh database",
+		)
+	}
+
+	schema, err := ss.schemaFsRepo.Read()
+	if err != nil {
+		return err
+	}
+
+	versions := parseAppliedVersions(schema)
+	err = ss.dbRepo.LoadSchema(schema, versions)
+	if err != nil {
+		return fmt.Errorf("unable to load schema: %w", err)
+	}
+
+	ss.outputter.Output(
+		fmt.Sprintf("Loaded database schema with %d applied migrations.", len(versions)),
+	)
+	return nil
+}
+
+// DiffSchema finds the schema drift between the database and its
+// migrations. The migrations that are applied to the database are
+// applied to the scratch database, which must be empty, and the
+// two databases' schemas are compared. Every difference is output
+// and returned, so no differences means there is no drift.
+func (ss SchemaService) DiffSchema(
+	scratchDBRepo repositories.MigrationDBRepo,
+	scratchMigrationService MigrationService,
+) ([]models.SchemaDifference, error) {
+	scratchSchema, err := scratchDBRepo.InspectSchema()
+	if err != nil {
+		return nil, err
+	}
+	scratchMigrations, err := scratchDBRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to list out applied migrations from scratch db: %w",
+			err,
+		)
+	}
+	if len(scratchSchema.Tables) > 0 || len(scratchMigrations) > 0 {
+		return nil, errors.New("the scratch database must be empty")
+	}
+
+	appliedMigrations, err := ss.dbRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"unable to list out applied migrations from remote db: %w",
+			err,
+		)
+	}
+	versions := make([]string, 0, len(appliedMigrations))
+	for version := range appliedMigrations {
+		versions = append(versions, version)
+	}
+
+	ss.outputter.Output("Applying migrations to the scratch database..")
+	err = scratchMigrationService.ApplyMigrationsByVersion(versions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to apply migrations to scratch db: %w", err)
+	}
+
+	expectedSchema, err := scratchDBRepo.InspectSchema()
+	if err != nil {
+		return nil, err
+	}
+	actualSchema, err := ss.dbRepo.InspectSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	differences := models.DiffSchemas(expectedSchema, actualSchema)
+	if len(differences) == 0 {
+		ss.outputter.Output("No schema drift detected.")
+		return differences, nil
+	}
+
+	headers := []string{"Table", "Kind", "Name", "Migrations", "Database"}
+	rows := make([][]string, len(differences))
+	for i, difference := range differences {
+		rows[i] = []string{
+			difference.Table,
+			string(difference.Kind),
+			difference.Name,
+			describeSchemaObject(difference.Expected),
+			describeSchemaObject(difference.Actual),
+		}
+	}
+	err = ss.outputter.Table(headers, rows)
+	if err != nil {
+		return nil, fmt.Errorf("unable to output schema differences as table: %w", err)
+	}
+
+	return differences, nil
+}
+
+func describeSchemaObject(description string) string {
+	if description == "" {
+		return "missing"
+	}
+	return description
+}
+
+// parseAppliedVersions extracts the versions of the migrations
+// that were applied when the schema was dumped.
+func parseAppliedVersions(schema string) []string {
+	versions := make([]string, 0)
+	for _, line := range strings.Split(schema, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, appliedVersionPrefix) {
+			version := strings.TrimSpace(strings.TrimPrefix(line, appliedVersionPrefix))
+			if version != "" {
+				versions = append(versions, version)
+			}
+		}
+	}
+	return versions
+}
+
+// normalizeSchema makes the schema's formatting consistent
+// regardless of the database or platform it came from, so that
+// the only differences between two dumps are schema changes.
+func normalizeSchema(schema string) string {
+	schema = strings.ReplaceAll(schema, "\r\n", "\n")
+
+	lines := strings.Split(schema, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+}
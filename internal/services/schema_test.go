@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/bolttest"
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/eugenetriguba/bolt/internal/sqlparse"
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func TestDumpSchema_WritesNormalizedSchema(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		DumpSchemaReturnValue: bolttest.DumpSchemaReturnValue{
+			Schema: "CREATE TABLE users (  \r\n    id INT\r\n);\r\n\r\n",
+		},
+	}
+	schemaFsRepo := &bolttest.MockSchemaFsRepo{}
+	svc := NewSchemaService(migrationDbRepo, schemaFsRepo, bolttest.NullOutputter{})
+
+	err := svc.DumpSchema()
+
+	assert.Nil(t, err)
+	check.Equal(t, schemaFsRepo.WriteCallCount, 1)
+	check.Equal(
+		t,
+		schemaFsRepo.WrittenSchema,
+		schemaFileHeader+"\nCREATE TABLE users (\n    id INT\n);\n",
+	)
+}
+
+func TestDumpSchema_DumpSchemaErr(t *testing.T) {
+	expectedErr := errors.New("dump failed")
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		DumpSchemaReturnValue: bolttest.DumpSchemaReturnValue{Err: expectedErr},
+	}
+	schemaFsRepo := &bolttest.MockSchemaFsRepo{}
+	svc := NewSchemaService(migrationDbRepo, schemaFsRepo, bolttest.NullOutputter{})
+
+	err := svc.DumpSchema()
+
+	check.ErrorIs(t, err, expectedErr)
+	check.Equal(t, schemaFsRepo.WriteCallCount, 0)
+}
+
+func TestDumpSchema_WriteErr(t *testing.T) {
+	expectedErr := errors.New("write failed")
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+	schemaFsRepo := &bolttest.MockSchemaFsRepo{
+		WriteReturnValue: bolttest.WriteReturnValue{Err: expectedErr},
+	}
+	svc := NewSchemaService(migrationDbRepo, schemaFsRepo, bolttest.NullOutputter{})
+
+	err := svc.DumpSchema()
+
+	check.ErrorIs(t, err, expectedErr)
+}
+
+func TestDumpSchema_RecordsAppliedVersions(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		DumpSchemaReturnValue: bolttest.DumpSchemaReturnValue{
+			Schema: "CREATE TABLE users(id INT);\n",
+		},
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"010": {Version: "010", Applied: true},
+				"002": {Version: "002", Applied: true},
+				"001": {Version: "001", Applied: true},
+			},
+		},
+	}
+	schemaFsRepo := &bolttest.MockSchemaFsRepo{}
+	svc := NewSchemaService(migrationDbRepo, schemaFsRepo, bolttest.NullOutputter{})
+
+	err := svc.DumpSchema()
+
+	assert.Nil(t, err)
+	check.Equal(
+		t,
+		schemaFsRepo.WrittenSchema,
+		schemaFileHeader+"\nCREATE TABLE users(id INT);\n\n"+
+			"-- bolt:applied 001\n-- bolt:applied 002\n-- bolt:applied 010\n",
+	)
+}
+
+func TestLoadSchema_LoadsSchemaAndAppliedVersions(t *testing.T) {
+	schema := schemaFileHeader + "\nCREATE TABLE users(id INT);\n\n" +
+		"-- bolt:applied 001\n-- bolt:applied 002\n"
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	schemaFsRepo := &bolttest.MockSchemaFsRepo{
+		ReadReturnValue: bolttest.ReadReturnValue{Contents: schema},
+	}
+	svc := NewSchemaService(migrationDbRepo, schemaFsRepo, bolttest.NullOutputter{})
+
+	err := svc.LoadSchema()
+
+	assert.Nil(t, err)
+	check.Equal(t, migrationDbRepo.LoadSchemaCallCount, 1)
+	check.Equal(t, migrationDbRepo.LoadedSchema, schema)
+	check.DeepEqual(t, migrationDbRepo.LoadedVersions, []string{"001", "002"})
+}
+
+func TestLoadSchema_DatabaseNotFresh(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+	}
+	schemaFsRepo := &bolttest.MockSchemaFsRepo{}
+	svc := NewSchemaService(migrationDbRepo, schemaFsRepo, bolttest.NullOutputter{})
+
+	err := svc.LoadSchema()
+
+	check.ErrorContains(t, err, "can only be loaded into a fresh database")
+	check.Equal(t, schemaFsRepo.ReadCallCount, 0)
+	check.Equal(t, migrationDbRepo.LoadSchemaCallCount, 0)
+}
+
+func TestLoadSchema_ReadErr(t *testing.T) {
+	expectedErr := errors.New("read failed")
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{},
+		},
+	}
+	schemaFsRepo := &bolttest.MockSchemaFsRepo{
+		ReadReturnValue: bolttest.ReadReturnValue{Err: expectedErr},
+	}
+	svc := NewSchemaService(migrationDbRepo, schemaFsRepo, bolttest.NullOutputter{})
+
+	err := svc.LoadSchema()
+
+	check.ErrorIs(t, err, expectedErr)
+	check.Equal(t, migrationDbRepo.LoadSchemaCallCount, 0)
+}
+
+func newScratchMigrationService(
+	scratchDbRepo *bolttest.MockMigrationDBRepo,
+	migrations map[string]*models.Migration,
+) MigrationService {
+	migrationFsRepo := &bolttest.MockMigrationFsRepo{
+		ListReturnValue: bolttest.ListReturnValue{Migrations: migrations},
+		ReadUpgradeScriptReturnValue: bolttest.ReadUpgradeScriptReturnValue{
+			Script: sqlparse.MigrationScript{
+				Contents: "SELECT 1;",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+		},
+	}
+	return NewMigrationService(
+		scratchDbRepo,
+		migrationFsRepo,
+		configloader.Config{
+			Migrations: configloader.MigrationsConfig{
+				VersionStyle: configloader.VersionStyleSequential,
+			},
+		},
+		bolttest.NullOutputter{},
+	)
+}
+
+func TestDiffSchema_NoDrift(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		ListReturnValue: bolttest.ListReturnValue{
+			Migrations: map[string]*models.Migration{
+				"001": {Version: "001", Applied: true},
+			},
+		},
+	}
+	scratchDbRepo := &bolttest.MockMigrationDBRepo{}
+	scratchMigrationService := newScratchMigrationService(
+		scratchDbRepo,
+		map[string]*models.Migration{
+			"001": {Version: "001", Applied: false},
+			"002": {Version: "002", Applied: false},
+		},
+	)
+	svc := NewSchemaService(migrationDbRepo, &bolttest.MockSchemaFsRepo{}, bolttest.NullOutputter{})
+
+	differences, err := svc.DiffSchema(scratchDbRepo, scratchMigrationService)
+
+	assert.Nil(t, err)
+	check.Equal(t, len(differences), 0)
+	// Only the migrations applied to the database are applied
+	// to the scratch database.
+	check.Equal(t, scratchDbRepo.ApplyWithTxCallCount, 1)
+	check.Equal(t, scratchDbRepo.InspectSchemaCallCount, 2)
+	check.Equal(t, migrationDbRepo.InspectSchemaCallCount, 1)
+}
+
+func TestDiffSchema_ReportsDrift(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{
+		InspectSchemaReturnValue: bolttest.InspectSchemaReturnValue{
+			Schema: models.Schema{Tables: []models.Table{{Name: "hotfix"}}},
+		},
+	}
+	scratchDbRepo := &bolttest.MockMigrationDBRepo{}
+	scratchMigrationService := newScratchMigrationService(
+		scratchDbRepo,
+		map[string]*models.Migration{},
+	)
+	svc := NewSchemaService(migrationDbRepo, &bolttest.MockSchemaFsRepo{}, bolttest.NullOutputter{})
+
+	differences, err := svc.DiffSchema(scratchDbRepo, scratchMigrationService)
+
+	assert.Nil(t, err)
+	check.DeepEqual(t, differences, []models.SchemaDifference{
+		{Kind: models.SchemaObjectTable, Table: "hotfix", Name: "hotfix", Actual: "exists"},
+	})
+}
+
+func TestDiffSchema_ScratchDatabaseNotEmpty(t *testing.T) {
+	migrationDbRepo := &bolttest.MockMigrationDBRepo{}
+	scratchDbRepo := &bolttest.MockMigrationDBRepo{
+		InspectSchemaReturnValue: bolttest.InspectSchemaReturnValue{
+			Schema: models.Schema{Tables: []models.Table{{Name: "users"}}},
+		},
+	}
+	scratchMigrationService := newScratchMigrationService(
+		scratchDbRepo,
+		map[string]*models.Migration{},
+	)
+	svc := NewSchemaService(migrationDbRepo, &bolttest.MockSchemaFsRepo{}, bolttest.NullOutputter{})
+
+	_, err := svc.DiffSchema(scratchDbRepo, scratchMigrationService)
+
+	check.ErrorContains(t, err, "the scratch database must be empty")
+	check.Equal(t, migrationDbRepo.InspectSchemaCallCount, 0)
+}
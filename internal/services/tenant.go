@@ -0,0 +1,42 @@
+package services
+
+import "sync"
+
+// TenantResult is the outcome of migrating a single tenant.
+type TenantResult struct {
+	Tenant string
+	// Version is the tenant's current version once it has been
+	// migrated. It is empty when no migrations are applied.
+	Version string
+	// Err is why the tenant failed to be migrated.
+	Err error
+}
+
+// MigrateTenants calls migrate for each tenant, with at most
+// parallelism tenants being migrated at a time. A tenant failing
+// to be migrated doesn't stop the other tenants from being
+// migrated. The results are in the same order as the tenants.
+func MigrateTenants(
+	tenants []string,
+	parallelism int,
+	migrate func(tenant string) (version string, err error),
+) []TenantResult {
+	results := make([]TenantResult, len(tenants))
+	slots := make(chan struct{}, max(parallelism, 1))
+
+	var wg sync.WaitGroup
+	for i, tenant := range tenants {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, tenant string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			version, err := migrate(tenant)
+			results[i] = TenantResult{Tenant: tenant, Version: version, Err: err}
+		}(i, tenant)
+	}
+	wg.Wait()
+
+	return results
+}
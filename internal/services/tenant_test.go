@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
+)
+
+func TestMigrateTenants_KeepsOrderAndIsolatesFailures(t *testing.T) {
+	tenants := []string{"acme", "globex", "initech"}
+
+	results := MigrateTenants(tenants, 2, func(tenant string) (string, error) {
+		if tenant == "globex" {
+			return "001", errors.New("migration failed")
+		}
+		return "002", nil
+	})
+
+	assert.Equal(t, len(results), 3)
+	check.Equal(t, results[0], TenantResult{Tenant: "acme", Version: "002"})
+	check.Equal(t, results[1].Tenant, "globex")
+	check.Equal(t, results[1].Version, "001")
+	check.ErrorContains(t, results[1].Err, "migration failed")
+	check.Equal(t, results[2], TenantResult{Tenant: "initech", Version: "002"})
+}
+
+func TestMigrateTenants_BoundsParallelism(t *testing.T) {
+	tenants := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	var mu sync.Mutex
+	running := 0
+	maxRunning := 0
+
+	results := MigrateTenants(tenants, 3, func(tenant string) (string, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return "", nil
+	})
+
+	check.Equal(t, len(results), len(tenants))
+	check.True(t, maxRunning <= 3)
+	check.True(t, maxRunning > 1)
+}
+
+func TestMigrateTenants_NoTenants(t *testing.T) {
+	results := MigrateTenants(nil, 1, func(tenant string) (string, error) {
+		t.Fatal("migrate should not be called")
+		return "", nil
+	})
+
+	check.Equal(t, len(results), 0)
+}
@@ -16,8 +16,20 @@ type MigrationScript struct {
 	Options  ExecutionOptions
 }
 
+// NewMigrationScript creates a MigrationScript with the
+// default execution options.
+func NewMigrationScript(contents string) MigrationScript {
+	return MigrationScript{Contents: contents, Options: defaultExecutionOptions()}
+}
+
 type ExecutionOptions struct {
 	UseTransaction bool
+	// Irreversible marks a downgrade script as one that cannot
+	// undo its upgrade script.
+	Irreversible bool
+	// Squashes lists the versions of the migrations that an upgrade
+	// script was squashed from, including its own version.
+	Squashes []string
 }
 
 const (
@@ -30,6 +42,8 @@ const (
 	upgradeScriptDeliminator   = "-- migrate:up"
 	downgradeScriptDeliminator = "-- migrate:down"
 	transactionOptionName      = "transaction"
+	irreversibleOptionName     = "irreversible"
+	squashesOptionName         = "squashes"
 )
 
 type sqlParser struct{}
@@ -79,16 +93,55 @@ func (sp *sqlParser) Parse(reader io.Reader) (MigrationScript, MigrationScript,
 // parseExecutionOptions extracts the execution options for a
 // upgrade or downgrade migration script.
 func parseExecutionOptions(line string) ExecutionOptions {
-	options := ExecutionOptions{UseTransaction: true}
+	options := defaultExecutionOptions()
 
 	parts := strings.Split(line, " ")
 	for _, part := range parts {
 		part = strings.ToLower(part)
-		if strings.Contains(part, "transaction:") {
+		if strings.Contains(part, transactionOptionName+":") {
 			option := strings.Split(part, ":")[1]
 			options.UseTransaction = option != "false"
+		} else if part == irreversibleOptionName {
+			options.Irreversible = true
+		} else if versions, ok := strings.CutPrefix(part, squashesOptionName+":"); ok {
+			options.Squashes = strings.Split(versions, ",")
 		}
 	}
 
 	return options
 }
+
+func defaultExecutionOptions() ExecutionOptions {
+	return ExecutionOptions{UseTransaction: true}
+}
+
+// FormatUpgradeScript renders the upgrade script with its
+// section comment so that it can be parsed back out by Parse.
+func FormatUpgradeScript(script MigrationScript) string {
+	return formatScript(upgradeScriptDeliminator, script)
+}
+
+// FormatDowngradeScript renders the downgrade script with its
+// section comment so that it can be parsed back out by Parse.
+func FormatDowngradeScript(script MigrationScript) string {
+	return formatScript(downgradeScriptDeliminator, script)
+}
+
+func formatScript(deliminator string, script MigrationScript) string {
+	sectionComment := deliminator
+	if !script.Options.UseTransaction {
+		sectionComment += " " + transactionOptionName + ":false"
+	}
+	if script.Options.Irreversible {
+		sectionComment += " " + irreversibleOptionName
+	}
+	if len(script.Options.Squashes) != 0 {
+		sectionComment += " " + squashesOptionName + ":" + strings.Join(script.Options.Squashes, ",")
+	}
+
+	contents := script.Contents
+	if contents != "" && !strings.HasSuffix(contents, "\n") {
+		contents += "\n"
+	}
+	return sectionComment + "\n" + contents
+}
@@ -178,6 +178,39 @@ func TestSqlParser_Parse(t *testing.T) {
 				Options:  sqlparse.ExecutionOptions{UseTransaction: false},
 			},
 		},
+		{
+			migration: `
+			-- migrate:up
+			CREATE TABLE users(id int PRIMARY KEY);
+			-- migrate:down irreversible`,
+			expectedUpgradeScript: sqlparse.MigrationScript{
+				Contents: "CREATE TABLE users(id int PRIMARY KEY);\n",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: true},
+			},
+			expectedDowngradeScript: sqlparse.MigrationScript{
+				Contents: "",
+				Options: sqlparse.ExecutionOptions{
+					UseTransaction: true,
+					Irreversible:   true,
+				},
+			},
+		},
+		{
+			migration: `
+			-- migrate:down transaction:false IRREVERSIBLE
+			SELECT 1;`,
+			expectedUpgradeScript: sqlparse.MigrationScript{
+				Contents: "",
+				Options:  sqlparse.ExecutionOptions{},
+			},
+			expectedDowngradeScript: sqlparse.MigrationScript{
+				Contents: "SELECT 1;\n",
+				Options: sqlparse.ExecutionOptions{
+					UseTransaction: false,
+					Irreversible:   true,
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -219,3 +252,58 @@ func TestSqlParserParseError(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.ErrorContains(t, err, "unwanted input encountered")
 }
+
+func TestFormatScripts_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		upgradeScript   sqlparse.MigrationScript
+		downgradeScript sqlparse.MigrationScript
+		expected        string
+	}{
+		{
+			upgradeScript:   sqlparse.NewMigrationScript(""),
+			downgradeScript: sqlparse.NewMigrationScript(""),
+			expected:        "-- migrate:up\n\n-- migrate:down\n",
+		},
+		{
+			upgradeScript: sqlparse.NewMigrationScript("CREATE TABLE users(id int PRIMARY KEY);"),
+			downgradeScript: sqlparse.MigrationScript{
+				Contents: "DROP TABLE users;\n",
+				Options:  sqlparse.ExecutionOptions{UseTransaction: false},
+			},
+			expected: "-- migrate:up\nCREATE TABLE users(id int PRIMARY KEY);\n\n" +
+				"-- migrate:down transaction:false\nDROP TABLE users;\n",
+		},
+		{
+			upgradeScript: sqlparse.NewMigrationScript("DROP TABLE users;\n"),
+			downgradeScript: sqlparse.MigrationScript{
+				Options: sqlparse.ExecutionOptions{UseTransaction: true, Irreversible: true},
+			},
+			expected: "-- migrate:up\nDROP TABLE users;\n\n-- migrate:down irreversible\n",
+		},
+		{
+			upgradeScript: sqlparse.MigrationScript{
+				Contents: "CREATE TABLE users(id int PRIMARY KEY);\n",
+				Options: sqlparse.ExecutionOptions{
+					UseTransaction: true,
+					Squashes:       []string{"001", "002"},
+				},
+			},
+			downgradeScript: sqlparse.NewMigrationScript(""),
+			expected: "-- migrate:up squashes:001,002\nCREATE TABLE users(id int PRIMARY KEY);\n\n" +
+				"-- migrate:down\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		formatted := sqlparse.FormatUpgradeScript(tc.upgradeScript) + "\n" +
+			sqlparse.FormatDowngradeScript(tc.downgradeScript)
+		check.Equal(t, formatted, tc.expected)
+
+		upgradeScript, downgradeScript, err := sqlparse.NewSqlParser().Parse(
+			strings.NewReader(formatted),
+		)
+		assert.Nil(t, err)
+		check.DeepEqual(t, upgradeScript.Options, tc.upgradeScript.Options)
+		check.DeepEqual(t, downgradeScript.Options, tc.downgradeScript.Options)
+	}
+}
@@ -1,6 +1,11 @@
 package storage
 
-import "github.com/eugenetriguba/bolt/internal/configloader"
+import (
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
+)
 
 type DBAdapter interface {
 	// ConvertGenericPlaceholders replaces any generic `?` placeholders
@@ -9,10 +14,66 @@ type DBAdapter interface {
 	ConvertGenericPlaceholders(query string, argsCount int) string
 	// TableExists checks if the tableName exists within the
 	// database currently connected to.
-	TableExists(executor sqlExecutor, tableName string) (bool, error)
+	TableExists(executor SQLExecutor, tableName string) (bool, error)
+	// ColumnExists checks if the columnName exists on the
+	// tableName within the database currently connected to.
+	ColumnExists(executor SQLExecutor, tableName string, columnName string) (bool, error)
 	// DatabaseName retrieves the currently selected database name.
-	DatabaseName(executor sqlExecutor) (string, error)
+	DatabaseName(executor SQLExecutor) (string, error)
 	// CreateDSN creates a DSN to be used with sql.Open in the database
 	// driver specific format.
 	CreateDSN(cfg configloader.ConnectionConfig) string
+	// DumpSchema generates the statements that recreate the schema
+	// of the database currently connected to, leaving out the
+	// excludeTables and anything that belongs to them.
+	DumpSchema(executor SQLExecutor, excludeTables []string) (string, error)
+	// InspectSchema retrieves the tables of the database currently
+	// connected to along with their columns, indexes, and constraints,
+	// leaving out the excludeTables.
+	InspectSchema(executor SQLExecutor, excludeTables []string) (models.Schema, error)
+	// CreateServerDSN creates a DSN like CreateDSN, but for the
+	// server-level database that other databases are created and
+	// dropped from rather than cfg.DBName.
+	CreateServerDSN(cfg configloader.ConnectionConfig) string
+	// DatabaseExists checks if the database named dbName exists
+	// on the server-level database connection.
+	DatabaseExists(executor SQLExecutor, dbName string) (bool, error)
+	// CreateDatabase creates the database named dbName on the
+	// server-level database connection.
+	CreateDatabase(executor SQLExecutor, dbName string) error
+	// DropDatabase drops the database named dbName on the
+	// server-level database connection.
+	DropDatabase(executor SQLExecutor, dbName string) error
+	// IsTransientError checks whether err is one that is likely to
+	// succeed when retried, such as a serialization failure, deadlock,
+	// or lock timeout.
+	IsTransientError(err error) bool
+	// CreateSchema creates the schema named schemaName if it
+	// doesn't exist yet. ErrSchemasUnsupported is returned by
+	// databases without schemas that can be selected with a
+	// search path.
+	CreateSchema(executor SQLExecutor, schemaName string) error
+}
+
+// SchemaDropper is implemented by the adapters of databases whose
+// schemas can be dropped along with everything in them.
+type SchemaDropper interface {
+	// DropSchema drops the schema named schemaName, if it exists,
+	// along with everything in it.
+	DropSchema(executor SQLExecutor, schemaName string) error
+}
+
+// UpdateStatementCreator is implemented by the adapters of databases
+// without a standard SQL UPDATE statement, such as ClickHouse.
+type UpdateStatementCreator interface {
+	// UpdateStatement creates the statement that sets the assignments,
+	// such as `dirty = NULL`, on the rows of tableName that match the
+	// condition. It's used to update Bolt's migrations table.
+	UpdateStatement(tableName string, assignments string, condition string) string
+}
+
+// updateStatement creates a standard SQL UPDATE statement for
+// the adapters that don't implement UpdateStatementCreator.
+func updateStatement(tableName string, assignments string, condition string) string {
+	return fmt.Sprintf("UPDATE %s SET %s WHERE %s", tableName, assignments, condition)
 }
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
+)
+
+type ClickHouseAdapter struct{}
+
+func (c ClickHouseAdapter) ConvertGenericPlaceholders(
+	query string,
+	argsCount int,
+) string {
+	// The clickhouse driver uses ? as a generic placeholder.
+	return query
+}
+
+func (c ClickHouseAdapter) UpdateStatement(
+	tableName string,
+	assignments string,
+	condition string,
+) string {
+	// ClickHouse only updates rows with an ALTER TABLE ... UPDATE
+	// mutation, which the connection waits for to finish.
+	return fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", tableName, assignments, condition)
+}
+
+func (c ClickHouseAdapter) TableExists(
+	executor SQLExecutor,
+	tableName string,
+) (bool, error) {
+	var count int
+	databaseName, tableName := c.splitTableName(tableName)
+	err := executor.QueryRow(`
+		SELECT count()
+		FROM system.tables
+		WHERE database = coalesce(?, currentDatabase())
+		AND name = ?;
+	`, databaseName, tableName).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf(
+			"unable to check if %s exists: %w",
+			tableName,
+			err,
+		)
+	}
+
+	return count > 0, nil
+}
+
+func (c ClickHouseAdapter) ColumnExists(
+	executor SQLExecutor,
+	tableName string,
+	columnName string,
+) (bool, error) {
+	var count int
+	databaseName, tableName := c.splitTableName(tableName)
+	err := executor.QueryRow(`
+		SELECT count()
+		FROM system.columns
+		WHERE database = coalesce(?, currentDatabase())
+		AND table = ?
+		AND name = ?;
+	`, databaseName, tableName, columnName).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf(
+			"unable to check if %s.%s exists: %w",
+			tableName,
+			columnName,
+			err,
+		)
+	}
+
+	return count > 0, nil
+}
+
+// splitTableName splits a database-qualified table name into its
+// database and table. The database is NULL for unqualified names
+// so that they're looked up in the current database.
+func (c ClickHouseAdapter) splitTableName(tableName string) (sql.NullString, string) {
+	databaseName, name, qualified := strings.Cut(tableName, ".")
+	if !qualified {
+		return sql.NullString{}, tableName
+	}
+
+	return sql.NullString{String: databaseName, Valid: true}, name
+}
+
+func (c ClickHouseAdapter) DatabaseName(executor SQLExecutor) (string, error) {
+	var name string
+	err := executor.QueryRow("SELECT currentDatabase();").Scan(&name)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve database name: %w", err)
+	}
+
+	return name, nil
+}
+
+func (c ClickHouseAdapter) CreateDSN(cfg configloader.ConnectionConfig) string {
+	host := cfg.Host
+	if cfg.Port != "" {
+		host += fmt.Sprintf(":%s", cfg.Port)
+	}
+	dsn := url.URL{
+		Scheme: "clickhouse",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   host,
+		Path:   "/" + cfg.DBName,
+		// Mutations, like ALTER TABLE ... UPDATE, run in the
+		// background by default. Waiting for them keeps the
+		// migrations table consistent with what was executed.
+		RawQuery: "mutations_sync=2",
+	}
+	return dsn.String()
+}
+
+func (c ClickHouseAdapter) CreateServerDSN(cfg configloader.ConnectionConfig) string {
+	cfg.DBName = "default"
+	return c.CreateDSN(cfg)
+}
+
+func (c ClickHouseAdapter) DatabaseExists(executor SQLExecutor, dbName string) (bool, error) {
+	var count int
+	err := executor.QueryRow(
+		"SELECT count() FROM system.databases WHERE name = ?;",
+		dbName,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("unable to check if database %s exists: %w", dbName, err)
+	}
+
+	return count > 0, nil
+}
+
+func (c ClickHouseAdapter) CreateDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("CREATE DATABASE %s;", c.quoteIdentifier(dbName)))
+	return err
+}
+
+func (c ClickHouseAdapter) DropDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("DROP DATABASE %s;", c.quoteIdentifier(dbName)))
+	return err
+}
+
+func (c ClickHouseAdapter) CreateSchema(executor SQLExecutor, schemaName string) error {
+	return ErrSchemasUnsupported
+}
+
+func (c ClickHouseAdapter) IsTransientError(err error) bool {
+	var exception *clickhouse.Exception
+	if !errors.As(err, &exception) {
+		return false
+	}
+
+	switch exception.Code {
+	// TOO_MANY_SIMULTANEOUS_QUERIES, TOO_MANY_PARTS, and DEADLOCK_AVOIDED.
+	case 202, 252, 473:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c ClickHouseAdapter) quoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func (c ClickHouseAdapter) DumpSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (string, error) {
+	databaseName, err := c.DatabaseName(executor)
+	if err != nil {
+		return "", err
+	}
+
+	// ClickHouse keeps the statement each table, view, and
+	// dictionary was created with. They're qualified with the
+	// database name, which is stripped so the dump can be loaded
+	// into a database with another name. Tables are created before
+	// the views that select from them.
+	rows, err := executor.Query(`
+		SELECT name, create_table_query
+		FROM system.tables
+		WHERE database = ?
+		AND NOT is_temporary
+		AND name NOT LIKE '.inner%'
+		ORDER BY engine LIKE '%View', metadata_modification_time, name;
+	`, databaseName)
+	if err != nil {
+		return "", fmt.Errorf("unable to query schema: %w", err)
+	}
+	defer rows.Close()
+
+	qualifier := c.quoteIdentifier(databaseName) + "."
+	statements := make([]string, 0)
+	for rows.Next() {
+		var tableName, statement string
+		err = rows.Scan(&tableName, &statement)
+		if err != nil {
+			return "", fmt.Errorf("unable to scan schema: %w", err)
+		}
+		if isExcludedTable(databaseName, tableName, databaseName, excludeTables) {
+			continue
+		}
+		statement = strings.ReplaceAll(statement, qualifier, "")
+		statement = strings.ReplaceAll(statement, databaseName+".", "")
+		statements = append(statements, statement)
+	}
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("unable to query schema: %w", err)
+	}
+
+	return formatSchema(statements), nil
+}
+
+func (c ClickHouseAdapter) InspectSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (models.Schema, error) {
+	databaseName, err := c.DatabaseName(executor)
+	if err != nil {
+		return models.Schema{}, err
+	}
+
+	// ClickHouse doesn't have unique or foreign key constraints.
+	// A table's sorting key stands in for its primary key, and
+	// data skipping indexes are its only indexes.
+	return inspectSchema(executor, databaseName, excludeTables, `
+		SELECT name, 'table', name, '', false
+		FROM system.tables
+		WHERE database = ? AND NOT is_temporary AND engine NOT LIKE '%View'
+		UNION ALL
+		SELECT table, 'column', name, type, startsWith(type, 'Nullable(')
+		FROM system.columns
+		WHERE database = ? AND table IN (
+			SELECT name FROM system.tables
+			WHERE database = ? AND engine NOT LIKE '%View'
+		)
+		UNION ALL
+		SELECT table, 'index', name, '', false
+		FROM system.data_skipping_indices
+		WHERE database = ?
+		UNION ALL
+		SELECT name, 'constraint', 'ORDER BY (' || sorting_key || ')', '', false
+		FROM system.tables
+		WHERE database = ? AND sorting_key != '';
+	`, databaseName, databaseName, databaseName, databaseName, databaseName)
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+)
+
+// CockroachDBAdapter is the adapter for CockroachDB. It speaks
+// PostgreSQL's wire protocol and mostly its SQL too, so it builds
+// on the PostgreSQL adapter where the two are the same. That
+// includes IsTransientError, since CockroachDB reports the errors
+// it asks clients to retry transactions on as serialization
+// failures.
+type CockroachDBAdapter struct {
+	PostgresqlAdapter
+}
+
+func (c CockroachDBAdapter) CreateServerDSN(cfg configloader.ConnectionConfig) string {
+	cfg.DBName = "defaultdb"
+	cfg.Schema = ""
+	return c.CreateDSN(cfg)
+}
+
+func (c CockroachDBAdapter) DumpSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (string, error) {
+	var schemaName string
+	err := executor.QueryRow("SELECT current_schema();").Scan(&schemaName)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve current schema: %w", err)
+	}
+
+	// CockroachDB doesn't have most of the catalog functions the
+	// PostgreSQL dump relies on, but it does keep the statements
+	// that recreate each table, view, and sequence. Foreign keys are
+	// split off into their own statements so that they come after
+	// the tables they reference.
+	statements, err := c.queryStatements(executor, schemaName, excludeTables, `
+		SELECT descriptor_name, create_nofks
+		FROM crdb_internal.create_statements
+		WHERE database_name = current_database()
+		AND schema_name = $1
+		ORDER BY descriptor_type = 'view', descriptor_id;
+	`)
+	if err != nil {
+		return "", err
+	}
+
+	foreignKeys, err := c.queryStatements(executor, schemaName, excludeTables, `
+		SELECT descriptor_name, unnest(alter_statements)
+		FROM crdb_internal.create_statements
+		WHERE database_name = current_database()
+		AND schema_name = $1
+		ORDER BY descriptor_id;
+	`)
+	if err != nil {
+		return "", err
+	}
+
+	return formatSchema(append(statements, foreignKeys...)), nil
+}
@@ -0,0 +1,112 @@
+//go:build cockroachdb
+
+package storage_test
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/bolttest"
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestCockroachDB_TableExists(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.CockroachDBAdapter{}
+	db, err := sql.Open("pgx", adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	exists, err := adapter.TableExists(db, "tmp")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	exists, err = adapter.TableExists(db, "tmp")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+}
+
+func TestCockroachDB_CreateServerDSN(t *testing.T) {
+	cfg := configloader.ConnectionConfig{
+		Host:   "db1",
+		Port:   "26257",
+		User:   "root",
+		DBName: "testdb",
+		Schema: "custom_schema",
+	}
+	adapter := storage.CockroachDBAdapter{}
+
+	dsn := adapter.CreateServerDSN(cfg)
+
+	assert.Equal(t, dsn, "host=db1 port=26257 user=root password= dbname=defaultdb sslmode=disable")
+}
+
+func TestCockroachDB_DumpSchema(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.CockroachDBAdapter{}
+	db, err := sql.Open("pgx", adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp_child, tmp, excluded;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE tmp_child(id INT PRIMARY KEY, tmp_id INT REFERENCES tmp(id));")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE excluded(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	schema, err := adapter.DumpSchema(db, []string{"excluded"})
+	assert.Nil(t, err)
+
+	assert.True(t, strings.Contains(schema, "CREATE TABLE public.tmp ("))
+	assert.True(t, strings.Contains(schema, "CREATE TABLE public.tmp_child ("))
+	assert.True(t, strings.Contains(schema, "ALTER TABLE public.tmp_child ADD CONSTRAINT"))
+	assert.False(t, strings.Contains(schema, "excluded"))
+}
+
+func TestCockroachDB_CreateAndDropDatabase(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	cfg.DBName = "bolt_created_db"
+	t.Cleanup(func() {
+		_ = storage.DropDatabase(cfg)
+	})
+
+	err := storage.CreateDatabase(cfg)
+	assert.Nil(t, err)
+	err = storage.CreateDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseExists)
+
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	err = storage.DropDatabase(cfg)
+	assert.Nil(t, err)
+	err = storage.DropDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseDoesNotExist)
+}
+
+func TestCockroachDB_IsTransientError(t *testing.T) {
+	adapter := storage.CockroachDBAdapter{}
+
+	// CockroachDB's "restart transaction" errors.
+	err := fmt.Errorf("unable to execute: %w", &pgconn.PgError{Code: "40001"})
+	assert.True(t, adapter.IsTransientError(err))
+	assert.False(t, adapter.IsTransientError(&pgconn.PgError{Code: "42P01"}))
+}
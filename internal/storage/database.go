@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+)
+
+var (
+	ErrDatabaseExists       = errors.New("database already exists")
+	ErrDatabaseDoesNotExist = errors.New("database does not exist")
+)
+
+// CreateDatabase creates the database named by the connection
+// configuration's DBName. It connects to the server-level database
+// to do so, so the database doesn't need to exist beforehand.
+//
+// ErrDatabaseExists is returned if the database already exists.
+func CreateDatabase(cfg configloader.ConnectionConfig) error {
+	return withServerConnection(cfg, func(adapter DBAdapter, executor SQLExecutor) error {
+		exists, err := adapter.DatabaseExists(executor, cfg.DBName)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrDatabaseExists
+		}
+
+		err = adapter.CreateDatabase(executor, cfg.DBName)
+		if err != nil {
+			return fmt.Errorf("unable to create database %s: %w", cfg.DBName, err)
+		}
+		return nil
+	})
+}
+
+// DropDatabase drops the database named by the connection
+// configuration's DBName. It connects to the server-level database
+// to do so, since a database can't be dropped while connected to it.
+//
+// ErrDatabaseDoesNotExist is returned if the database doesn't exist.
+func DropDatabase(cfg configloader.ConnectionConfig) error {
+	return withServerConnection(cfg, func(adapter DBAdapter, executor SQLExecutor) error {
+		exists, err := adapter.DatabaseExists(executor, cfg.DBName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrDatabaseDoesNotExist
+		}
+
+		err = adapter.DropDatabase(executor, cfg.DBName)
+		if err != nil {
+			return fmt.Errorf("unable to drop database %s: %w", cfg.DBName, err)
+		}
+		return nil
+	})
+}
+
+// RecreateDatabase drops the database named by the connection
+// configuration's DBName, if it exists, and creates it again.
+//
+// When a schema is configured, only the schema is dropped, along
+// with everything in it, and created again. The database may be
+// shared with other schemas, such as those of other tenants, that
+// are left alone.
+func RecreateDatabase(cfg configloader.ConnectionConfig) error {
+	if cfg.Schema != "" {
+		return recreateSchema(cfg)
+	}
+
+	err := DropDatabase(cfg)
+	if err != nil && !errors.Is(err, ErrDatabaseDoesNotExist) {
+		return fmt.Errorf("unable to drop database: %w", err)
+	}
+	err = CreateDatabase(cfg)
+	if err != nil {
+		return fmt.Errorf("unable to create database: %w", err)
+	}
+	return nil
+}
+
+func recreateSchema(cfg configloader.ConnectionConfig) error {
+	driver, err := LookupDriver(cfg.Driver)
+	if err != nil {
+		return err
+	}
+	dropper, ok := driver.Adapter.(SchemaDropper)
+	if !driver.Schemas || !ok {
+		return ErrSchemasUnsupported
+	}
+
+	cfg.CreateSchema = false
+	db, err := NewDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = dropper.DropSchema(db, cfg.Schema)
+	if err != nil {
+		return fmt.Errorf("unable to drop schema %s: %w", cfg.Schema, err)
+	}
+	err = driver.Adapter.CreateSchema(db, cfg.Schema)
+	if err != nil {
+		return fmt.Errorf("unable to create schema %s: %w", cfg.Schema, err)
+	}
+	return nil
+}
+
+func withServerConnection(
+	cfg configloader.ConnectionConfig,
+	fn func(adapter DBAdapter, executor SQLExecutor) error,
+) error {
+	driver, err := LookupDriver(cfg.Driver)
+	if err != nil {
+		return err
+	}
+	if cfg.DBName == "" {
+		return errors.New("no database name is configured")
+	}
+
+	db, err := sql.Open(driver.SQLDriverName, driver.Adapter.CreateServerDSN(cfg))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedConnectionString, err)
+	}
+	defer db.Close()
+
+	err = ping(db, cfg)
+	if err != nil {
+		return err
+	}
+
+	return fn(driver.Adapter, db)
+}
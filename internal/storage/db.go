@@ -1,51 +1,29 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
-	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/jackc/pgx/v5/stdlib"
-	_ "github.com/mattn/go-sqlite3"
-	_ "github.com/microsoft/go-mssqldb"
+	"github.com/eugenetriguba/bolt/internal/models"
 )
 
-var postgresqlDriverName = "postgresql"
-var mysqlDriverName = "mysql"
-var mssqlDriverName = "mssql"
-var sqliteDriverName = "sqlite3"
-
-var supportedDrivers = map[string]dbDriver{
-	postgresqlDriverName: {name: "pgx", adapter: PostgresqlAdapter{}},
-	mysqlDriverName:      {name: "mysql", adapter: MySQLAdapter{}},
-	mssqlDriverName:      {name: "sqlserver", adapter: MSSQLAdapter{}},
-	sqliteDriverName:     {name: "sqlite3", adapter: SqliteAdapter{}},
-}
-
-type dbDriver struct {
-	name    string
-	adapter DBAdapter
-}
-
 var (
 	ErrMalformedConnectionString = errors.New(
 		"malformed database connection parameters provided",
 	)
-	ErrUnableToConnect   = errors.New("unable to open connection to database")
-	ErrUnsupportedDriver = fmt.Errorf(
-		"unsupported driver, supported drivers are %s",
-		[]string{
-			postgresqlDriverName,
-			mysqlDriverName,
-			mssqlDriverName,
-			sqliteDriverName,
-		},
-	)
+	ErrUnableToConnect    = errors.New("unable to open connection to database")
+	ErrSchemasUnsupported = errors.New("the schema setting isn't supported by the driver")
+	ErrUnsupportedDriver  = errors.New("unsupported driver")
 )
 
-type sqlExecutor interface {
+// SQLExecutor executes statements against the database. It's
+// either the database's connection pool or a transaction.
+type SQLExecutor interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(query string, args ...interface{}) *sql.Row
@@ -60,16 +38,25 @@ type DB interface {
 	Tx(fn TxFunc) error
 	Close() error
 	TableExists(tableName string) (bool, error)
+	ColumnExists(tableName string, columnName string) (bool, error)
+	DatabaseName() (string, error)
+	DumpSchema(excludeTables ...string) (string, error)
+	InspectSchema(excludeTables ...string) (models.Schema, error)
 }
 
 type SqlDB struct {
-	executor sqlExecutor
+	executor SQLExecutor
 	conn     *sql.DB
-	adapter  DBAdapter
+	driver   Driver
+	// statementRetry is how statements, and transactions, that
+	// fail with a transient error are retried.
+	statementRetry retryPolicy
 }
 
 // NewDB establishes a connection to the database using the
-// connection configuration.
+// connection configuration. When the database can't be reached,
+// connecting is retried with exponential backoff according to the
+// configured connect retries and timeout.
 //
 // The following errors may be returned:
 //   - ErrMalformedConnectionString: The provided connection parameters are
@@ -77,13 +64,27 @@ type SqlDB struct {
 //   - ErrUnableToConnect: Unable to make a connection to the database with
 //     the provided connection parameters.
 //   - ErrUnsupportedDriver: The provided driver is not supported.
+//   - ErrSchemasUnsupported: A schema is configured for a driver
+//     that doesn't support them.
 func NewDB(cfg configloader.ConnectionConfig) (DB, error) {
-	driver, exists := supportedDrivers[cfg.Driver]
-	if !exists {
-		return SqlDB{}, ErrUnsupportedDriver
+	driver, err := LookupDriver(cfg.Driver)
+	if err != nil {
+		return SqlDB{}, err
+	}
+	if cfg.Schema != "" && !driver.Schemas {
+		return SqlDB{}, ErrSchemasUnsupported
 	}
 
-	db, err := sql.Open(driver.name, driver.adapter.CreateDSN(cfg))
+	slog.Debug(
+		"connecting to database",
+		slog.String("driver", cfg.Driver),
+		slog.String("host", cfg.Host),
+		slog.String("port", cfg.Port),
+		slog.String("user", cfg.User),
+		slog.String("dbname", cfg.DBName),
+		slog.String("schema", cfg.Schema),
+	)
+	db, err := sql.Open(driver.SQLDriverName, driver.Adapter.CreateDSN(cfg))
 	if err != nil {
 		return SqlDB{}, fmt.Errorf("%w: %v", ErrMalformedConnectionString, err)
 	}
@@ -91,12 +92,95 @@ func NewDB(cfg configloader.ConnectionConfig) (DB, error) {
 	// Note: `sql.Open` only validates the connection string we provided is sane.
 	// It doesn't open up a connection to the database. For that, we ping
 	// the database to ensure the connection string is fully valid.
-	err = db.Ping()
+	err = ping(db, cfg)
+	if err != nil {
+		db.Close()
+		return SqlDB{}, err
+	}
+
+	if cfg.Schema != "" && cfg.CreateSchema {
+		err = driver.Adapter.CreateSchema(db, cfg.Schema)
+		if err != nil {
+			db.Close()
+			return SqlDB{}, fmt.Errorf("unable to create schema %s: %w", cfg.Schema, err)
+		}
+	}
+
+	return SqlDB{
+		executor:       db,
+		conn:           db,
+		driver:         driver,
+		statementRetry: retryPolicy{retries: cfg.StatementRetries},
+	}, nil
+}
+
+// ping opens up a connection to the database, retrying according
+// to the configured connect retries and timeout.
+func ping(db *sql.DB, cfg configloader.ConnectionConfig) error {
+	policy := retryPolicy{retries: cfg.ConnectRetries, timeout: cfg.ConnectTimeout}
+	err := policy.do("connecting to database", retryAll, func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	})
 	if err != nil {
-		return SqlDB{}, fmt.Errorf("%w: %v", ErrUnableToConnect, err)
+		return fmt.Errorf("%w: %v", ErrUnableToConnect, err)
+	}
+
+	return nil
+}
+
+// HasTransactions checks whether the database db is connected
+// to has transactions. Without them, Tx executes its statements
+// as they come and a failure leaves the earlier ones applied.
+func HasTransactions(db DB) bool {
+	sqlDB, ok := db.(SqlDB)
+	if !ok {
+		return true
+	}
+
+	return sqlDB.driver.Transactions
+}
+
+// TableOptions gives back the options the database db is
+// connected to needs at the end of the statement that creates
+// the migrations table, such as ClickHouse's table engine.
+// It's empty for most databases.
+func TableOptions(db DB) string {
+	sqlDB, ok := db.(SqlDB)
+	if !ok {
+		return ""
+	}
+
+	return sqlDB.driver.TableOptions
+}
+
+// UpdateStatement creates the statement that sets the assignments
+// on the rows of tableName that match the condition for the
+// database db is connected to, such as ClickHouse's mutations.
+func UpdateStatement(db DB, tableName string, assignments string, condition string) string {
+	sqlDB, ok := db.(SqlDB)
+	if !ok {
+		return updateStatement(tableName, assignments, condition)
+	}
+	creator, ok := sqlDB.driver.Adapter.(UpdateStatementCreator)
+	if !ok {
+		return updateStatement(tableName, assignments, condition)
+	}
+
+	return creator.UpdateStatement(tableName, assignments, condition)
+}
+
+// WithoutRetries gives back a copy of db that doesn't retry statements
+// that fail with a transient error. Scripts that execute several
+// statements outside of a transaction aren't safe to retry, since the
+// statements before the failing one stay applied.
+func WithoutRetries(db DB) DB {
+	sqlDB, ok := db.(SqlDB)
+	if !ok {
+		return db
 	}
 
-	return SqlDB{executor: db, conn: db, adapter: driver.adapter}, nil
+	sqlDB.statementRetry = retryPolicy{}
+	return sqlDB
 }
 
 // Close closes the database connection. Any further
@@ -106,34 +190,165 @@ func (db SqlDB) Close() error {
 	return db.conn.Close()
 }
 
-// Exec is a wrapper around the sql.DB Exec.
+// Exec is a wrapper around the sql.DB Exec. The statement
+// is traced when debug logging is enabled and retried when
+// it fails with a transient error.
+//
+// A query without arguments is split up into its batches for
+// databases with a batch separator, and into its statements for
+// databases that only execute a single statement at a time. They
+// are executed in order.
 func (db SqlDB) Exec(query string, args ...any) (sql.Result, error) {
-	newQuery := db.adapter.ConvertGenericPlaceholders(query, len(args))
-	return db.executor.Exec(newQuery, args...)
+	if len(args) == 0 {
+		statements := db.splitScript(query)
+		if len(statements) > 1 {
+			var result sql.Result
+			for _, statement := range statements {
+				var err error
+				result, err = db.exec(statement)
+				if err != nil {
+					return result, err
+				}
+			}
+			return result, nil
+		}
+	}
+
+	return db.exec(query, args...)
+}
+
+// splitScript splits a script up into what the database
+// executes at a time.
+func (db SqlDB) splitScript(script string) []string {
+	statements := []string{script}
+	if db.driver.BatchSeparator != "" {
+		statements = splitBatches(script, db.driver.BatchSeparator)
+	}
+	if db.driver.SingleStatements {
+		batches := statements
+		statements = make([]string, 0, len(batches))
+		for _, batch := range batches {
+			statements = append(statements, splitStatements(batch)...)
+		}
+	}
+	return statements
+}
+
+func (db SqlDB) exec(query string, args ...any) (sql.Result, error) {
+	newQuery := db.driver.Adapter.ConvertGenericPlaceholders(query, len(args))
+	var result sql.Result
+	err := db.retryStatement(func() error {
+		startTime := time.Now()
+		var err error
+		result, err = db.executor.Exec(newQuery, args...)
+		traceStatement(newQuery, args, startTime, err)
+		return err
+	})
+	return result, err
 }
 
-// Query is a wrapper around the sql.DB Query.
+// Query is a wrapper around the sql.DB Query. The statement
+// is traced when debug logging is enabled and retried when
+// it fails with a transient error.
 func (db SqlDB) Query(query string, args ...any) (*sql.Rows, error) {
-	newQuery := db.adapter.ConvertGenericPlaceholders(query, len(args))
-	return db.executor.Query(newQuery, args...)
+	newQuery := db.driver.Adapter.ConvertGenericPlaceholders(query, len(args))
+	var rows *sql.Rows
+	err := db.retryStatement(func() error {
+		startTime := time.Now()
+		var err error
+		rows, err = db.executor.Query(newQuery, args...)
+		traceStatement(newQuery, args, startTime, err)
+		return err
+	})
+	return rows, err
 }
 
-// QueryRow is a wrapper around the sql.DB QueryRow.
+// QueryRow is a wrapper around the sql.DB QueryRow. The statement
+// is traced when debug logging is enabled and retried when
+// it fails with a transient error.
 func (db SqlDB) QueryRow(query string, args ...any) *sql.Row {
-	newQuery := db.adapter.ConvertGenericPlaceholders(query, len(args))
-	return db.executor.QueryRow(newQuery, args...)
+	newQuery := db.driver.Adapter.ConvertGenericPlaceholders(query, len(args))
+	var row *sql.Row
+	db.retryStatement(func() error {
+		startTime := time.Now()
+		row = db.executor.QueryRow(newQuery, args...)
+		traceStatement(newQuery, args, startTime, row.Err())
+		return row.Err()
+	})
+	return row
+}
+
+// retryStatement calls fn, retrying it when it fails with an
+// error the adapter classifies as transient, such as a deadlock.
+func (db SqlDB) retryStatement(fn func() error) error {
+	return db.statementRetry.do(
+		"executing statement",
+		db.driver.Adapter.IsTransientError,
+		func(ctx context.Context) error {
+			return fn()
+		},
+	)
 }
 
 // TableExists checks if the tableName exists within the
 // database currently connected to.
 func (db SqlDB) TableExists(tableName string) (bool, error) {
-	return db.adapter.TableExists(db.executor, tableName)
+	return db.driver.Adapter.TableExists(db.executor, tableName)
+}
+
+// ColumnExists checks if the columnName exists on the
+// tableName within the database currently connected to.
+func (db SqlDB) ColumnExists(tableName string, columnName string) (bool, error) {
+	return db.driver.Adapter.ColumnExists(db.executor, tableName, columnName)
+}
+
+// DatabaseName retrieves the name of the database currently
+// connected to, as reported by the database itself.
+func (db SqlDB) DatabaseName() (string, error) {
+	return db.driver.Adapter.DatabaseName(db.executor)
+}
+
+// DumpSchema generates the statements that recreate the schema
+// of the database currently connected to. Any excludeTables, and
+// anything that belongs to them, are left out.
+func (db SqlDB) DumpSchema(excludeTables ...string) (string, error) {
+	return db.driver.Adapter.DumpSchema(db.executor, excludeTables)
+}
+
+// InspectSchema retrieves the tables of the database currently
+// connected to along with their columns, indexes, and constraints.
+// Any excludeTables are left out.
+func (db SqlDB) InspectSchema(excludeTables ...string) (models.Schema, error) {
+	return db.driver.Adapter.InspectSchema(db.executor, excludeTables)
 }
 
 // Tx executes fn within a transaction block. If
 // fn returns an error, the transaction will be rolled
 // back. Otherwise, it will be committed.
+//
+// When the transaction fails with a transient error, it is
+// rolled back and retried from the start, since the statements
+// within it can't be retried on their own.
+//
+// For databases without transactions, fn is executed directly
+// against the database and nothing is rolled back on failure.
 func (db SqlDB) Tx(fn TxFunc) error {
+	if !db.driver.Transactions {
+		noTxDB := db
+		noTxDB.statementRetry = retryPolicy{}
+		return fn(noTxDB)
+	}
+
+	return db.statementRetry.do(
+		"executing transaction",
+		db.driver.Adapter.IsTransientError,
+		func(ctx context.Context) error {
+			return db.tx(fn)
+		},
+	)
+}
+
+func (db SqlDB) tx(fn TxFunc) error {
 	tx, err := db.conn.Begin()
 	if err != nil {
 		return fmt.Errorf(
@@ -141,15 +356,18 @@ func (db SqlDB) Tx(fn TxFunc) error {
 			err,
 		)
 	}
+	slog.Debug("started transaction")
 	defer tx.Rollback()
 
 	// Create a shallow clone of the DB instance for
 	// the transaction scope with a tx executor.
 	txDB := db
 	txDB.executor = tx
+	txDB.statementRetry = retryPolicy{}
 
 	err = fn(txDB)
 	if err != nil {
+		slog.Debug("rolling back transaction", slog.String("error", err.Error()))
 		return fmt.Errorf("unable to execute transaction: %w", err)
 	}
 
@@ -157,6 +375,7 @@ func (db SqlDB) Tx(fn TxFunc) error {
 	if err != nil {
 		return fmt.Errorf("unable to commit transaction: %w", err)
 	}
+	slog.Debug("committed transaction")
 
 	return nil
 }
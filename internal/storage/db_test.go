@@ -1,14 +1,22 @@
 package storage_test
 
 import (
+	"bytes"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/eugenetriguba/bolt/internal/bolttest"
+	"github.com/eugenetriguba/bolt/internal/configloader"
 	"github.com/eugenetriguba/bolt/internal/storage"
 	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/eugenetriguba/checkmate/check"
 )
 
 func TestNewDB_Success(t *testing.T) {
@@ -29,7 +37,7 @@ func TestNewDB_UnsupportedDriver(t *testing.T) {
 
 func TestNewDB_UnableToConnect(t *testing.T) {
 	driver := os.Getenv("BOLT_DB_DRIVER")
-	if driver != "sqlite3" {
+	if driver != "sqlite3" && driver != "sqlite" && driver != "libsql" {
 		t.Setenv("BOLT_DB_HOST", "")
 		t.Setenv("BOLT_DB_PORT", "")
 		_, err := storage.NewDB(bolttest.NewTestConnectionConfig())
@@ -37,6 +45,60 @@ func TestNewDB_UnableToConnect(t *testing.T) {
 	}
 }
 
+func TestNewDB_SchemaUnsupported(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	if storage.SupportsSchemas(cfg.Driver) {
+		t.Skipf("%s supports schemas", cfg.Driver)
+	}
+	cfg.Schema = "custom_schema"
+
+	_, err := storage.NewDB(cfg)
+
+	assert.ErrorIs(t, err, storage.ErrSchemasUnsupported)
+}
+
+func TestRecreateDatabase_SchemaConfigured(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	if !storage.SupportsSchemas(cfg.Driver) {
+		t.Skipf("%s doesn't support schemas", cfg.Driver)
+	}
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	bolttest.DropTable(t, db, "tmp")
+	t.Cleanup(func() {
+		bolttest.DropTable(t, db, "tmp")
+		_, err := db.Exec("DROP SCHEMA IF EXISTS custom_schema CASCADE;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+	schemaCfg := cfg
+	schemaCfg.Schema = "custom_schema"
+	schemaCfg.CreateSchema = true
+	schemaDB, err := storage.NewDB(schemaCfg)
+	assert.Nil(t, err)
+	_, err = schemaDB.Exec("CREATE TABLE tmp_schema(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+	assert.Nil(t, schemaDB.Close())
+
+	err = storage.RecreateDatabase(schemaCfg)
+	assert.Nil(t, err)
+
+	// The schema is emptied, but the rest of the database is still there.
+	schemaDB, err = storage.NewDB(schemaCfg)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		assert.Nil(t, schemaDB.Close())
+	})
+	exists, err := schemaDB.TableExists("custom_schema.tmp_schema")
+	assert.Nil(t, err)
+	check.False(t, exists)
+	exists, err = db.TableExists("tmp")
+	assert.Nil(t, err)
+	check.True(t, exists)
+}
+
 func TestClose_IsClosed(t *testing.T) {
 	db, err := storage.NewDB(bolttest.NewTestConnectionConfig())
 	assert.Nil(t, err)
@@ -156,3 +218,180 @@ func TestTx_Rollback(t *testing.T) {
 	err = db.QueryRow("SELECT id FROM tmp WHERE id = 1;").Scan(&id)
 	assert.ErrorIs(t, err, sql.ErrNoRows)
 }
+
+func TestDumpSchema_ExcludesTables(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	bolttest.DropTable(t, db, "tmp")
+	bolttest.DropTable(t, db, cfg.MigrationsTable)
+	t.Cleanup(func() {
+		bolttest.DropTable(t, db, "tmp")
+		bolttest.DropTable(t, db, cfg.MigrationsTable)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec(`CREATE TABLE tmp(id INT PRIMARY KEY, name VARCHAR(255));`)
+	assert.Nil(t, err)
+	_, err = db.Exec(`CREATE INDEX tmp_name_idx ON tmp(name);`)
+	assert.Nil(t, err)
+	_, err = db.Exec(fmt.Sprintf(`CREATE TABLE %s(version VARCHAR(255));`, cfg.MigrationsTable))
+	assert.Nil(t, err)
+
+	schema, err := db.DumpSchema(cfg.MigrationsTable)
+	assert.Nil(t, err)
+
+	assert.True(t, strings.Contains(schema, "tmp"))
+	assert.True(t, strings.Contains(schema, "tmp_name_idx"))
+	assert.False(t, strings.Contains(schema, cfg.MigrationsTable))
+}
+
+func TestInspectSchema_ExcludesTables(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	bolttest.DropTable(t, db, "tmp")
+	bolttest.DropTable(t, db, cfg.MigrationsTable)
+	t.Cleanup(func() {
+		bolttest.DropTable(t, db, "tmp")
+		bolttest.DropTable(t, db, cfg.MigrationsTable)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec(`CREATE TABLE tmp(id INT PRIMARY KEY, name VARCHAR(255));`)
+	assert.Nil(t, err)
+	_, err = db.Exec(`CREATE INDEX tmp_name_idx ON tmp(name);`)
+	assert.Nil(t, err)
+	_, err = db.Exec(fmt.Sprintf(`CREATE TABLE %s(version VARCHAR(255));`, cfg.MigrationsTable))
+	assert.Nil(t, err)
+
+	schema, err := db.InspectSchema(cfg.MigrationsTable)
+	assert.Nil(t, err)
+
+	assert.Equal(t, len(schema.Tables), 1)
+	assert.Equal(t, schema.Tables[0].Name, "tmp")
+	assert.Equal(t, len(schema.Tables[0].Columns), 2)
+	assert.DeepEqual(t, schema.Tables[0].Indexes, []string{"tmp_name_idx"})
+	assert.Equal(t, len(schema.Tables[0].Constraints), 1)
+}
+
+func TestExec_TracesStatementsAtDebugLevel(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+	})
+
+	db, err := storage.NewDB(bolttest.NewTestConnectionConfig())
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("SELECT 1 WHERE 1 = ?", 1)
+	assert.Nil(t, err)
+	_, err = db.Exec("SELECT 1 WHERE password = 'hunter2'")
+	assert.NotNil(t, err)
+
+	assert.True(t, strings.Contains(logs.String(), `msg="executed statement"`))
+	assert.True(t, strings.Contains(logs.String(), "args=1"))
+	assert.True(t, strings.Contains(logs.String(), "duration="))
+	assert.True(t, strings.Contains(logs.String(), "password = '[REDACTED]'"))
+	assert.False(t, strings.Contains(logs.String(), "hunter2"))
+	assert.True(t, strings.Contains(logs.String(), "error="))
+}
+
+func TestExec_DoesNotTraceStatementsAboveDebugLevel(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+	})
+
+	db, err := storage.NewDB(bolttest.NewTestConnectionConfig())
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("SELECT 1;")
+	assert.Nil(t, err)
+
+	assert.Equal(t, logs.String(), "")
+}
+
+func TestColumnExists(t *testing.T) {
+	db := bolttest.NewTestDB(t)
+	_, err := db.Exec("CREATE TABLE tmp(id INT NOT NULL PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	exists, err := db.ColumnExists("tmp", "id")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+
+	exists, err = db.ColumnExists("tmp", "name")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	exists, err = db.ColumnExists("abc123donotexist", "id")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+// unreachableConnectionConfig gives back connection configuration
+// for a database that can't be connected to.
+func unreachableConnectionConfig(t *testing.T) configloader.ConnectionConfig {
+	cfg := bolttest.NewTestConnectionConfig()
+	if cfg.Driver == "sqlite3" || cfg.Driver == "sqlite" || cfg.Driver == "libsql" {
+		cfg.DBName = filepath.Join(t.TempDir(), "missing", "bolt.db")
+	} else {
+		cfg.Host = "127.0.0.1"
+		cfg.Port = "1"
+	}
+	return cfg
+}
+
+func TestNewDB_RetriesConnecting(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+	})
+	cfg := unreachableConnectionConfig(t)
+	cfg.ConnectRetries = 2
+
+	startTime := time.Now()
+	_, err := storage.NewDB(cfg)
+
+	assert.ErrorIs(t, err, storage.ErrUnableToConnect)
+	assert.True(t, time.Since(startTime) >= 300*time.Millisecond)
+	assert.Equal(t, strings.Count(logs.String(), `msg="connecting to database failed, retrying"`), 2)
+}
+
+func TestNewDB_StopsRetryingAtConnectTimeout(t *testing.T) {
+	cfg := unreachableConnectionConfig(t)
+	cfg.ConnectTimeout = 500 * time.Millisecond
+
+	startTime := time.Now()
+	_, err := storage.NewDB(cfg)
+
+	assert.ErrorIs(t, err, storage.ErrUnableToConnect)
+	assert.True(t, time.Since(startTime) < 2*time.Second)
+}
+
+func TestNewDB_DoesNotRetryByDefault(t *testing.T) {
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() {
+		slog.SetDefault(defaultLogger)
+	})
+
+	_, err := storage.NewDB(unreachableConnectionConfig(t))
+
+	assert.ErrorIs(t, err, storage.ErrUnableToConnect)
+	assert.Equal(t, logs.String(), "")
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/microsoft/go-mssqldb"
+	_ "github.com/tursodatabase/libsql-client-go/libsql"
+	_ "modernc.org/sqlite"
+)
+
+// Driver describes a database Bolt can connect to: the database/sql
+// driver it's opened with, the adapter for its flavor of SQL, and
+// what the database is capable of.
+type Driver struct {
+	// SQLDriverName is the name of the database/sql driver
+	// that connections to the database are opened with.
+	SQLDriverName string
+	// SQLDriver is registered with database/sql under
+	// SQLDriverName when it's set and no driver is registered
+	// under that name yet. It can be left unset for drivers
+	// that register themselves when they're imported.
+	SQLDriver driver.Driver
+	// Adapter handles everything that differs between databases,
+	// such as placeholders, DSNs, and reading the schema.
+	Adapter DBAdapter
+	// Transactions is whether the database has transactions.
+	// Without them, the statements within a transaction take
+	// effect as they're executed and can't be rolled back.
+	Transactions bool
+	// TransactionalDDL is whether DDL statements, like
+	// CREATE TABLE, are rolled back with the transaction
+	// rather than committing it implicitly.
+	TransactionalDDL bool
+	// Schemas is whether the database has schemas that
+	// the connection can be switched to with the schema
+	// setting.
+	Schemas bool
+	// SingleStatements is whether the database only executes a
+	// single statement at a time, so scripts need to be split up
+	// into their statements before they're executed.
+	SingleStatements bool
+	// BatchSeparator is a line, like SQL Server's GO, that splits
+	// scripts up into batches that are executed one after another.
+	// Statements that have to be alone in their batch, like CREATE
+	// VIEW, are separated from the rest of the script with it.
+	BatchSeparator string
+	// TableOptions are added to the end of the statement that
+	// creates the migrations table, such as ClickHouse's table
+	// engine and the version column it's sorted by.
+	TableOptions string
+}
+
+var (
+	ErrDriverRegistered = errors.New("a driver is already registered with that name")
+	ErrInvalidDriver    = errors.New("invalid driver")
+)
+
+var postgresqlDriverName = "postgresql"
+var mysqlDriverName = "mysql"
+var mssqlDriverName = "mssql"
+var sqliteDriverName = "sqlite3"
+var pureGoSqliteDriverName = "sqlite"
+var cockroachdbDriverName = "cockroachdb"
+var clickhouseDriverName = "clickhouse"
+var libsqlDriverName = "libsql"
+
+// driversMu guards supportedDrivers, which RegisterDriver
+// can add to while other drivers are being looked up.
+var driversMu sync.RWMutex
+
+var supportedDrivers = map[string]Driver{
+	postgresqlDriverName: {
+		SQLDriverName:    "pgx",
+		Adapter:          PostgresqlAdapter{},
+		Transactions:     true,
+		TransactionalDDL: true,
+		Schemas:          true,
+	},
+	mysqlDriverName: {
+		SQLDriverName:    "mysql",
+		Adapter:          MySQLAdapter{},
+		Transactions:     true,
+		TransactionalDDL: false,
+	},
+	mssqlDriverName: {
+		SQLDriverName:    "sqlserver",
+		Adapter:          MSSQLAdapter{},
+		Transactions:     true,
+		TransactionalDDL: true,
+		BatchSeparator:   mssqlBatchSeparator,
+	},
+	// The sqlite3 driver is the pure-Go one when Bolt is built
+	// without cgo or with the purego build tag.
+	sqliteDriverName: {
+		SQLDriverName:    sqliteSQLDriverName,
+		Adapter:          SqliteAdapter{},
+		Transactions:     true,
+		TransactionalDDL: true,
+	},
+	pureGoSqliteDriverName: {
+		SQLDriverName:    "sqlite",
+		Adapter:          SqliteAdapter{},
+		Transactions:     true,
+		TransactionalDDL: true,
+	},
+	// CockroachDB runs schema changes asynchronously, even within a
+	// transaction, so a failing transaction can't roll them back.
+	cockroachdbDriverName: {
+		SQLDriverName:    "pgx",
+		Adapter:          CockroachDBAdapter{},
+		Transactions:     true,
+		TransactionalDDL: false,
+		Schemas:          true,
+	},
+	clickhouseDriverName: {
+		SQLDriverName:    "clickhouse",
+		Adapter:          ClickHouseAdapter{},
+		Transactions:     false,
+		TransactionalDDL: false,
+		SingleStatements: true,
+		TableOptions:     "ENGINE = MergeTree ORDER BY version",
+	},
+	libsqlDriverName: {
+		SQLDriverName:    "libsql",
+		Adapter:          LibSQLAdapter{},
+		Transactions:     true,
+		TransactionalDDL: true,
+	},
+}
+
+// RegisterDriver makes a database available under name, the
+// name the driver connection setting refers to it by. This is
+// how databases Bolt doesn't support out of the box are added.
+//
+// The following errors may be returned:
+//   - ErrDriverRegistered: A driver is already registered
+//     under name, including the built-in ones.
+//   - ErrInvalidDriver: The name, SQL driver name, or
+//     adapter is missing.
+func RegisterDriver(name string, d Driver) error {
+	if name == "" {
+		return fmt.Errorf("%w: a name is required", ErrInvalidDriver)
+	}
+	if d.SQLDriverName == "" {
+		return fmt.Errorf("%w: %s: a SQL driver name is required", ErrInvalidDriver, name)
+	}
+	if d.Adapter == nil {
+		return fmt.Errorf("%w: %s: an adapter is required", ErrInvalidDriver, name)
+	}
+
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, exists := supportedDrivers[name]; exists {
+		return fmt.Errorf("%w: %s", ErrDriverRegistered, name)
+	}
+
+	if d.SQLDriver != nil && !slices.Contains(sql.Drivers(), d.SQLDriverName) {
+		sql.Register(d.SQLDriverName, d.SQLDriver)
+	}
+	supportedDrivers[name] = d
+	return nil
+}
+
+// Drivers gives back the sorted names of the registered drivers.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(supportedDrivers))
+	for name := range supportedDrivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupDriver finds the driver registered under name. If there
+// isn't one, ErrUnsupportedDriver is returned.
+func LookupDriver(name string) (Driver, error) {
+	driversMu.RLock()
+	d, exists := supportedDrivers[name]
+	driversMu.RUnlock()
+	if !exists {
+		return Driver{}, fmt.Errorf(
+			"%w %q, supported drivers are %s",
+			ErrUnsupportedDriver,
+			name,
+			Drivers(),
+		)
+	}
+
+	return d, nil
+}
+
+// SupportsTransactionalDDL checks whether the driver's database
+// rolls back DDL statements along with the rest of a transaction.
+func SupportsTransactionalDDL(driver string) bool {
+	d, err := LookupDriver(driver)
+	return err == nil && d.TransactionalDDL
+}
+
+// SupportsSchemas checks whether the driver's database has
+// schemas that the schema setting can switch to.
+func SupportsSchemas(driver string) bool {
+	d, err := LookupDriver(driver)
+	return err == nil && d.Schemas
+}
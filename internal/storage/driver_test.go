@@ -0,0 +1,83 @@
+package storage_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/eugenetriguba/checkmate/assert"
+)
+
+func TestRegisterDriver(t *testing.T) {
+	err := storage.RegisterDriver("registered_sqlite", storage.Driver{
+		SQLDriverName:    "sqlite",
+		Adapter:          storage.SqliteAdapter{},
+		Transactions:     true,
+		TransactionalDDL: true,
+	})
+	assert.Nil(t, err)
+
+	assert.True(t, slices.Contains(storage.Drivers(), "registered_sqlite"))
+	assert.True(t, storage.SupportsTransactionalDDL("registered_sqlite"))
+	assert.False(t, storage.SupportsSchemas("registered_sqlite"))
+
+	db, err := storage.NewDB(configloader.ConnectionConfig{
+		Driver: "registered_sqlite",
+		DBName: filepath.Join(t.TempDir(), "bolt.db"),
+	})
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		assert.Nil(t, db.Close())
+	})
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+	exists, err := db.TableExists("tmp")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+}
+
+type unreachableSQLDriver struct{}
+
+func (d unreachableSQLDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("unreachable")
+}
+
+func TestRegisterDriver_RegistersSQLDriver(t *testing.T) {
+	err := storage.RegisterDriver("registered_unreachable", storage.Driver{
+		SQLDriverName: "bolt_unreachable",
+		SQLDriver:     unreachableSQLDriver{},
+		Adapter:       storage.PostgresqlAdapter{},
+	})
+	assert.Nil(t, err)
+
+	_, err = storage.NewDB(configloader.ConnectionConfig{Driver: "registered_unreachable"})
+
+	assert.ErrorIs(t, err, storage.ErrUnableToConnect)
+	assert.ErrorContains(t, err, "unreachable")
+}
+
+func TestRegisterDriver_AlreadyRegistered(t *testing.T) {
+	err := storage.RegisterDriver("postgresql", storage.Driver{
+		SQLDriverName: "pgx",
+		Adapter:       storage.PostgresqlAdapter{},
+	})
+
+	assert.ErrorIs(t, err, storage.ErrDriverRegistered)
+}
+
+func TestRegisterDriver_Invalid(t *testing.T) {
+	testCases := map[string]storage.Driver{
+		"":              {SQLDriverName: "pgx", Adapter: storage.PostgresqlAdapter{}},
+		"no_sql_driver": {Adapter: storage.PostgresqlAdapter{}},
+		"no_adapter":    {SQLDriverName: "pgx"},
+	}
+	for name, d := range testCases {
+		err := storage.RegisterDriver(name, d)
+		assert.ErrorIs(t, err, storage.ErrInvalidDriver)
+	}
+	assert.False(t, slices.Contains(storage.Drivers(), "no_adapter"))
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/eugenetriguba/bolt/internal/configloader"
+)
+
+var ErrRemoteDatabase = errors.New(
+	"remote libsql databases can't be created or dropped, use the turso cli instead",
+)
+
+// LibSQLAdapter is the adapter for libSQL, SQLite's fork used by
+// Turso. It's SQLite's SQL, so it builds on the SQLite adapter.
+//
+// The database name is the database's URL, such as
+// libsql://my-db.turso.io, with the password used as the auth
+// token. A file path, or file: URL, opens a local database file.
+type LibSQLAdapter struct {
+	SqliteAdapter
+}
+
+func (l LibSQLAdapter) CreateDSN(cfg configloader.ConnectionConfig) string {
+	if !l.isRemote(cfg.DBName) {
+		if strings.HasPrefix(cfg.DBName, "file:") {
+			return cfg.DBName
+		}
+		return "file:" + cfg.DBName
+	}
+
+	if cfg.Password == "" {
+		return cfg.DBName
+	}
+
+	dsn, err := url.Parse(cfg.DBName)
+	if err != nil {
+		return cfg.DBName
+	}
+	query := dsn.Query()
+	query.Set("authToken", cfg.Password)
+	dsn.RawQuery = query.Encode()
+	return dsn.String()
+}
+
+func (l LibSQLAdapter) CreateServerDSN(cfg configloader.ConnectionConfig) string {
+	// Local databases are files like SQLite's, so an in-memory
+	// database stands in for the server. Remote databases are
+	// managed by Turso rather than through a connection.
+	return "file::memory:"
+}
+
+// isRemote checks whether the database name is the URL of a
+// remote database rather than the path to a local one.
+func (l LibSQLAdapter) isRemote(dbName string) bool {
+	return strings.Contains(dbName, "://") && !strings.HasPrefix(dbName, "file:")
+}
+
+// localPath gives back the path to the local database file.
+func (l LibSQLAdapter) localPath(dbName string) string {
+	dbName = strings.TrimPrefix(dbName, "file:")
+	dbName = strings.TrimPrefix(dbName, "//")
+	path, _, _ := strings.Cut(dbName, "?")
+	return path
+}
+
+func (l LibSQLAdapter) DatabaseExists(executor SQLExecutor, dbName string) (bool, error) {
+	if l.isRemote(dbName) {
+		return false, ErrRemoteDatabase
+	}
+
+	return l.SqliteAdapter.DatabaseExists(executor, l.localPath(dbName))
+}
+
+func (l LibSQLAdapter) CreateDatabase(executor SQLExecutor, dbName string) error {
+	if l.isRemote(dbName) {
+		return ErrRemoteDatabase
+	}
+
+	return l.SqliteAdapter.CreateDatabase(executor, l.localPath(dbName))
+}
+
+func (l LibSQLAdapter) DropDatabase(executor SQLExecutor, dbName string) error {
+	if l.isRemote(dbName) {
+		return ErrRemoteDatabase
+	}
+
+	return l.SqliteAdapter.DropDatabase(executor, l.localPath(dbName))
+}
@@ -0,0 +1,116 @@
+//go:build libsql
+
+package storage_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eugenetriguba/bolt/internal/bolttest"
+	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/storage"
+	"github.com/eugenetriguba/checkmate/assert"
+)
+
+func TestLibSQL_TableExists(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.LibSQLAdapter{}
+	db, err := sql.Open("libsql", adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	exists, err := adapter.TableExists(db, "tmp")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	exists, err = adapter.TableExists(db, "tmp")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+}
+
+func TestLibSQL_CreateDSN(t *testing.T) {
+	type test struct {
+		dbName      string
+		password    string
+		expectedDSN string
+	}
+
+	testCases := []test{
+		{
+			dbName:      "./tmp/test.db",
+			expectedDSN: "file:./tmp/test.db",
+		},
+		{
+			dbName:      "file:/tmp/test.db",
+			expectedDSN: "file:/tmp/test.db",
+		},
+		{
+			dbName:      "libsql://my-db.turso.io",
+			expectedDSN: "libsql://my-db.turso.io",
+		},
+		{
+			dbName:      "libsql://my-db.turso.io",
+			password:    "token",
+			expectedDSN: "libsql://my-db.turso.io?authToken=token",
+		},
+		{
+			dbName:      "http://127.0.0.1:8080?tls=0",
+			password:    "token",
+			expectedDSN: "http://127.0.0.1:8080?authToken=token&tls=0",
+		},
+	}
+	for _, tc := range testCases {
+		cfg := configloader.ConnectionConfig{
+			Driver:   "libsql",
+			DBName:   tc.dbName,
+			Password: tc.password,
+		}
+		adapter := storage.LibSQLAdapter{}
+
+		dsn := adapter.CreateDSN(cfg)
+
+		assert.Equal(t, dsn, tc.expectedDSN)
+	}
+}
+
+func TestLibSQL_CreateAndDropDatabase(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	cfg.DBName = filepath.Join(t.TempDir(), "created.db")
+
+	err := storage.CreateDatabase(cfg)
+	assert.Nil(t, err)
+	_, err = os.Stat(cfg.DBName)
+	assert.Nil(t, err)
+	err = storage.CreateDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseExists)
+
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE tmp(id INT);")
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	err = storage.DropDatabase(cfg)
+	assert.Nil(t, err)
+	_, err = os.Stat(cfg.DBName)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	err = storage.DropDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseDoesNotExist)
+}
+
+func TestLibSQL_CreateRemoteDatabase(t *testing.T) {
+	adapter := storage.LibSQLAdapter{}
+
+	err := adapter.CreateDatabase(nil, "libsql://my-db.turso.io")
+
+	assert.ErrorIs(t, err, storage.ErrRemoteDatabase)
+}
@@ -1,11 +1,14 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
+	mssql "github.com/microsoft/go-mssqldb"
 	"github.com/microsoft/go-mssqldb/msdsn"
 )
 
@@ -27,7 +30,7 @@ func (m MSSQLAdapter) createPlaceholder(index int) string {
 }
 
 func (m MSSQLAdapter) TableExists(
-	executor sqlExecutor,
+	executor SQLExecutor,
 	tableName string,
 ) (bool, error) {
 	var exists bool
@@ -60,7 +63,44 @@ func (m MSSQLAdapter) TableExists(
 	return exists, nil
 }
 
-func (m MSSQLAdapter) DatabaseName(executor sqlExecutor) (string, error) {
+func (m MSSQLAdapter) ColumnExists(
+	executor SQLExecutor,
+	tableName string,
+	columnName string,
+) (bool, error) {
+	var exists bool
+
+	schemaName := "dbo"
+	parts := strings.Split(tableName, ".")
+	if len(parts) == 2 {
+		schemaName = parts[0]
+		tableName = parts[1]
+	} else {
+		tableName = parts[0]
+	}
+
+	err := executor.QueryRow(`
+		SELECT CASE WHEN EXISTS (
+			SELECT *
+			FROM INFORMATION_SCHEMA.COLUMNS
+			WHERE TABLE_SCHEMA = @p1
+			AND TABLE_NAME = @p2
+			AND COLUMN_NAME = @p3
+		) THEN 1 ELSE 0 END
+	`, schemaName, tableName, columnName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf(
+			"unable to check if %s.%s exists: %w",
+			tableName,
+			columnName,
+			err,
+		)
+	}
+
+	return exists, nil
+}
+
+func (m MSSQLAdapter) DatabaseName(executor SQLExecutor) (string, error) {
 	var name string
 	err := executor.QueryRow("SELECT DB_NAME();").Scan(&name)
 	if err != nil {
@@ -86,3 +126,416 @@ func (m MSSQLAdapter) CreateDSN(cfg configloader.ConnectionConfig) string {
 	}
 	return dsnCfg.URL().String()
 }
+
+func (m MSSQLAdapter) CreateServerDSN(cfg configloader.ConnectionConfig) string {
+	cfg.DBName = "master"
+	return m.CreateDSN(cfg)
+}
+
+func (m MSSQLAdapter) DatabaseExists(executor SQLExecutor, dbName string) (bool, error) {
+	var count int
+	err := executor.QueryRow(
+		"SELECT COUNT(*) FROM sys.databases WHERE name = @p1;",
+		dbName,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("unable to check if database %s exists: %w", dbName, err)
+	}
+
+	return count > 0, nil
+}
+
+func (m MSSQLAdapter) CreateDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("CREATE DATABASE %s;", m.quoteIdentifier(dbName)))
+	return err
+}
+
+func (m MSSQLAdapter) DropDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("DROP DATABASE %s;", m.quoteIdentifier(dbName)))
+	return err
+}
+
+func (m MSSQLAdapter) CreateSchema(executor SQLExecutor, schemaName string) error {
+	return ErrSchemasUnsupported
+}
+
+func (m MSSQLAdapter) IsTransientError(err error) bool {
+	var mssqlErr mssql.Error
+	if !errors.As(err, &mssqlErr) {
+		return false
+	}
+
+	switch mssqlErr.Number {
+	// The transaction was chosen as a deadlock victim, or
+	// the lock request time out period was exceeded.
+	case 1205, 1222:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m MSSQLAdapter) quoteIdentifier(identifier string) string {
+	return "[" + strings.ReplaceAll(identifier, "]", "]]") + "]"
+}
+
+func (m MSSQLAdapter) DumpSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (string, error) {
+	var schemaName string
+	err := executor.QueryRow("SELECT SCHEMA_NAME();").Scan(&schemaName)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve current schema: %w", err)
+	}
+
+	statements := make([]string, 0)
+	dumpers := []func(SQLExecutor, string, []string) ([]string, error){
+		m.dumpTables,
+		m.dumpKeyConstraints,
+		m.dumpCheckConstraints,
+		m.dumpIndexes,
+		m.dumpForeignKeys,
+	}
+	for _, dump := range dumpers {
+		dumped, err := dump(executor, schemaName, excludeTables)
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, dumped...)
+	}
+	modules, err := m.dumpModules(executor, schemaName, excludeTables)
+	if err != nil {
+		return "", err
+	}
+
+	// Views, procedures, functions, and triggers have to be the
+	// only statement in their batch, so each one is separated
+	// from the rest of the schema by the batch separator.
+	batches := []string{formatSchema(statements)}
+	for _, module := range modules {
+		batches = append(batches, formatSchema([]string{module}))
+	}
+	return m.joinBatches(batches), nil
+}
+
+// mssqlBatchSeparator is the line that separates
+// batches, as sqlcmd and SQL Server Management Studio do.
+const mssqlBatchSeparator = "GO"
+
+// joinBatches joins the batches of a script together
+// with the batch separator, leaving out empty batches.
+func (m MSSQLAdapter) joinBatches(batches []string) string {
+	nonEmpty := make([]string, 0, len(batches))
+	for _, batch := range batches {
+		batch = strings.TrimSpace(batch)
+		if batch != "" {
+			nonEmpty = append(nonEmpty, batch)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return ""
+	}
+	if len(nonEmpty) == 1 {
+		return nonEmpty[0] + "\n"
+	}
+
+	separator := "\n" + mssqlBatchSeparator + "\n"
+	return strings.Join(nonEmpty, separator+"\n") + separator
+}
+
+// queryStatements runs a query whose rows are a table name and a
+// statement, skipping any statements for excluded tables.
+func (m MSSQLAdapter) queryStatements(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+	query string,
+) ([]string, error) {
+	rows, err := executor.Query(query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query schema: %w", err)
+	}
+	defer rows.Close()
+
+	statements := make([]string, 0)
+	for rows.Next() {
+		var tableName, statement string
+		err = rows.Scan(&tableName, &statement)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan schema: %w", err)
+		}
+		if isExcludedTable(schemaName, tableName, schemaName, excludeTables) {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to query schema: %w", err)
+	}
+
+	return statements, nil
+}
+
+func (m MSSQLAdapter) dumpTables(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	return m.queryStatements(executor, schemaName, excludeTables, `
+		SELECT t.name, 'CREATE TABLE ' + QUOTENAME(t.name) + ' (' + CHAR(10) + STRING_AGG(
+			CAST('    ' + QUOTENAME(c.name) + ' ' + CASE
+				WHEN cc.definition IS NOT NULL
+					THEN 'AS ' + cc.definition + CASE WHEN cc.is_persisted = 1 THEN ' PERSISTED' ELSE '' END
+				ELSE ty.name + CASE
+					WHEN ty.name IN ('varchar', 'char', 'varbinary', 'binary')
+						THEN '(' + CASE WHEN c.max_length = -1 THEN 'MAX' ELSE CAST(c.max_length AS VARCHAR(10)) END + ')'
+					WHEN ty.name IN ('nvarchar', 'nchar')
+						THEN '(' + CASE WHEN c.max_length = -1 THEN 'MAX' ELSE CAST(c.max_length / 2 AS VARCHAR(10)) END + ')'
+					WHEN ty.name IN ('decimal', 'numeric')
+						THEN '(' + CAST(c.precision AS VARCHAR(10)) + ', ' + CAST(c.scale AS VARCHAR(10)) + ')'
+					WHEN ty.name IN ('datetime2', 'datetimeoffset', 'time')
+						THEN '(' + CAST(c.scale AS VARCHAR(10)) + ')'
+					ELSE ''
+				END
+				+ CASE WHEN ic.column_id IS NOT NULL
+					THEN ' IDENTITY(' + CAST(ic.seed_value AS VARCHAR(40)) + ', ' + CAST(ic.increment_value AS VARCHAR(40)) + ')'
+					ELSE ''
+				END
+				+ CASE WHEN dc.definition IS NOT NULL
+					THEN ' CONSTRAINT ' + QUOTENAME(dc.name) + ' DEFAULT ' + dc.definition
+					ELSE ''
+				END
+				+ CASE WHEN c.is_nullable = 1 THEN ' NULL' ELSE ' NOT NULL' END
+			END AS NVARCHAR(MAX)),
+			',' + CHAR(10)
+		) WITHIN GROUP (ORDER BY c.column_id) + CHAR(10) + ')'
+		FROM sys.tables t
+		JOIN sys.columns c ON c.object_id = t.object_id
+		JOIN sys.types ty ON ty.user_type_id = c.user_type_id
+		LEFT JOIN sys.identity_columns ic
+			ON ic.object_id = c.object_id AND ic.column_id = c.column_id
+		LEFT JOIN sys.default_constraints dc
+			ON dc.parent_object_id = c.object_id AND dc.parent_column_id = c.column_id
+		LEFT JOIN sys.computed_columns cc
+			ON cc.object_id = c.object_id AND cc.column_id = c.column_id
+		WHERE t.schema_id = SCHEMA_ID(@p1)
+		AND t.is_ms_shipped = 0
+		GROUP BY t.name
+		ORDER BY t.name;
+	`)
+}
+
+func (m MSSQLAdapter) dumpKeyConstraints(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	return m.queryStatements(executor, schemaName, excludeTables, `
+		SELECT t.name, 'ALTER TABLE ' + QUOTENAME(t.name)
+			+ ' ADD CONSTRAINT ' + QUOTENAME(kc.name)
+			+ CASE WHEN kc.type = 'PK' THEN ' PRIMARY KEY ' ELSE ' UNIQUE ' END
+			+ i.type_desc COLLATE DATABASE_DEFAULT + ' ('
+			+ STRING_AGG(
+				CAST(QUOTENAME(c.name) + CASE WHEN ixc.is_descending_key = 1 THEN ' DESC' ELSE '' END AS NVARCHAR(MAX)),
+				', '
+			) WITHIN GROUP (ORDER BY ixc.key_ordinal) + ')'
+		FROM sys.key_constraints kc
+		JOIN sys.tables t ON t.object_id = kc.parent_object_id
+		JOIN sys.indexes i
+			ON i.object_id = kc.parent_object_id AND i.index_id = kc.unique_index_id
+		JOIN sys.index_columns ixc
+			ON ixc.object_id = i.object_id AND ixc.index_id = i.index_id
+		JOIN sys.columns c
+			ON c.object_id = ixc.object_id AND c.column_id = ixc.column_id
+		WHERE t.schema_id = SCHEMA_ID(@p1)
+		AND t.is_ms_shipped = 0
+		GROUP BY t.name, kc.name, kc.type, i.type_desc
+		ORDER BY t.name, kc.name;
+	`)
+}
+
+func (m MSSQLAdapter) dumpCheckConstraints(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	return m.queryStatements(executor, schemaName, excludeTables, `
+		SELECT t.name, 'ALTER TABLE ' + QUOTENAME(t.name)
+			+ ' ADD CONSTRAINT ' + QUOTENAME(cc.name)
+			+ ' CHECK ' + cc.definition
+		FROM sys.check_constraints cc
+		JOIN sys.tables t ON t.object_id = cc.parent_object_id
+		WHERE t.schema_id = SCHEMA_ID(@p1)
+		AND t.is_ms_shipped = 0
+		ORDER BY t.name, cc.name;
+	`)
+}
+
+func (m MSSQLAdapter) dumpIndexes(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Indexes backing primary keys and unique constraints are
+	// created by their constraint.
+	return m.queryStatements(executor, schemaName, excludeTables, `
+		SELECT t.name, 'CREATE '
+			+ CASE WHEN i.is_unique = 1 THEN 'UNIQUE ' ELSE '' END
+			+ i.type_desc COLLATE DATABASE_DEFAULT
+			+ ' INDEX ' + QUOTENAME(i.name) + ' ON ' + QUOTENAME(t.name) + ' ('
+			+ STRING_AGG(
+				CASE WHEN ixc.is_included_column = 0
+					THEN CAST(QUOTENAME(c.name) + CASE WHEN ixc.is_descending_key = 1 THEN ' DESC' ELSE '' END AS NVARCHAR(MAX))
+				END,
+				', '
+			) WITHIN GROUP (ORDER BY ixc.key_ordinal) + ')'
+			+ ISNULL(' INCLUDE (' + STRING_AGG(
+				CASE WHEN ixc.is_included_column = 1
+					THEN CAST(QUOTENAME(c.name) AS NVARCHAR(MAX))
+				END,
+				', '
+			) WITHIN GROUP (ORDER BY ixc.index_column_id) + ')', '')
+			+ ISNULL(' WHERE ' + MAX(i.filter_definition), '')
+		FROM sys.indexes i
+		JOIN sys.tables t ON t.object_id = i.object_id
+		JOIN sys.index_columns ixc
+			ON ixc.object_id = i.object_id AND ixc.index_id = i.index_id
+		JOIN sys.columns c
+			ON c.object_id = ixc.object_id AND c.column_id = ixc.column_id
+		WHERE t.schema_id = SCHEMA_ID(@p1)
+		AND t.is_ms_shipped = 0
+		AND i.type > 0
+		AND i.is_primary_key = 0
+		AND i.is_unique_constraint = 0
+		GROUP BY t.name, i.name, i.is_unique, i.type_desc
+		ORDER BY t.name, i.name;
+	`)
+}
+
+func (m MSSQLAdapter) dumpForeignKeys(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Foreign keys come after the tables and key constraints
+	// they reference.
+	return m.queryStatements(executor, schemaName, excludeTables, `
+		SELECT t.name, 'ALTER TABLE ' + QUOTENAME(t.name)
+			+ ' ADD CONSTRAINT ' + QUOTENAME(fk.name) + ' FOREIGN KEY ('
+			+ STRING_AGG(CAST(QUOTENAME(pc.name) AS NVARCHAR(MAX)), ', ')
+				WITHIN GROUP (ORDER BY fkc.constraint_column_id)
+			+ ') REFERENCES ' + QUOTENAME(rt.name) + ' ('
+			+ STRING_AGG(CAST(QUOTENAME(rc.name) AS NVARCHAR(MAX)), ', ')
+				WITHIN GROUP (ORDER BY fkc.constraint_column_id)
+			+ ') ON DELETE ' + REPLACE(fk.delete_referential_action_desc, '_', ' ')
+			+ ' ON UPDATE ' + REPLACE(fk.update_referential_action_desc, '_', ' ')
+		FROM sys.foreign_keys fk
+		JOIN sys.tables t ON t.object_id = fk.parent_object_id
+		JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.columns pc
+			ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		JOIN sys.columns rc
+			ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		WHERE t.schema_id = SCHEMA_ID(@p1)
+		AND t.is_ms_shipped = 0
+		GROUP BY t.name, fk.name, rt.name,
+			fk.delete_referential_action_desc, fk.update_referential_action_desc
+		ORDER BY t.name, fk.name;
+	`)
+}
+
+func (m MSSQLAdapter) dumpModules(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Views, procedures, functions, and triggers can depend on
+	// each other, so they are kept in the order they were created in.
+	return m.queryStatements(executor, schemaName, excludeTables, `
+		SELECT ISNULL(OBJECT_NAME(o.parent_object_id), ''), sm.definition
+		FROM sys.sql_modules sm
+		JOIN sys.objects o ON o.object_id = sm.object_id
+		WHERE o.schema_id = SCHEMA_ID(@p1)
+		AND o.is_ms_shipped = 0
+		AND o.type IN ('V', 'P', 'FN', 'IF', 'TF', 'TR')
+		ORDER BY o.create_date, o.object_id;
+	`)
+}
+
+func (m MSSQLAdapter) InspectSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (models.Schema, error) {
+	var schemaName string
+	err := executor.QueryRow("SELECT SCHEMA_NAME();").Scan(&schemaName)
+	if err != nil {
+		return models.Schema{}, fmt.Errorf("unable to retrieve current schema: %w", err)
+	}
+
+	// Indexes backing primary keys and unique constraints are
+	// left out since the constraint covers them.
+	return inspectSchema(executor, schemaName, excludeTables, `
+		WITH tables AS (
+			SELECT t.object_id, t.name
+			FROM sys.tables t
+			WHERE t.schema_id = SCHEMA_ID(@p1)
+			AND t.is_ms_shipped = 0
+		)
+		SELECT t.name, 'table', t.name, '', CAST(0 AS BIT)
+		FROM tables t
+		UNION ALL
+		SELECT t.name, 'column', c.name, ty.name + CASE
+			WHEN ty.name IN ('varchar', 'char', 'varbinary', 'binary')
+				THEN '(' + CASE WHEN c.max_length = -1 THEN 'MAX' ELSE CAST(c.max_length AS VARCHAR(10)) END + ')'
+			WHEN ty.name IN ('nvarchar', 'nchar')
+				THEN '(' + CASE WHEN c.max_length = -1 THEN 'MAX' ELSE CAST(c.max_length / 2 AS VARCHAR(10)) END + ')'
+			WHEN ty.name IN ('decimal', 'numeric')
+				THEN '(' + CAST(c.precision AS VARCHAR(10)) + ', ' + CAST(c.scale AS VARCHAR(10)) + ')'
+			ELSE ''
+		END, c.is_nullable
+		FROM tables t
+		JOIN sys.columns c ON c.object_id = t.object_id
+		JOIN sys.types ty ON ty.user_type_id = c.user_type_id
+		UNION ALL
+		SELECT t.name, 'index', i.name, '', CAST(0 AS BIT)
+		FROM tables t
+		JOIN sys.indexes i ON i.object_id = t.object_id
+		WHERE i.type > 0
+		AND i.is_primary_key = 0
+		AND i.is_unique_constraint = 0
+		UNION ALL
+		SELECT t.name, 'constraint', CASE WHEN kc.type = 'PK' THEN 'PRIMARY KEY (' ELSE 'UNIQUE (' END
+			+ STRING_AGG(CAST(c.name AS NVARCHAR(MAX)), ', ')
+				WITHIN GROUP (ORDER BY ixc.key_ordinal) + ')', '', CAST(0 AS BIT)
+		FROM tables t
+		JOIN sys.key_constraints kc ON kc.parent_object_id = t.object_id
+		JOIN sys.index_columns ixc
+			ON ixc.object_id = t.object_id AND ixc.index_id = kc.unique_index_id
+		JOIN sys.columns c
+			ON c.object_id = ixc.object_id AND c.column_id = ixc.column_id
+		GROUP BY t.name, kc.name, kc.type
+		UNION ALL
+		SELECT t.name, 'constraint', 'FOREIGN KEY ('
+			+ STRING_AGG(CAST(pc.name AS NVARCHAR(MAX)), ', ')
+				WITHIN GROUP (ORDER BY fkc.constraint_column_id)
+			+ ') REFERENCES ' + rt.name + ' ('
+			+ STRING_AGG(CAST(rc.name AS NVARCHAR(MAX)), ', ')
+				WITHIN GROUP (ORDER BY fkc.constraint_column_id)
+			+ ')', '', CAST(0 AS BIT)
+		FROM tables t
+		JOIN sys.foreign_keys fk ON fk.parent_object_id = t.object_id
+		JOIN sys.tables rt ON rt.object_id = fk.referenced_object_id
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.columns pc
+			ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		JOIN sys.columns rc
+			ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		GROUP BY t.name, fk.name, rt.name
+		UNION ALL
+		SELECT t.name, 'constraint', 'CHECK ' + cc.definition, '', CAST(0 AS BIT)
+		FROM tables t
+		JOIN sys.check_constraints cc ON cc.parent_object_id = t.object_id;
+	`, schemaName)
+}
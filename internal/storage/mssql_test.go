@@ -4,13 +4,17 @@ package storage_test
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/eugenetriguba/bolt/internal/bolttest"
 	"github.com/eugenetriguba/bolt/internal/configloader"
 	"github.com/eugenetriguba/bolt/internal/storage"
 	"github.com/eugenetriguba/checkmate/assert"
+	mssql "github.com/microsoft/go-mssqldb"
 )
 
 func TestMSSQL_ConvertGenericPlaceholders(t *testing.T) {
@@ -151,3 +155,158 @@ func TestMSSQL_CreateDSN_UnparsablePort(t *testing.T) {
 	)
 	assert.Equal(t, cs, expectedConnectionString)
 }
+
+func TestMSSQL_DumpSchema(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.MSSQLAdapter{}
+	db, err := sql.Open("sqlserver", adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp_child;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS excluded;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY, name VARCHAR(255) NOT NULL);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE INDEX tmp_name_idx ON tmp(name);")
+	assert.Nil(t, err)
+	_, err = db.Exec(
+		"CREATE TABLE tmp_child(id INT PRIMARY KEY, tmp_id INT, " +
+			"CONSTRAINT tmp_child_tmp_fk FOREIGN KEY (tmp_id) REFERENCES tmp(id));",
+	)
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE excluded(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	schema, err := adapter.DumpSchema(db, []string{"excluded"})
+	assert.Nil(t, err)
+
+	assert.True(t, strings.Contains(schema, "CREATE TABLE"))
+	assert.True(t, strings.Contains(schema, "tmp_name_idx"))
+	assert.True(t, strings.Contains(schema, "tmp_child_tmp_fk"))
+	assert.False(t, strings.Contains(schema, "excluded"))
+	// Foreign keys are added after every table is created.
+	assert.True(
+		t,
+		strings.Index(schema, "tmp_child_tmp_fk") > strings.LastIndex(schema, "CREATE TABLE"),
+	)
+}
+
+func TestMSSQL_DumpSchemaLoadsModules(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	dropObjects := func() {
+		_, err := db.Exec("DROP PROCEDURE IF EXISTS tmp_proc;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP VIEW IF EXISTS tmp_view;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+	}
+	t.Cleanup(func() {
+		dropObjects()
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE VIEW tmp_view AS SELECT id FROM tmp;")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE PROCEDURE tmp_proc AS SELECT id FROM tmp_view;")
+	assert.Nil(t, err)
+
+	schema, err := db.DumpSchema()
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(schema, "\nGO\n"))
+
+	dropObjects()
+	_, err = db.Exec(schema)
+	assert.Nil(t, err)
+
+	var count int
+	err = db.QueryRow(
+		"SELECT COUNT(*) FROM sys.objects WHERE name IN ('tmp', 'tmp_view', 'tmp_proc');",
+	).Scan(&count)
+	assert.Nil(t, err)
+	assert.Equal(t, count, 3)
+}
+
+func TestMSSQL_InspectSchema(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.MSSQLAdapter{}
+	db, err := sql.Open("sqlserver", adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp_child;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS excluded;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY, name VARCHAR(255) NOT NULL);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE INDEX tmp_name_idx ON tmp(name);")
+	assert.Nil(t, err)
+	_, err = db.Exec(
+		"CREATE TABLE tmp_child(id INT PRIMARY KEY, tmp_id INT, " +
+			"CONSTRAINT tmp_child_tmp_fk FOREIGN KEY (tmp_id) REFERENCES tmp(id));",
+	)
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE excluded(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	schema, err := adapter.InspectSchema(db, []string{"excluded"})
+	assert.Nil(t, err)
+
+	assert.Equal(t, len(schema.Tables), 2)
+	assert.Equal(t, schema.Tables[0].Name, "tmp")
+	assert.Equal(t, len(schema.Tables[0].Columns), 2)
+	assert.DeepEqual(t, schema.Tables[0].Indexes, []string{"tmp_name_idx"})
+	assert.Equal(t, schema.Tables[1].Name, "tmp_child")
+	assert.True(t, slices.Contains(
+		schema.Tables[1].Constraints,
+		"FOREIGN KEY (tmp_id) REFERENCES tmp (id)",
+	))
+}
+
+func TestMSSQL_CreateAndDropDatabase(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	cfg.DBName = "bolt_created_db"
+	t.Cleanup(func() {
+		_ = storage.DropDatabase(cfg)
+	})
+
+	err := storage.CreateDatabase(cfg)
+	assert.Nil(t, err)
+	err = storage.CreateDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseExists)
+
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	err = storage.DropDatabase(cfg)
+	assert.Nil(t, err)
+	err = storage.DropDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseDoesNotExist)
+}
+
+func TestMSSQL_IsTransientError(t *testing.T) {
+	adapter := storage.MSSQLAdapter{}
+
+	for _, number := range []int32{1205, 1222} {
+		err := fmt.Errorf("unable to execute: %w", mssql.Error{Number: number})
+		assert.True(t, adapter.IsTransientError(err))
+	}
+	assert.False(t, adapter.IsTransientError(mssql.Error{Number: 208}))
+	assert.False(t, adapter.IsTransientError(errors.New("deadlock victim")))
+}
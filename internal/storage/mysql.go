@@ -1,9 +1,13 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/go-sql-driver/mysql"
 )
 
@@ -16,7 +20,7 @@ func (m MySQLAdapter) ConvertGenericPlaceholders(query string, argsCount int) st
 }
 
 func (m MySQLAdapter) TableExists(
-	executor sqlExecutor,
+	executor SQLExecutor,
 	tableName string,
 ) (bool, error) {
 	// Note: MySQL doesn't have schemas. So the "table_schema"
@@ -46,7 +50,39 @@ func (m MySQLAdapter) TableExists(
 	return exists, nil
 }
 
-func (m MySQLAdapter) DatabaseName(executor sqlExecutor) (string, error) {
+func (m MySQLAdapter) ColumnExists(
+	executor SQLExecutor,
+	tableName string,
+	columnName string,
+) (bool, error) {
+	databaseName, err := m.DatabaseName(executor)
+	if err != nil {
+		return false, fmt.Errorf("unable to retrieve database name: %w", err)
+	}
+
+	var exists bool
+	err = executor.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM INFORMATION_SCHEMA.COLUMNS
+			WHERE TABLE_SCHEMA = ?
+			AND TABLE_NAME = ?
+			AND COLUMN_NAME = ?
+		);
+	`, databaseName, tableName, columnName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf(
+			"unable to check if %s.%s exists: %w",
+			tableName,
+			columnName,
+			err,
+		)
+	}
+
+	return exists, nil
+}
+
+func (m MySQLAdapter) DatabaseName(executor SQLExecutor) (string, error) {
 	var name string
 	err := executor.QueryRow("SELECT DATABASE();").Scan(&name)
 	if err != nil {
@@ -72,6 +108,245 @@ func (m MySQLAdapter) CreateDSN(cfg configloader.ConnectionConfig) string {
 		User:   cfg.User,
 		Passwd: cfg.Password,
 		DBName: cfg.DBName,
+		// Migration scripts and schema dumps are
+		// executed with every statement at once.
+		MultiStatements: true,
 	}
 	return dsnCfg.FormatDSN()
 }
+
+func (m MySQLAdapter) CreateServerDSN(cfg configloader.ConnectionConfig) string {
+	// MySQL allows connecting without a database selected.
+	cfg.DBName = ""
+	return m.CreateDSN(cfg)
+}
+
+func (m MySQLAdapter) DatabaseExists(executor SQLExecutor, dbName string) (bool, error) {
+	var count int
+	err := executor.QueryRow(
+		"SELECT COUNT(*) FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?;",
+		dbName,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("unable to check if database %s exists: %w", dbName, err)
+	}
+
+	return count > 0, nil
+}
+
+func (m MySQLAdapter) CreateDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("CREATE DATABASE %s;", m.quoteIdentifier(dbName)))
+	return err
+}
+
+func (m MySQLAdapter) DropDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("DROP DATABASE %s;", m.quoteIdentifier(dbName)))
+	return err
+}
+
+func (m MySQLAdapter) CreateSchema(executor SQLExecutor, schemaName string) error {
+	return ErrSchemasUnsupported
+}
+
+func (m MySQLAdapter) IsTransientError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+
+	switch mysqlErr.Number {
+	// ER_LOCK_WAIT_TIMEOUT and ER_LOCK_DEADLOCK.
+	case 1205, 1213:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m MySQLAdapter) quoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+var (
+	mysqlAutoIncrementPattern = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+	mysqlViewDefinerPattern   = regexp.MustCompile(`^CREATE .*?VIEW`)
+)
+
+func (m MySQLAdapter) DumpSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (string, error) {
+	databaseName, err := m.DatabaseName(executor)
+	if err != nil {
+		return "", err
+	}
+
+	rows, err := executor.Query(`
+		SELECT TABLE_NAME, TABLE_TYPE
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_TYPE, TABLE_NAME;
+	`, databaseName)
+	if err != nil {
+		return "", fmt.Errorf("unable to query schema: %w", err)
+	}
+	defer rows.Close()
+
+	tableNames := make([]string, 0)
+	viewNames := make([]string, 0)
+	for rows.Next() {
+		var tableName, tableType string
+		err = rows.Scan(&tableName, &tableType)
+		if err != nil {
+			return "", fmt.Errorf("unable to scan schema: %w", err)
+		}
+		if isExcludedTable(databaseName, tableName, databaseName, excludeTables) {
+			continue
+		}
+		if tableType == "VIEW" {
+			viewNames = append(viewNames, tableName)
+		} else {
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("unable to query schema: %w", err)
+	}
+
+	statements := make([]string, 0)
+	foreignKeys := make([]string, 0)
+	for _, tableName := range tableNames {
+		var name, statement string
+		err = executor.QueryRow(
+			fmt.Sprintf("SHOW CREATE TABLE `%s`;", tableName),
+		).Scan(&name, &statement)
+		if err != nil {
+			return "", fmt.Errorf("unable to dump table %s: %w", tableName, err)
+		}
+
+		statement, tableForeignKeys := m.splitForeignKeys(tableName, statement)
+		statements = append(
+			statements,
+			mysqlAutoIncrementPattern.ReplaceAllString(statement, ""),
+		)
+		foreignKeys = append(foreignKeys, tableForeignKeys...)
+	}
+	// Foreign keys are added once all the tables exist so that
+	// the tables can be created in any order.
+	statements = append(statements, foreignKeys...)
+
+	for _, viewName := range viewNames {
+		var name, statement, characterSet, collation string
+		err = executor.QueryRow(
+			fmt.Sprintf("SHOW CREATE VIEW `%s`;", viewName),
+		).Scan(&name, &statement, &characterSet, &collation)
+		if err != nil {
+			return "", fmt.Errorf("unable to dump view %s: %w", viewName, err)
+		}
+
+		// The definer and security options are specific to the
+		// server the view was created on.
+		statements = append(
+			statements,
+			mysqlViewDefinerPattern.ReplaceAllString(statement, "CREATE VIEW"),
+		)
+	}
+
+	return formatSchema(statements), nil
+}
+
+// splitForeignKeys removes the foreign key constraints from a
+// CREATE TABLE statement and returns them as separate ALTER TABLE
+// statements.
+func (m MySQLAdapter) splitForeignKeys(
+	tableName string,
+	statement string,
+) (string, []string) {
+	lines := strings.Split(statement, "\n")
+	keptLines := make([]string, 0, len(lines))
+	foreignKeys := make([]string, 0)
+	for _, line := range lines {
+		trimmedLine := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmedLine, "CONSTRAINT ") &&
+			strings.Contains(trimmedLine, " FOREIGN KEY ") {
+			foreignKeys = append(foreignKeys, fmt.Sprintf(
+				"ALTER TABLE `%s` ADD %s",
+				tableName,
+				strings.TrimSuffix(trimmedLine, ","),
+			))
+			continue
+		}
+		keptLines = append(keptLines, line)
+	}
+
+	// The line before the closing parenthesis can't end with a
+	// comma now that the foreign keys after it are gone.
+	for i := len(keptLines) - 1; i > 0; i-- {
+		if strings.HasPrefix(strings.TrimSpace(keptLines[i]), ")") {
+			keptLines[i-1] = strings.TrimSuffix(keptLines[i-1], ",")
+			break
+		}
+	}
+
+	return strings.Join(keptLines, "\n"), foreignKeys
+}
+
+func (m MySQLAdapter) InspectSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (models.Schema, error) {
+	databaseName, err := m.DatabaseName(executor)
+	if err != nil {
+		return models.Schema{}, err
+	}
+
+	// MySQL creates an index for every key and constraint under the
+	// constraint's name, so only indexes without a matching
+	// constraint are reported as indexes.
+	return inspectSchema(executor, databaseName, excludeTables, `
+		SELECT t.TABLE_NAME, 'table', t.TABLE_NAME, '', 0
+		FROM INFORMATION_SCHEMA.TABLES t
+		WHERE t.TABLE_SCHEMA = ? AND t.TABLE_TYPE = 'BASE TABLE'
+		UNION ALL
+		SELECT c.TABLE_NAME, 'column', c.COLUMN_NAME, c.COLUMN_TYPE, c.IS_NULLABLE = 'YES'
+		FROM INFORMATION_SCHEMA.COLUMNS c
+		JOIN INFORMATION_SCHEMA.TABLES t
+			ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+		WHERE c.TABLE_SCHEMA = ? AND t.TABLE_TYPE = 'BASE TABLE'
+		UNION ALL
+		SELECT DISTINCT s.TABLE_NAME, 'index', s.INDEX_NAME, '', 0
+		FROM INFORMATION_SCHEMA.STATISTICS s
+		WHERE s.TABLE_SCHEMA = ?
+		AND NOT EXISTS (
+			SELECT 1 FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+			WHERE tc.TABLE_SCHEMA = s.TABLE_SCHEMA
+			AND tc.TABLE_NAME = s.TABLE_NAME
+			AND tc.CONSTRAINT_NAME = s.INDEX_NAME
+		)
+		UNION ALL
+		SELECT tc.TABLE_NAME, 'constraint', CONCAT(
+			tc.CONSTRAINT_TYPE, ' (',
+			GROUP_CONCAT(k.COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '),
+			')',
+			IFNULL(CONCAT(
+				' REFERENCES ', MAX(k.REFERENCED_TABLE_NAME), ' (',
+				GROUP_CONCAT(k.REFERENCED_COLUMN_NAME ORDER BY k.ORDINAL_POSITION SEPARATOR ', '),
+				')'
+			), '')
+		), '', 0
+		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k
+			ON k.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND k.TABLE_NAME = tc.TABLE_NAME
+			AND k.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = ?
+		GROUP BY tc.TABLE_NAME, tc.CONSTRAINT_NAME, tc.CONSTRAINT_TYPE
+		UNION ALL
+		SELECT tc.TABLE_NAME, 'constraint', CONCAT('CHECK ', cc.CHECK_CLAUSE), '', 0
+		FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		JOIN INFORMATION_SCHEMA.CHECK_CONSTRAINTS cc
+			ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = ? AND tc.CONSTRAINT_TYPE = 'CHECK';
+	`, databaseName, databaseName, databaseName, databaseName, databaseName)
+}
@@ -4,13 +4,17 @@ package storage_test
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/eugenetriguba/bolt/internal/bolttest"
 	"github.com/eugenetriguba/bolt/internal/configloader"
 	"github.com/eugenetriguba/bolt/internal/storage"
 	"github.com/eugenetriguba/checkmate/assert"
+	"github.com/go-sql-driver/mysql"
 )
 
 func TestMySQL_ConvertGenericPlaceholders(t *testing.T) {
@@ -101,3 +105,134 @@ func TestMySQL_CreateDSN(t *testing.T) {
 	)
 	assert.Equal(t, cs, expectedConnectionString)
 }
+
+func TestMySQL_CreateDSN_MultiStatements(t *testing.T) {
+	cfg := configloader.ConnectionConfig{
+		Driver: "mysql",
+		Host:   "db1",
+		Port:   "3306",
+		User:   "testuser",
+		DBName: "testdb",
+	}
+	adapter := storage.MySQLAdapter{}
+
+	dsn, err := mysql.ParseDSN(adapter.CreateDSN(cfg))
+
+	assert.Nil(t, err)
+	assert.True(t, dsn.MultiStatements)
+}
+
+func TestMySQL_DumpSchema(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.MySQLAdapter{}
+	db, err := sql.Open("mysql", adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp_child;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS excluded;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY, name VARCHAR(255) NOT NULL);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE INDEX tmp_name_idx ON tmp(name);")
+	assert.Nil(t, err)
+	_, err = db.Exec(
+		"CREATE TABLE tmp_child(id INT PRIMARY KEY, tmp_id INT, " +
+			"CONSTRAINT tmp_child_tmp_fk FOREIGN KEY (tmp_id) REFERENCES tmp(id));",
+	)
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE excluded(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	schema, err := adapter.DumpSchema(db, []string{"excluded"})
+	assert.Nil(t, err)
+
+	assert.True(t, strings.Contains(schema, "CREATE TABLE"))
+	assert.True(t, strings.Contains(schema, "tmp_name_idx"))
+	assert.True(t, strings.Contains(schema, "tmp_child_tmp_fk"))
+	assert.False(t, strings.Contains(schema, "excluded"))
+	// Foreign keys are added after every table is created.
+	assert.True(
+		t,
+		strings.Index(schema, "tmp_child_tmp_fk") > strings.LastIndex(schema, "CREATE TABLE"),
+	)
+}
+
+func TestMySQL_InspectSchema(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.MySQLAdapter{}
+	db, err := sql.Open("mysql", adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp_child;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS excluded;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY, name VARCHAR(255) NOT NULL);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE INDEX tmp_name_idx ON tmp(name);")
+	assert.Nil(t, err)
+	_, err = db.Exec(
+		"CREATE TABLE tmp_child(id INT PRIMARY KEY, tmp_id INT, " +
+			"CONSTRAINT tmp_child_tmp_fk FOREIGN KEY (tmp_id) REFERENCES tmp(id));",
+	)
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE excluded(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	schema, err := adapter.InspectSchema(db, []string{"excluded"})
+	assert.Nil(t, err)
+
+	assert.Equal(t, len(schema.Tables), 2)
+	assert.Equal(t, schema.Tables[0].Name, "tmp")
+	assert.Equal(t, len(schema.Tables[0].Columns), 2)
+	assert.DeepEqual(t, schema.Tables[0].Indexes, []string{"tmp_name_idx"})
+	assert.Equal(t, schema.Tables[1].Name, "tmp_child")
+	assert.True(t, slices.Contains(
+		schema.Tables[1].Constraints,
+		"FOREIGN KEY (tmp_id) REFERENCES tmp (id)",
+	))
+}
+
+func TestMySQL_CreateAndDropDatabase(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	cfg.DBName = "bolt_created_db"
+	t.Cleanup(func() {
+		_ = storage.DropDatabase(cfg)
+	})
+
+	err := storage.CreateDatabase(cfg)
+	assert.Nil(t, err)
+	err = storage.CreateDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseExists)
+
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	err = storage.DropDatabase(cfg)
+	assert.Nil(t, err)
+	err = storage.DropDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseDoesNotExist)
+}
+
+func TestMySQL_IsTransientError(t *testing.T) {
+	adapter := storage.MySQLAdapter{}
+
+	for _, number := range []uint16{1205, 1213} {
+		err := fmt.Errorf("unable to execute: %w", &mysql.MySQLError{Number: number})
+		assert.True(t, adapter.IsTransientError(err))
+	}
+	assert.False(t, adapter.IsTransientError(&mysql.MySQLError{Number: 1146}))
+	assert.False(t, adapter.IsTransientError(errors.New("deadlock found")))
+}
@@ -1,10 +1,14 @@
 package storage
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type PostgresqlAdapter struct{}
@@ -25,25 +29,17 @@ func (p PostgresqlAdapter) createPlaceholder(index int) string {
 }
 
 func (p PostgresqlAdapter) TableExists(
-	executor sqlExecutor,
+	executor SQLExecutor,
 	tableName string,
 ) (bool, error) {
 	var exists bool
 
-	schemaName := "public"
-	parts := strings.Split(tableName, ".")
-	if len(parts) == 2 {
-		schemaName = parts[0]
-		tableName = parts[1]
-	} else {
-		tableName = parts[0]
-	}
-
+	schemaName, tableName := p.splitTableName(tableName)
 	err := executor.QueryRow(`
 		SELECT EXISTS (
 			SELECT FROM pg_catalog.pg_class c
 			JOIN   pg_catalog.pg_namespace n ON n.oid = c.relnamespace
-			WHERE  n.nspname = $1
+			WHERE  n.nspname = COALESCE($1, current_schema())
 			AND    c.relname = $2
 			AND    c.relkind = 'r'  -- Only tables
 		);
@@ -59,7 +55,48 @@ func (p PostgresqlAdapter) TableExists(
 	return exists, nil
 }
 
-func (p PostgresqlAdapter) DatabaseName(executor sqlExecutor) (string, error) {
+func (p PostgresqlAdapter) ColumnExists(
+	executor SQLExecutor,
+	tableName string,
+	columnName string,
+) (bool, error) {
+	var exists bool
+
+	schemaName, tableName := p.splitTableName(tableName)
+	err := executor.QueryRow(`
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE  table_schema = COALESCE($1, current_schema())
+			AND    table_name = $2
+			AND    column_name = $3
+		);
+	`, schemaName, tableName, columnName).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf(
+			"unable to check if %s.%s exists: %w",
+			tableName,
+			columnName,
+			err,
+		)
+	}
+
+	return exists, nil
+}
+
+// splitTableName splits a schema-qualified table name into its
+// schema and table. The schema is NULL for unqualified names so
+// that they're looked up in the current schema, the first schema
+// on the search_path that exists.
+func (p PostgresqlAdapter) splitTableName(tableName string) (sql.NullString, string) {
+	schemaName, name, qualified := strings.Cut(tableName, ".")
+	if !qualified {
+		return sql.NullString{}, tableName
+	}
+
+	return sql.NullString{String: schemaName, Valid: true}, name
+}
+
+func (p PostgresqlAdapter) DatabaseName(executor SQLExecutor) (string, error) {
 	var name string
 	err := executor.QueryRow("SELECT current_database();").Scan(&name)
 	if err != nil {
@@ -70,8 +107,417 @@ func (p PostgresqlAdapter) DatabaseName(executor sqlExecutor) (string, error) {
 }
 
 func (p PostgresqlAdapter) CreateDSN(cfg configloader.ConnectionConfig) string {
-	return fmt.Sprintf(
+	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName,
 	)
+	if cfg.Schema != "" {
+		// The search_path is set as a connection parameter so that
+		// every connection in the pool uses the schema, not only the
+		// one a SET statement would've been executed on.
+		searchPath := strings.NewReplacer(`\`, `\\`, `'`, `\'`).
+			Replace(p.quoteIdentifier(cfg.Schema))
+		dsn += fmt.Sprintf(" search_path='%s'", searchPath)
+	}
+
+	return dsn
+}
+
+func (p PostgresqlAdapter) CreateServerDSN(cfg configloader.ConnectionConfig) string {
+	cfg.DBName = "postgres"
+	cfg.Schema = ""
+	return p.CreateDSN(cfg)
+}
+
+func (p PostgresqlAdapter) CreateSchema(executor SQLExecutor, schemaName string) error {
+	_, err := executor.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s;", p.quoteIdentifier(schemaName)))
+	return err
+}
+
+func (p PostgresqlAdapter) DropSchema(executor SQLExecutor, schemaName string) error {
+	_, err := executor.Exec(
+		fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE;", p.quoteIdentifier(schemaName)),
+	)
+	return err
+}
+
+func (p PostgresqlAdapter) DatabaseExists(executor SQLExecutor, dbName string) (bool, error) {
+	var exists bool
+	err := executor.QueryRow(
+		"SELECT EXISTS (SELECT FROM pg_catalog.pg_database WHERE datname = $1);",
+		dbName,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("unable to check if database %s exists: %w", dbName, err)
+	}
+
+	return exists, nil
+}
+
+func (p PostgresqlAdapter) CreateDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("CREATE DATABASE %s;", p.quoteIdentifier(dbName)))
+	return err
+}
+
+func (p PostgresqlAdapter) DropDatabase(executor SQLExecutor, dbName string) error {
+	_, err := executor.Exec(fmt.Sprintf("DROP DATABASE %s;", p.quoteIdentifier(dbName)))
+	return err
+}
+
+func (p PostgresqlAdapter) IsTransientError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	// serialization_failure, deadlock_detected, and lock_not_available.
+	case "40001", "40P01", "55P03":
+		return true
+	default:
+		return false
+	}
+}
+
+func (p PostgresqlAdapter) quoteIdentifier(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func (p PostgresqlAdapter) DumpSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (string, error) {
+	var schemaName string
+	err := executor.QueryRow("SELECT current_schema();").Scan(&schemaName)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve current schema: %w", err)
+	}
+
+	statements := make([]string, 0)
+	dumpers := []func(SQLExecutor, string, []string) ([]string, error){
+		p.dumpEnums,
+		p.dumpSequences,
+		p.dumpTables,
+		p.dumpSequenceOwners,
+		p.dumpConstraints,
+		p.dumpIndexes,
+		p.dumpViews,
+		p.dumpFunctions,
+		p.dumpTriggers,
+	}
+	for _, dump := range dumpers {
+		dumped, err := dump(executor, schemaName, excludeTables)
+		if err != nil {
+			return "", err
+		}
+		statements = append(statements, dumped...)
+	}
+
+	return formatSchema(statements), nil
+}
+
+// queryStatements runs a query whose rows are a table name and a
+// statement, skipping any statements for excluded tables.
+func (p PostgresqlAdapter) queryStatements(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+	query string,
+) ([]string, error) {
+	rows, err := executor.Query(query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query schema: %w", err)
+	}
+	defer rows.Close()
+
+	statements := make([]string, 0)
+	for rows.Next() {
+		var tableName, statement string
+		err = rows.Scan(&tableName, &statement)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan schema: %w", err)
+		}
+		if isExcludedTable(schemaName, tableName, schemaName, excludeTables) {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to query schema: %w", err)
+	}
+
+	return statements, nil
+}
+
+func (p PostgresqlAdapter) dumpEnums(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT '', format(
+			'CREATE TYPE %I AS ENUM (%s)',
+			t.typname,
+			string_agg(quote_literal(e.enumlabel), ', ' ORDER BY e.enumsortorder)
+		)
+		FROM pg_catalog.pg_type t
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+		JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid
+		WHERE n.nspname = $1
+		GROUP BY t.typname
+		ORDER BY t.typname;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpSequences(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Sequences backing identity columns are created along
+	// with their table, so they're left out.
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT '', format(
+			'CREATE SEQUENCE %I AS %s START WITH %s INCREMENT BY %s '
+				'MINVALUE %s MAXVALUE %s CACHE %s%s',
+			s.sequencename,
+			s.data_type,
+			s.start_value,
+			s.increment_by,
+			s.min_value,
+			s.max_value,
+			s.cache_size,
+			CASE WHEN s.cycle THEN ' CYCLE' ELSE '' END
+		)
+		FROM pg_catalog.pg_sequences s
+		JOIN pg_catalog.pg_class c ON c.relname = s.sequencename
+		JOIN pg_catalog.pg_namespace n
+			ON n.oid = c.relnamespace AND n.nspname = s.schemaname
+		WHERE s.schemaname = $1
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_depend d
+			WHERE d.objid = c.oid AND d.deptype = 'i'
+		)
+		ORDER BY s.sequencename;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpTables(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT c.relname, format(
+			E'CREATE TABLE %I (\n%s\n)',
+			c.relname,
+			string_agg(
+				format(
+					'    %I %s%s%s%s',
+					a.attname,
+					pg_catalog.format_type(a.atttypid, a.atttypmod),
+					CASE
+						WHEN a.attidentity = 'a' THEN ' GENERATED ALWAYS AS IDENTITY'
+						WHEN a.attidentity = 'd' THEN ' GENERATED BY DEFAULT AS IDENTITY'
+						ELSE ''
+					END,
+					CASE
+						WHEN a.attgenerated = 's'
+							THEN format(' GENERATED ALWAYS AS (%s) STORED', pg_catalog.pg_get_expr(ad.adbin, ad.adrelid))
+						WHEN ad.adbin IS NOT NULL
+							THEN ' DEFAULT ' || pg_catalog.pg_get_expr(ad.adbin, ad.adrelid)
+						ELSE ''
+					END,
+					CASE WHEN a.attnotnull THEN ' NOT NULL' ELSE '' END
+				),
+				E',\n' ORDER BY a.attnum
+			)
+		)
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_catalog.pg_attribute a
+			ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+		LEFT JOIN pg_catalog.pg_attrdef ad
+			ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+		WHERE n.nspname = $1
+		AND c.relkind IN ('r', 'p')
+		GROUP BY c.relname
+		ORDER BY c.relname;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpSequenceOwners(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT t.relname, format(
+			'ALTER SEQUENCE %I OWNED BY %I.%I',
+			s.relname,
+			t.relname,
+			a.attname
+		)
+		FROM pg_catalog.pg_class s
+		JOIN pg_catalog.pg_namespace n ON n.oid = s.relnamespace
+		JOIN pg_catalog.pg_depend d
+			ON d.objid = s.oid AND d.deptype = 'a'
+			AND d.refclassid = 'pg_catalog.pg_class'::regclass
+		JOIN pg_catalog.pg_class t ON t.oid = d.refobjid
+		JOIN pg_catalog.pg_attribute a
+			ON a.attrelid = t.oid AND a.attnum = d.refobjsubid
+		WHERE n.nspname = $1
+		AND s.relkind = 'S'
+		ORDER BY s.relname;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpConstraints(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Foreign keys come last so that the tables and unique
+	// constraints they reference already exist.
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT c.relname, format(
+			'ALTER TABLE %I ADD CONSTRAINT %I %s',
+			c.relname,
+			con.conname,
+			pg_catalog.pg_get_constraintdef(con.oid)
+		)
+		FROM pg_catalog.pg_constraint con
+		JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+		AND con.contype IN ('p', 'u', 'c', 'x', 'f')
+		AND c.relkind IN ('r', 'p')
+		ORDER BY con.contype = 'f', c.relname, con.conname;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpIndexes(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Indexes backing constraints are created by their constraint.
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT t.relname, pg_catalog.pg_get_indexdef(i.indexrelid)
+		FROM pg_catalog.pg_index i
+		JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+		JOIN pg_catalog.pg_class t ON t.oid = i.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_constraint con
+			WHERE con.conindid = i.indexrelid
+		)
+		ORDER BY t.relname, ic.relname;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpViews(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Views can depend on each other, so they are kept in
+	// the order they were created in.
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT c.relname, format(
+			E'CREATE %sVIEW %I AS\n%s',
+			CASE WHEN c.relkind = 'm' THEN 'MATERIALIZED ' ELSE '' END,
+			c.relname,
+			rtrim(pg_catalog.pg_get_viewdef(c.oid, true), ';')
+		)
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+		AND c.relkind IN ('v', 'm')
+		ORDER BY c.oid;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpFunctions(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	// Functions that belong to an extension are created
+	// by the extension, so they're left out.
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT '', pg_catalog.pg_get_functiondef(f.oid)
+		FROM pg_catalog.pg_proc f
+		JOIN pg_catalog.pg_namespace n ON n.oid = f.pronamespace
+		WHERE n.nspname = $1
+		AND f.prokind IN ('f', 'p')
+		AND NOT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_depend d
+			WHERE d.objid = f.oid AND d.deptype = 'e'
+		)
+		ORDER BY f.proname, f.oid;
+	`)
+}
+
+func (p PostgresqlAdapter) dumpTriggers(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+) ([]string, error) {
+	return p.queryStatements(executor, schemaName, excludeTables, `
+		SELECT c.relname, pg_catalog.pg_get_triggerdef(t.oid)
+		FROM pg_catalog.pg_trigger t
+		JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+		AND NOT t.tgisinternal
+		ORDER BY c.relname, t.tgname;
+	`)
+}
+
+func (p PostgresqlAdapter) InspectSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (models.Schema, error) {
+	var schemaName string
+	err := executor.QueryRow("SELECT current_schema();").Scan(&schemaName)
+	if err != nil {
+		return models.Schema{}, fmt.Errorf("unable to retrieve current schema: %w", err)
+	}
+
+	// Indexes backing primary key, unique, and exclusion
+	// constraints are left out since the constraint covers them.
+	return inspectSchema(executor, schemaName, excludeTables, `
+		WITH tables AS (
+			SELECT c.oid, c.relname
+			FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1
+			AND c.relkind IN ('r', 'p')
+		)
+		SELECT t.relname, 'table', t.relname, '', false
+		FROM tables t
+		UNION ALL
+		SELECT t.relname, 'column', a.attname,
+			pg_catalog.format_type(a.atttypid, a.atttypmod), NOT a.attnotnull
+		FROM tables t
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid
+		WHERE a.attnum > 0 AND NOT a.attisdropped
+		UNION ALL
+		SELECT t.relname, 'index', i.relname, '', false
+		FROM tables t
+		JOIN pg_catalog.pg_index x ON x.indrelid = t.oid
+		JOIN pg_catalog.pg_class i ON i.oid = x.indexrelid
+		WHERE NOT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_constraint con
+			WHERE con.conindid = x.indexrelid
+			AND con.contype IN ('p', 'u', 'x')
+		)
+		UNION ALL
+		SELECT t.relname, 'constraint', pg_catalog.pg_get_constraintdef(con.oid), '', false
+		FROM tables t
+		JOIN pg_catalog.pg_constraint con ON con.conrelid = t.oid
+		WHERE con.contype IN ('p', 'u', 'f', 'c', 'x');
+	`, schemaName)
 }
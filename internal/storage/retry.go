@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+var (
+	initialRetryDelay = 100 * time.Millisecond
+	maxRetryDelay     = 5 * time.Second
+)
+
+// retryPolicy is how many times, and for how long, an operation
+// is retried with exponential backoff. The zero value doesn't
+// retry at all.
+type retryPolicy struct {
+	// retries is how many times the operation is retried after
+	// the first attempt. When it is zero but there is a timeout,
+	// the operation is retried until the timeout is reached.
+	retries int
+	// timeout is the total time to spend on the operation,
+	// including the time spent waiting between attempts.
+	timeout time.Duration
+}
+
+// do calls fn until it succeeds, it returns an error that
+// shouldRetry rejects, or the policy's retries or timeout
+// run out. The last error from fn is returned.
+func (p retryPolicy) do(
+	operation string,
+	shouldRetry func(err error) bool,
+	fn func(ctx context.Context) error,
+) error {
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !shouldRetry(err) {
+			return err
+		}
+		if p.retries == 0 && p.timeout == 0 {
+			return err
+		}
+		if p.retries > 0 && attempt > p.retries {
+			return err
+		}
+		deadline, hasDeadline := ctx.Deadline()
+		if hasDeadline && time.Now().Add(delay).After(deadline) {
+			return err
+		}
+
+		slog.Warn(
+			operation+" failed, retrying",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.String("error", err.Error()),
+		)
+		time.Sleep(delay)
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// retryAll is a shouldRetry func for operations where
+// every error is worth retrying.
+func retryAll(err error) bool {
+	return true
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eugenetriguba/bolt/internal/models"
+)
+
+// formatSchema joins the schema's statements into a single
+// script with one blank line between each statement.
+func formatSchema(statements []string) string {
+	if len(statements) == 0 {
+		return ""
+	}
+
+	formatted := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		statement = strings.TrimSpace(statement)
+		statement = strings.TrimSuffix(statement, ";")
+		formatted = append(formatted, statement+";")
+	}
+	return strings.Join(formatted, "\n\n") + "\n"
+}
+
+// isExcludedTable checks whether the table within the schema is
+// one of the excluded tables. An excluded table may be given with
+// or without a schema. Without one, it only matches tables in the
+// currently selected schema.
+func isExcludedTable(
+	schemaName string,
+	tableName string,
+	currentSchemaName string,
+	excludeTables []string,
+) bool {
+	for _, excludeTable := range excludeTables {
+		excludeSchemaName := currentSchemaName
+		parts := strings.Split(excludeTable, ".")
+		if len(parts) == 2 {
+			excludeSchemaName = parts[0]
+			excludeTable = parts[1]
+		}
+
+		if strings.EqualFold(excludeSchemaName, schemaName) &&
+			strings.EqualFold(excludeTable, tableName) {
+			return true
+		}
+	}
+	return false
+}
+
+// inspectSchema builds up the schema from a query whose rows are
+// a table name, the kind of schema object (table, column, index,
+// or constraint), the object's name, its data type, and whether
+// it is nullable. The data type and nullability are only used for
+// columns. Objects belonging to excluded tables are skipped.
+func inspectSchema(
+	executor SQLExecutor,
+	schemaName string,
+	excludeTables []string,
+	query string,
+	args ...any,
+) (models.Schema, error) {
+	rows, err := executor.Query(query, args...)
+	if err != nil {
+		return models.Schema{}, fmt.Errorf("unable to query schema: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]*models.Table)
+	for rows.Next() {
+		var tableName, kind, name, dataType string
+		var nullable bool
+		err = rows.Scan(&tableName, &kind, &name, &dataType, &nullable)
+		if err != nil {
+			return models.Schema{}, fmt.Errorf("unable to scan schema: %w", err)
+		}
+		if isExcludedTable(schemaName, tableName, schemaName, excludeTables) {
+			continue
+		}
+
+		table, ok := tables[tableName]
+		if !ok {
+			table = &models.Table{
+				Name:        tableName,
+				Columns:     make([]models.Column, 0),
+				Indexes:     make([]string, 0),
+				Constraints: make([]string, 0),
+			}
+			tables[tableName] = table
+		}
+
+		switch models.SchemaObjectKind(kind) {
+		case models.SchemaObjectTable:
+		case models.SchemaObjectColumn:
+			table.Columns = append(table.Columns, models.Column{
+				Name:     name,
+				Type:     strings.ToLower(dataType),
+				Nullable: nullable,
+			})
+		case models.SchemaObjectIndex:
+			table.Indexes = append(table.Indexes, name)
+		case models.SchemaObjectConstraint:
+			table.Constraints = append(table.Constraints, name)
+		default:
+			return models.Schema{}, fmt.Errorf("unknown schema object kind %s", kind)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return models.Schema{}, fmt.Errorf("unable to query schema: %w", err)
+	}
+
+	schema := models.Schema{Tables: make([]models.Table, 0, len(tables))}
+	for _, table := range tables {
+		sort.Strings(table.Indexes)
+		sort.Strings(table.Constraints)
+		schema.Tables = append(schema.Tables, *table)
+	}
+	sort.Slice(schema.Tables, func(i, j int) bool {
+		return schema.Tables[i].Name < schema.Tables[j].Name
+	})
+	return schema, nil
+}
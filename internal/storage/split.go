@@ -0,0 +1,104 @@
+package storage
+
+import "strings"
+
+// splitStatements splits a script up into its statements on the
+// semicolons that end them. Semicolons within quoted strings,
+// quoted identifiers, and comments don't end a statement. Empty
+// statements, and statements that are only comments, are left out.
+func splitStatements(script string) []string {
+	statements := make([]string, 0)
+	var statement strings.Builder
+	hasCode := false
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(statement.String()))
+		}
+		statement.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(script) {
+				if script[end] == '\\' {
+					end += 2
+					continue
+				}
+				if script[end] == c {
+					// A doubled quote is an escaped quote.
+					if end+1 < len(script) && script[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(script))
+			statement.WriteString(script[i:end])
+			hasCode = true
+			i = end - 1
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end == -1 {
+				end = len(script) - i
+			}
+			statement.WriteString(script[i : i+end])
+			i += end - 1
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end == -1 {
+				end = len(script) - i
+			} else {
+				end += 4
+			}
+			statement.WriteString(script[i : i+end])
+			i += end - 1
+		case c == ';':
+			flush()
+		default:
+			statement.WriteByte(c)
+			if !isSpace(c) {
+				hasCode = true
+			}
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// splitBatches splits a script up into its batches on the lines
+// that are only the separator, such as SQL Server's GO. Batches
+// that are empty are left out.
+func splitBatches(script string, separator string) []string {
+	batches := make([]string, 0)
+	var batch strings.Builder
+
+	flush := func() {
+		if strings.TrimSpace(batch.String()) != "" {
+			batches = append(batches, strings.TrimSpace(batch.String()))
+		}
+		batch.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(script, "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), separator) {
+			flush()
+			continue
+		}
+		batch.WriteString(line)
+	}
+	flush()
+
+	return batches
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/eugenetriguba/checkmate/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	type test struct {
+		script             string
+		expectedStatements []string
+	}
+
+	testCases := []test{
+		{
+			script:             "",
+			expectedStatements: []string{},
+		},
+		{
+			script:             "SELECT 1",
+			expectedStatements: []string{"SELECT 1"},
+		},
+		{
+			script:             "SELECT 1;\nSELECT 2;\n",
+			expectedStatements: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			script:             "INSERT INTO t VALUES ('a;b', 'it''s;');",
+			expectedStatements: []string{"INSERT INTO t VALUES ('a;b', 'it''s;')"},
+		},
+		{
+			script:             `INSERT INTO t VALUES ('a\';b');`,
+			expectedStatements: []string{`INSERT INTO t VALUES ('a\';b')`},
+		},
+		{
+			script:             "SELECT \"a;b\", `c;d` FROM t;",
+			expectedStatements: []string{"SELECT \"a;b\", `c;d` FROM t"},
+		},
+		{
+			script:             "-- first; statement\nSELECT 1;\n/* second;\nstatement */ SELECT 2;",
+			expectedStatements: []string{"-- first; statement\nSELECT 1", "/* second;\nstatement */ SELECT 2"},
+		},
+		{
+			script:             "SELECT 1;\n-- trailing comment;\n;",
+			expectedStatements: []string{"SELECT 1"},
+		},
+	}
+	for _, tc := range testCases {
+		statements := splitStatements(tc.script)
+		assert.DeepEqual(t, statements, tc.expectedStatements)
+	}
+}
+
+func TestSplitBatches(t *testing.T) {
+	type test struct {
+		script          string
+		expectedBatches []string
+	}
+
+	testCases := []test{
+		{
+			script:          "",
+			expectedBatches: []string{},
+		},
+		{
+			script:          "SELECT 1;\nSELECT 2;\n",
+			expectedBatches: []string{"SELECT 1;\nSELECT 2;"},
+		},
+		{
+			script: "CREATE TABLE t(id INT);\nGO\n\nCREATE VIEW v AS SELECT id FROM t;\n  go  \n" +
+				"CREATE PROCEDURE p AS SELECT 1;\nGO\n",
+			expectedBatches: []string{
+				"CREATE TABLE t(id INT);",
+				"CREATE VIEW v AS SELECT id FROM t;",
+				"CREATE PROCEDURE p AS SELECT 1;",
+			},
+		},
+		{
+			script:          "GO\nSELECT 'GO';\nSELECT 1 AS go_to;\nGO",
+			expectedBatches: []string{"SELECT 'GO';\nSELECT 1 AS go_to;"},
+		},
+	}
+	for _, tc := range testCases {
+		batches := splitBatches(tc.script, "GO")
+		assert.DeepEqual(t, batches, tc.expectedBatches)
+	}
+}
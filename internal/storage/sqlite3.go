@@ -1,9 +1,14 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"strings"
 
 	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
 )
 
 type SqliteAdapter struct{}
@@ -18,7 +23,7 @@ func (s SqliteAdapter) ConvertGenericPlaceholders(
 }
 
 func (s SqliteAdapter) TableExists(
-	executor sqlExecutor,
+	executor SQLExecutor,
 	tableName string,
 ) (bool, error) {
 	var count int
@@ -38,7 +43,30 @@ func (s SqliteAdapter) TableExists(
 	return count > 0, nil
 }
 
-func (s SqliteAdapter) DatabaseName(executor sqlExecutor) (string, error) {
+func (s SqliteAdapter) ColumnExists(
+	executor SQLExecutor,
+	tableName string,
+	columnName string,
+) (bool, error) {
+	var count int
+	err := executor.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info(?)
+		WHERE name=?;
+	`, tableName, columnName).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf(
+			"unable to check if %s.%s exists: %w",
+			tableName,
+			columnName,
+			err,
+		)
+	}
+
+	return count > 0, nil
+}
+
+func (s SqliteAdapter) DatabaseName(executor SQLExecutor) (string, error) {
 	return "main", nil
 }
 
@@ -46,3 +74,157 @@ func (s SqliteAdapter) CreateDSN(cfg configloader.ConnectionConfig) string {
 	// Note: Use the dbname as the sqlite db name/path
 	return cfg.DBName
 }
+
+func (s SqliteAdapter) CreateServerDSN(cfg configloader.ConnectionConfig) string {
+	// SQLite databases are files, so there is no server to connect
+	// to. An in-memory database stands in for one and the files are
+	// created and removed directly.
+	return ":memory:"
+}
+
+func (s SqliteAdapter) DatabaseExists(executor SQLExecutor, dbName string) (bool, error) {
+	_, err := os.Stat(dbName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to check if database %s exists: %w", dbName, err)
+	}
+
+	return true, nil
+}
+
+func (s SqliteAdapter) CreateDatabase(executor SQLExecutor, dbName string) error {
+	// An empty file is a valid SQLite database.
+	file, err := os.OpenFile(dbName, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func (s SqliteAdapter) DropDatabase(executor SQLExecutor, dbName string) error {
+	err := os.Remove(dbName)
+	if err != nil {
+		return err
+	}
+
+	// The journal files are left behind when a connection wasn't
+	// closed cleanly, and they'd be picked up by a new database
+	// created at the same path.
+	for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+		err = os.Remove(dbName + suffix)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s SqliteAdapter) CreateSchema(executor SQLExecutor, schemaName string) error {
+	return ErrSchemasUnsupported
+}
+
+func (s SqliteAdapter) IsTransientError(err error) bool {
+	// The error is matched on its message, SQLITE_BUSY's and
+	// SQLITE_LOCKED's, rather than the driver's error type since
+	// that type is only available when the driver is built with cgo.
+	message := err.Error()
+	return strings.Contains(message, "database is locked") ||
+		strings.Contains(message, "database table is locked")
+}
+
+func (s SqliteAdapter) DumpSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (string, error) {
+	// Tables and indexes are sorted by name so that the dump is
+	// stable. Views and triggers can depend on each other, so they
+	// are kept in the order they were created in.
+	rows, err := executor.Query(`
+		SELECT tbl_name, sql
+		FROM sqlite_master
+		WHERE sql IS NOT NULL
+		AND name NOT LIKE 'sqlite_%'
+		ORDER BY
+			CASE type
+				WHEN 'table' THEN 0
+				WHEN 'index' THEN 1
+				WHEN 'view' THEN 2
+				ELSE 3
+			END,
+			CASE WHEN type IN ('table', 'index') THEN name ELSE '' END,
+			rowid;
+	`)
+	if err != nil {
+		return "", fmt.Errorf("unable to query schema: %w", err)
+	}
+	defer rows.Close()
+
+	statements := make([]string, 0)
+	for rows.Next() {
+		var tableName, statement string
+		err = rows.Scan(&tableName, &statement)
+		if err != nil {
+			return "", fmt.Errorf("unable to scan schema: %w", err)
+		}
+		if isExcludedTable("main", tableName, "main", excludeTables) {
+			continue
+		}
+		statements = append(statements, statement)
+	}
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("unable to query schema: %w", err)
+	}
+
+	return formatSchema(statements), nil
+}
+
+func (s SqliteAdapter) InspectSchema(
+	executor SQLExecutor,
+	excludeTables []string,
+) (models.Schema, error) {
+	// SQLite doesn't keep check constraints anywhere but in the
+	// table's CREATE statement, so only primary keys, unique
+	// constraints, and foreign keys are inspected.
+	return inspectSchema(executor, "main", excludeTables, `
+		WITH tables AS (
+			SELECT name FROM sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		)
+		SELECT t.name, 'table', t.name, '', 0
+		FROM tables t
+		UNION ALL
+		SELECT t.name, 'column', c.name, c.type, c."notnull" = 0
+		FROM tables t, pragma_table_info(t.name) c
+		UNION ALL
+		SELECT t.name, 'index', i.name, '', 0
+		FROM tables t, pragma_index_list(t.name) i
+		WHERE i.origin = 'c'
+		UNION ALL
+		SELECT t.name, 'constraint', 'PRIMARY KEY (' || (
+			SELECT group_concat(name, ', ') FROM (
+				SELECT c.name FROM pragma_table_info(t.name) c
+				WHERE c.pk > 0 ORDER BY c.pk
+			)
+		) || ')', '', 0
+		FROM tables t
+		WHERE EXISTS (SELECT 1 FROM pragma_table_info(t.name) c WHERE c.pk > 0)
+		UNION ALL
+		SELECT t.name, 'constraint', 'UNIQUE (' || (
+			SELECT group_concat(name, ', ') FROM (
+				SELECT ii.name FROM pragma_index_info(i.name) ii ORDER BY ii.seqno
+			)
+		) || ')', '', 0
+		FROM tables t, pragma_index_list(t.name) i
+		WHERE i.origin = 'u'
+		UNION ALL
+		SELECT t.name, 'constraint', 'FOREIGN KEY (' ||
+			group_concat(f."from", ', ') || ') REFERENCES ' || f."table" ||
+			coalesce(' (' || group_concat(f."to", ', ') || ')', ''), '', 0
+		FROM tables t, pragma_foreign_key_list(t.name) f
+		GROUP BY t.name, f.id;
+	`)
+}
@@ -0,0 +1,11 @@
+//go:build cgo && !purego
+
+package storage
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSQLDriverName is the database/sql driver the sqlite3 driver
+// opens connections with. With cgo, it's SQLite's C library.
+const sqliteSQLDriverName = "sqlite3"
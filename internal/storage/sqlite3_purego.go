@@ -0,0 +1,9 @@
+//go:build !cgo || purego
+
+package storage
+
+// sqliteSQLDriverName is the database/sql driver the sqlite3 driver
+// opens connections with. Without cgo, or with the purego build tag,
+// it's modernc.org/sqlite, a translation of SQLite's C library into
+// Go, so Bolt can be built as a static binary.
+const sqliteSQLDriverName = "sqlite"
@@ -1,17 +1,33 @@
-//go:build sqlite3
+//go:build sqlite3 || sqlite
 
 package storage_test
 
 import (
 	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/eugenetriguba/bolt/internal/bolttest"
 	"github.com/eugenetriguba/bolt/internal/configloader"
+	"github.com/eugenetriguba/bolt/internal/models"
 	"github.com/eugenetriguba/bolt/internal/storage"
 	"github.com/eugenetriguba/checkmate/assert"
 )
 
+// openSqlite opens the database with the database/sql driver of the
+// configured driver, so that the tests run against both the cgo and
+// the pure-Go SQLite drivers.
+func openSqlite(dsn string) (*sql.DB, error) {
+	driver, err := storage.LookupDriver(os.Getenv("BOLT_DB_DRIVER"))
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.Open(driver.SQLDriverName, dsn)
+}
+
 func TestSqlite3_ConvertGenericPlaceholders(t *testing.T) {
 	type test struct {
 		query         string
@@ -46,7 +62,7 @@ func TestSqlite3_ConvertGenericPlaceholders(t *testing.T) {
 func TestSqlite3_TableExists(t *testing.T) {
 	cfg := bolttest.NewTestConnectionConfig()
 	adapter := storage.SqliteAdapter{}
-	db, err := sql.Open("sqlite3", adapter.CreateDSN(cfg))
+	db, err := openSqlite(adapter.CreateDSN(cfg))
 	assert.Nil(t, err)
 	t.Cleanup(func() {
 		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
@@ -69,7 +85,7 @@ func TestSqlite3_TableExists(t *testing.T) {
 func TestSqlite3_DatabaseName(t *testing.T) {
 	cfg := bolttest.NewTestConnectionConfig()
 	adapter := storage.SqliteAdapter{}
-	db, err := sql.Open("sqlite3", adapter.CreateDSN(cfg))
+	db, err := openSqlite(adapter.CreateDSN(cfg))
 	assert.Nil(t, err)
 	t.Cleanup(func() {
 		assert.Nil(t, db.Close())
@@ -92,3 +108,124 @@ func TestSqlite3_CreateDSN(t *testing.T) {
 
 	assert.Equal(t, cs, cfg.DBName)
 }
+
+func TestSqlite3_DumpSchema(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.SqliteAdapter{}
+	db, err := openSqlite(adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP VIEW IF EXISTS tmp_names;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS excluded;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY, name TEXT);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE INDEX tmp_name_idx ON tmp(name);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE VIEW tmp_names AS SELECT name FROM tmp;")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE excluded(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	schema, err := adapter.DumpSchema(db, []string{"excluded"})
+	assert.Nil(t, err)
+
+	assert.Equal(
+		t,
+		schema,
+		"CREATE TABLE tmp(id INT PRIMARY KEY, name TEXT);\n\n"+
+			"CREATE INDEX tmp_name_idx ON tmp(name);\n\n"+
+			"CREATE VIEW tmp_names AS SELECT name FROM tmp;\n",
+	)
+}
+
+func TestSqlite3_InspectSchema(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	adapter := storage.SqliteAdapter{}
+	db, err := openSqlite(adapter.CreateDSN(cfg))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp_child;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS tmp;")
+		assert.Nil(t, err)
+		_, err = db.Exec("DROP TABLE IF EXISTS excluded;")
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	})
+
+	_, err = db.Exec("CREATE TABLE tmp(id INT PRIMARY KEY, name TEXT NOT NULL UNIQUE, bio TEXT);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE INDEX tmp_bio_idx ON tmp(bio);")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE tmp_child(id INT PRIMARY KEY, tmp_id INT REFERENCES tmp(id));")
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE excluded(id INT PRIMARY KEY);")
+	assert.Nil(t, err)
+
+	schema, err := adapter.InspectSchema(db, []string{"excluded"})
+	assert.Nil(t, err)
+
+	assert.DeepEqual(t, schema, models.Schema{
+		Tables: []models.Table{
+			{
+				Name: "tmp",
+				Columns: []models.Column{
+					{Name: "id", Type: "int", Nullable: true},
+					{Name: "name", Type: "text", Nullable: false},
+					{Name: "bio", Type: "text", Nullable: true},
+				},
+				Indexes:     []string{"tmp_bio_idx"},
+				Constraints: []string{"PRIMARY KEY (id)", "UNIQUE (name)"},
+			},
+			{
+				Name: "tmp_child",
+				Columns: []models.Column{
+					{Name: "id", Type: "int", Nullable: true},
+					{Name: "tmp_id", Type: "int", Nullable: true},
+				},
+				Indexes:     []string{},
+				Constraints: []string{"FOREIGN KEY (tmp_id) REFERENCES tmp (id)", "PRIMARY KEY (id)"},
+			},
+		},
+	})
+}
+
+func TestSqlite3_CreateAndDropDatabase(t *testing.T) {
+	cfg := bolttest.NewTestConnectionConfig()
+	cfg.DBName = filepath.Join(t.TempDir(), "created.db")
+
+	err := storage.CreateDatabase(cfg)
+	assert.Nil(t, err)
+	_, err = os.Stat(cfg.DBName)
+	assert.Nil(t, err)
+	err = storage.CreateDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseExists)
+
+	db, err := storage.NewDB(cfg)
+	assert.Nil(t, err)
+	_, err = db.Exec("CREATE TABLE tmp(id INT);")
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	err = storage.DropDatabase(cfg)
+	assert.Nil(t, err)
+	_, err = os.Stat(cfg.DBName)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	err = storage.DropDatabase(cfg)
+	assert.ErrorIs(t, err, storage.ErrDatabaseDoesNotExist)
+}
+
+func TestSqlite3_IsTransientError(t *testing.T) {
+	adapter := storage.SqliteAdapter{}
+
+	assert.True(t, adapter.IsTransientError(errors.New("database is locked")))
+	assert.True(t, adapter.IsTransientError(errors.New("database table is locked: tmp")))
+	assert.False(t, adapter.IsTransientError(errors.New("no such table: tmp")))
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+// secretLiteralPattern matches the string literals that follow
+// keywords which set passwords, such as in
+// `CREATE USER app WITH PASSWORD 'secret'` or
+// `CREATE USER app IDENTIFIED BY 'secret'`.
+var secretLiteralPattern = regexp.MustCompile(
+	`(?i)\b(password|identified\s+by|secret)(\s*=?\s*)'(?:[^']|'')*'`,
+)
+
+// redactSQL replaces the secrets within query so it can be logged.
+func redactSQL(query string) string {
+	return secretLiteralPattern.ReplaceAllString(query, "${1}${2}'[REDACTED]'")
+}
+
+// traceStatement logs the statement executed against the database at
+// the debug level, along with how long it took to execute.
+func traceStatement(query string, args []any, startTime time.Time, err error) {
+	logger := slog.Default()
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	attrs := []any{
+		slog.String("sql", redactSQL(query)),
+		slog.Int("args", len(args)),
+		slog.Duration("duration", time.Since(startTime)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.Debug("executed statement", attrs...)
+}
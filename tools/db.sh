@@ -15,7 +15,7 @@ SCRIPT_DIR=$(dirname "$0")
 . "$SCRIPT_DIR/lib.sh"
 
 BASE_DIR=$(dirname "$0")/..
-ALLOWED_DBS=("postgresql" "mysql" "mssql")
+ALLOWED_DBS=("postgresql" "mysql" "mssql" "cockroachdb" "clickhouse")
 ALLOWED_COMMANDS=("start" "stop")
 
 function main() {
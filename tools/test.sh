@@ -10,7 +10,7 @@ SCRIPT_DIR=$(dirname "$0")
 . "$SCRIPT_DIR/lib.sh"
 
 BASE_DIR=$(dirname "$0")/..
-ALLOWED_DBS=("postgresql" "mysql" "mssql" "sqlite3")
+ALLOWED_DBS=("postgresql" "mysql" "mssql" "sqlite3" "sqlite" "cockroachdb" "clickhouse" "libsql")
 
 function main() {
   local DB_NAME="$1"
@@ -19,7 +19,19 @@ function main() {
     exit 1
   fi
 
-  env $(cat "$BASE_DIR/.env.$DB_NAME" | xargs) go test -p 1 -tags "$DB_NAME" -cover -coverprofile cover.out ./...
+  # The rest of the test suite's SQL is written for the other
+  # databases, so only the adapter's own tests run against these.
+  local RUN=""
+  case $DB_NAME in
+  cockroachdb)
+    RUN="^TestCockroachDB_"
+    ;;
+  clickhouse)
+    RUN="^TestClickHouse_"
+    ;;
+  esac
+
+  env $(cat "$BASE_DIR/.env.$DB_NAME" | xargs) go test -p 1 -tags "$DB_NAME" -run "$RUN" -cover -coverprofile cover.out ./...
 }
 
 main "$@"